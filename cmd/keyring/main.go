@@ -0,0 +1,137 @@
+// Command keyring manages the JWT signing key ring referenced by
+// JWT_KEYRING_PATH.
+//
+// Rotating keys without logging users out:
+//
+//	keyring generate -alg EdDSA   # new key, verify only; deploy it everywhere
+//	keyring promote -kid <new>    # new key signs, old key keeps verifying
+//	keyring retire -kid <old>     # old key verifies until its tokens expired
+//	keyring prune                 # drop retired keys past that window
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/HasanNugroho/gin-clean/pkg/jwt"
+)
+
+const usage = `Usage: keyring <command> [flags]
+
+Commands:
+  list                              List keys in the ring
+  generate -alg <alg> [-kid <id>]   Generate a key (HS256, RS256, ES256, EdDSA) in verify state
+           [-secret-env <VAR>]      Import an HS256 secret from an environment variable
+  promote  -kid <id>                Make a key the signing key
+  retire   -kid <id>                Retire a key; it verifies until issued tokens expired
+  prune    [-lifetime <duration>]   Remove retired keys whose tokens have all expired
+
+Key ids consist of letters, digits, '-' and '_'.
+Every command accepts -keyring <path> (default $JWT_KEYRING_PATH or ./keys/keyring.json).
+`
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	if err := run(os.Args[1], os.Args[2:]); err != nil {
+		fmt.Printf("❌ %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(command string, args []string) error {
+	flags := flag.NewFlagSet(command, flag.ExitOnError)
+	path := flags.String("keyring", defaultKeyRingPath(), "path to the key ring manifest")
+	kid := flags.String("kid", "", "key id")
+	alg := flags.String("alg", jwt.AlgorithmEdDSA, "signing algorithm")
+	secretEnv := flags.String("secret-env", "", "environment variable holding an HS256 secret to import")
+	lifetime := flags.Duration("lifetime", defaultLifetime(), "longest lifetime of an issued token")
+	flags.Parse(args)
+
+	ring, err := jwt.LoadKeyRing(*path)
+	if err != nil {
+		return err
+	}
+
+	switch command {
+	case "list":
+		return list(ring)
+	case "generate":
+		var secret []byte
+		if *secretEnv != "" {
+			secret = []byte(os.Getenv(*secretEnv))
+			if len(secret) == 0 {
+				return fmt.Errorf("environment variable %s is empty", *secretEnv)
+			}
+		}
+
+		entry, err := ring.Generate(*alg, *kid, secret)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("✅ Generated %s key %s\n", entry.Algorithm, entry.ID)
+	case "promote":
+		if err := ring.Promote(*kid); err != nil {
+			return err
+		}
+		fmt.Printf("✅ Key %s is now the signing key\n", *kid)
+	case "retire":
+		if err := ring.Retire(*kid, time.Now()); err != nil {
+			return err
+		}
+		fmt.Printf("✅ Key %s retired; it stops verifying after %s\n", *kid, time.Now().Add(*lifetime).Format(time.RFC3339))
+	case "prune":
+		pruned, err := ring.Prune(time.Now(), *lifetime)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("✅ Pruned %d key(s) %v\n", len(pruned), pruned)
+	default:
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	return ring.Save()
+}
+
+func list(ring *jwt.KeyRing) error {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "KID\tALG\tSTATUS\tCREATED\tRETIRED")
+	for _, entry := range ring.Keys {
+		retired := "-"
+		if entry.RetiredAt != nil {
+			retired = entry.RetiredAt.Format(time.RFC3339)
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", entry.ID, entry.Algorithm, entry.Status, entry.CreatedAt.Format(time.RFC3339), retired)
+	}
+	return w.Flush()
+}
+
+func defaultKeyRingPath() string {
+	if path := os.Getenv("JWT_KEYRING_PATH"); path != "" {
+		return path
+	}
+	return "./keys/keyring.json"
+}
+
+// defaultLifetime mirrors the server, which keeps retired keys verifying for
+// the longest configured token lifetime.
+func defaultLifetime() time.Duration {
+	var lifetime time.Duration
+	for _, name := range []string{"TOKEN_EXPIRY", "REFRESH_TOKEN_EXPIRY"} {
+		if d, err := time.ParseDuration(os.Getenv(name)); err == nil && d > lifetime {
+			lifetime = d
+		}
+	}
+
+	if lifetime == 0 {
+		return 168 * time.Hour
+	}
+	return lifetime
+}
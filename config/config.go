@@ -9,12 +9,21 @@ import (
 )
 
 type Config struct {
-	Server   Server   `mapstructure:",squash"`
-	Database Database `mapstructure:",squash"`
-	Secret   Secret   `mapstructure:",squash"`
-	Redis    Redis    `mapstructure:",squash"`
-	Context  Context  `mapstructure:",squash"`
-	Security Security `mapstructure:",squash"`
+	Server       Server       `mapstructure:",squash"`
+	Database     Database     `mapstructure:",squash"`
+	Secret       Secret       `mapstructure:",squash"`
+	Redis        Redis        `mapstructure:",squash"`
+	Context      Context      `mapstructure:",squash"`
+	Security     Security     `mapstructure:",squash"`
+	Mail         Mail         `mapstructure:",squash"`
+	Password     Password     `mapstructure:",squash"`
+	Verify       Verify       `mapstructure:",squash"`
+	MFA          MFA          `mapstructure:",squash"`
+	Registration Registration `mapstructure:",squash"`
+	Invitation   Invitation   `mapstructure:",squash"`
+	Policy       Policy       `mapstructure:",squash"`
+	Retention    Retention    `mapstructure:",squash"`
+	Concurrency  Concurrency  `mapstructure:",squash"`
 }
 
 type Server struct {
@@ -38,6 +47,12 @@ type Secret struct {
 	Jwt                string `mapstructure:"SECRET_KEY"`
 	TokenExpiry        string `mapstructure:"TOKEN_EXPIRY"`
 	RefreshTokenExpiry string `mapstructure:"REFRESH_TOKEN_EXPIRY"`
+	SessionMaxLifetime string `mapstructure:"SESSION_MAX_LIFETIME"`
+	JwtAlgorithm       string `mapstructure:"JWT_ALGORITHM"`
+	JwtPrivateKeyPath  string `mapstructure:"JWT_PRIVATE_KEY_PATH"`
+	JwtKeyID           string `mapstructure:"JWT_KEY_ID"`
+	JwtKeyRingPath     string `mapstructure:"JWT_KEYRING_PATH"`
+	CursorSecret       string `mapstructure:"CURSOR_SECRET"`
 }
 
 type Redis struct {
@@ -46,6 +61,101 @@ type Redis struct {
 	Pass string `mapstructure:"REDIS_PASS"`
 }
 
+type Mail struct {
+	Driver   string `mapstructure:"MAIL_DRIVER"`
+	From     string `mapstructure:"MAIL_FROM"`
+	FileDir  string `mapstructure:"MAIL_FILE_DIR"`
+	SMTPHost string `mapstructure:"SMTP_HOST"`
+	SMTPPort string `mapstructure:"SMTP_PORT"`
+	SMTPUser string `mapstructure:"SMTP_USER"`
+	SMTPPass string `mapstructure:"SMTP_PASS"`
+}
+
+// Registration modes for the self-service sign-up endpoint.
+const (
+	RegistrationOpen     = "open"
+	RegistrationInvite   = "invite"
+	RegistrationDisabled = "disabled"
+)
+
+type Registration struct {
+	Mode      string `mapstructure:"REGISTRATION_MODE"`
+	AutoLogin bool   `mapstructure:"REGISTRATION_AUTO_LOGIN"`
+}
+
+// Invitation links are single-use and expire after Expiry. URL is the page of
+// the client app that receives the ?token= parameter.
+type Invitation struct {
+	Expiry string `mapstructure:"INVITATION_EXPIRY"`
+	URL    string `mapstructure:"INVITATION_URL"`
+}
+
+// Policy enforcement modes.
+const (
+	PolicyEnforce = "enforce"
+	PolicyDryRun  = "dry_run"
+)
+
+type Policy struct {
+	File           string `mapstructure:"POLICY_FILE"`
+	Mode           string `mapstructure:"POLICY_MODE"`
+	ReloadInterval string `mapstructure:"POLICY_RELOAD_INTERVAL"`
+}
+
+// Retention controls how long soft-deleted users can be restored before they
+// are purged. Purging is on unless Purge is explicitly turned off, which keeps
+// deleted users until purged by hand.
+type Retention struct {
+	Purge       *bool  `mapstructure:"USER_RETENTION_PURGE"`
+	GracePeriod string `mapstructure:"USER_RETENTION_GRACE_PERIOD"`
+	Interval    string `mapstructure:"USER_RETENTION_INTERVAL"`
+}
+
+// PurgeEnabled reports whether deleted users are purged automatically once
+// their grace period is over. They are unless turned off.
+func (r Retention) PurgeEnabled() bool {
+	return r.Purge == nil || *r.Purge
+}
+
+// Concurrency controls optimistic concurrency of user updates. Changes
+// must carry If-Match unless RequireIfMatch is explicitly turned off.
+type Concurrency struct {
+	RequireIfMatch *bool `mapstructure:"USERS_REQUIRE_IF_MATCH"`
+}
+
+// IfMatchRequired reports whether updates and deletes of users must carry
+// If-Match. It is required unless turned off.
+func (c Concurrency) IfMatchRequired() bool {
+	return c.RequireIfMatch == nil || *c.RequireIfMatch
+}
+
+type Password struct {
+	ResetExpiry string `mapstructure:"PASSWORD_RESET_EXPIRY"`
+	ResetURL    string `mapstructure:"PASSWORD_RESET_URL"`
+}
+
+type Verify struct {
+	Required       bool   `mapstructure:"EMAIL_VERIFICATION_REQUIRED"`
+	Expiry         string `mapstructure:"EMAIL_VERIFICATION_EXPIRY"`
+	URL            string `mapstructure:"EMAIL_VERIFICATION_URL"`
+	ResendInterval string `mapstructure:"EMAIL_VERIFICATION_RESEND_INTERVAL"`
+}
+
+type MFA struct {
+	RequiredRoles   []string `mapstructure:"MFA_REQUIRED_ROLES"`
+	ChallengeExpiry string   `mapstructure:"MFA_CHALLENGE_EXPIRY"`
+}
+
+// IsRequiredFor reports whether accounts with the role must enroll in MFA.
+func (m MFA) IsRequiredFor(role string) bool {
+	for _, required := range m.RequiredRoles {
+		if required == role {
+			return true
+		}
+	}
+	return false
+}
+
 type Context struct {
 	Timeout int `mapstructure:"TIMEOUT"`
 }
@@ -76,25 +186,148 @@ func Get() (config *Config, err error) {
 		return nil, err
 	}
 
+	config.MFA.RequiredRoles = nil
+	for _, role := range strings.Split(viper.GetString("MFA_REQUIRED_ROLES"), ",") {
+		if role = strings.TrimSpace(role); role != "" {
+			config.MFA.RequiredRoles = append(config.MFA.RequiredRoles, role)
+		}
+	}
+	config.Context.Timeout = int(time.Duration(viper.GetInt("TIMEOUT")) * time.Second)
+	config.Security.AllowedOrigins = strings.Split(viper.GetString("ALLOWED_ORIGINS"), ",")
+
+	if err := config.Normalize(); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+// Normalize fills in the defaults of the settings left empty and validates
+// the configuration. Get does so for the configuration it loads;
+// configurations built by hand must be normalized too.
+func (config *Config) Normalize() error {
 	if config.Server.LogLevel < -1 || config.Server.LogLevel > 5 {
-		return nil, fmt.Errorf("LOG_LEVEL must be between -1 (trace) and 5 (panic), got %d", config.Server.LogLevel)
+		return fmt.Errorf("LOG_LEVEL must be between -1 (trace) and 5 (panic), got %d", config.Server.LogLevel)
 	}
 
 	if _, err := time.ParseDuration(config.Secret.TokenExpiry); err != nil {
-		return nil, fmt.Errorf("invalid TOKEN_EXPIRY: %w", err)
+		return fmt.Errorf("invalid TOKEN_EXPIRY: %w", err)
 	}
 
 	if _, err := time.ParseDuration(config.Secret.RefreshTokenExpiry); err != nil {
-		return nil, fmt.Errorf("invalid REFRESH_TOKEN_EXPIRY: %w", err)
+		return fmt.Errorf("invalid REFRESH_TOKEN_EXPIRY: %w", err)
+	}
+
+	if config.Secret.SessionMaxLifetime == "" {
+		config.Secret.SessionMaxLifetime = "720h"
+	}
+	if lifetime, err := time.ParseDuration(config.Secret.SessionMaxLifetime); err != nil || lifetime <= 0 {
+		return fmt.Errorf("SESSION_MAX_LIFETIME must be a positive duration, got %q", config.Secret.SessionMaxLifetime)
 	}
 
-	timeoutSeconds := viper.GetInt("TIMEOUT")
-	if timeoutSeconds <= 0 {
-		timeoutSeconds = 3600
+	if config.Secret.CursorSecret == "" {
+		config.Secret.CursorSecret = config.Secret.Jwt
+	}
+	if config.Secret.CursorSecret == "" {
+		return fmt.Errorf("CURSOR_SECRET is required when SECRET_KEY is empty")
 	}
 
-	config.Context.Timeout = int(time.Duration(timeoutSeconds) * time.Second)
-	config.Security.AllowedOrigins = strings.Split(viper.GetString("ALLOWED_ORIGINS"), ",")
+	if config.Password.ResetExpiry == "" {
+		config.Password.ResetExpiry = "30m"
+	}
+	if _, err := time.ParseDuration(config.Password.ResetExpiry); err != nil {
+		return fmt.Errorf("invalid PASSWORD_RESET_EXPIRY: %w", err)
+	}
+	if config.Password.ResetURL == "" {
+		config.Password.ResetURL = strings.TrimSuffix(config.Server.BaseUrl, "/") + "/reset-password"
+	}
+
+	if config.Verify.Expiry == "" {
+		config.Verify.Expiry = "24h"
+	}
+	if _, err := time.ParseDuration(config.Verify.Expiry); err != nil {
+		return fmt.Errorf("invalid EMAIL_VERIFICATION_EXPIRY: %w", err)
+	}
+	if config.Verify.ResendInterval == "" {
+		config.Verify.ResendInterval = "1m"
+	}
+	if _, err := time.ParseDuration(config.Verify.ResendInterval); err != nil {
+		return fmt.Errorf("invalid EMAIL_VERIFICATION_RESEND_INTERVAL: %w", err)
+	}
+	if config.Verify.URL == "" {
+		config.Verify.URL = strings.TrimSuffix(config.Server.BaseUrl, "/") + "/api/v1/auth/verify-email"
+	}
+
+	if config.MFA.ChallengeExpiry == "" {
+		config.MFA.ChallengeExpiry = "5m"
+	}
+	if _, err := time.ParseDuration(config.MFA.ChallengeExpiry); err != nil {
+		return fmt.Errorf("invalid MFA_CHALLENGE_EXPIRY: %w", err)
+	}
+
+	switch config.Registration.Mode {
+	case "":
+		config.Registration.Mode = RegistrationOpen
+	case RegistrationOpen, RegistrationInvite, RegistrationDisabled:
+	default:
+		return fmt.Errorf("REGISTRATION_MODE must be one of open, invite or disabled, got %q", config.Registration.Mode)
+	}
+
+	if config.Invitation.Expiry == "" {
+		config.Invitation.Expiry = "72h"
+	}
+	if _, err := time.ParseDuration(config.Invitation.Expiry); err != nil {
+		return fmt.Errorf("invalid INVITATION_EXPIRY: %w", err)
+	}
+	if config.Invitation.URL == "" {
+		config.Invitation.URL = strings.TrimSuffix(config.Server.BaseUrl, "/") + "/accept-invitation"
+	}
+
+	switch config.Policy.Mode {
+	case "":
+		config.Policy.Mode = PolicyEnforce
+	case PolicyEnforce, PolicyDryRun:
+	default:
+		return fmt.Errorf("POLICY_MODE must be enforce or dry_run, got %q", config.Policy.Mode)
+	}
+	if config.Policy.ReloadInterval == "" {
+		config.Policy.ReloadInterval = "10s"
+	}
+	if _, err := time.ParseDuration(config.Policy.ReloadInterval); err != nil {
+		return fmt.Errorf("invalid POLICY_RELOAD_INTERVAL: %w", err)
+	}
+
+	if config.Retention.GracePeriod == "" {
+		config.Retention.GracePeriod = "720h"
+	}
+	if gracePeriod, err := time.ParseDuration(config.Retention.GracePeriod); err != nil || gracePeriod <= 0 {
+		return fmt.Errorf("USER_RETENTION_GRACE_PERIOD must be a positive duration, got %q", config.Retention.GracePeriod)
+	}
+	if config.Retention.Interval == "" {
+		config.Retention.Interval = "1h"
+	}
+	if interval, err := time.ParseDuration(config.Retention.Interval); err != nil || interval <= 0 {
+		return fmt.Errorf("USER_RETENTION_INTERVAL must be a positive duration, got %q", config.Retention.Interval)
+	}
+
+	if config.Mail.Driver == "file" && config.Mail.FileDir == "" {
+		config.Mail.FileDir = "./mail"
+	}
+
+	switch config.Secret.JwtAlgorithm {
+	case "":
+		config.Secret.JwtAlgorithm = "HS256"
+	case "HS256":
+	case "RS256", "ES256", "EdDSA":
+		if config.Secret.JwtPrivateKeyPath == "" && config.Secret.JwtKeyRingPath == "" {
+			return fmt.Errorf("JWT_PRIVATE_KEY_PATH is required for JWT_ALGORITHM %s", config.Secret.JwtAlgorithm)
+		}
+	default:
+		return fmt.Errorf("JWT_ALGORITHM must be one of HS256, RS256, ES256 or EdDSA, got %q", config.Secret.JwtAlgorithm)
+	}
+
+	if config.Context.Timeout <= 0 {
+		config.Context.Timeout = int(3600 * time.Second)
+	}
 
-	return
+	return nil
 }
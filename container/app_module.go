@@ -1,15 +1,19 @@
 package container
 
 import (
+	"context"
 	"time"
 
 	"github.com/HasanNugroho/gin-clean/config"
 	"github.com/HasanNugroho/gin-clean/internal/domain/repository"
+	"github.com/HasanNugroho/gin-clean/internal/infrastructure/presistence/cache"
 	"github.com/HasanNugroho/gin-clean/internal/infrastructure/presistence/postgresql"
 	"github.com/HasanNugroho/gin-clean/internal/interfaces/http/handler"
 	"github.com/HasanNugroho/gin-clean/internal/service"
+	"github.com/HasanNugroho/gin-clean/pkg/cursor"
 	"github.com/HasanNugroho/gin-clean/pkg/jwt"
 	"github.com/HasanNugroho/gin-clean/pkg/logger"
+	"github.com/HasanNugroho/gin-clean/pkg/mail"
 	"github.com/sarulabs/di/v2"
 	"gorm.io/gorm"
 )
@@ -25,31 +29,243 @@ func RegisterModul(builder *di.Builder) *di.Builder {
 			},
 		},
 
+		{
+			Name: "session-repository",
+			Build: func(ctn di.Container) (interface{}, error) {
+				db := ctn.Get("db").(*gorm.DB)
+				return postgresql.NewSessionRepository(db), nil
+			},
+		},
+		{
+			Name: "role-repository",
+			Build: func(ctn di.Container) (interface{}, error) {
+				db := ctn.Get("db").(*gorm.DB)
+				return postgresql.NewRoleRepository(db), nil
+			},
+		},
+		{
+			Name: "recovery-code-repository",
+			Build: func(ctn di.Container) (interface{}, error) {
+				db := ctn.Get("db").(*gorm.DB)
+				return postgresql.NewRecoveryCodeRepository(db), nil
+			},
+		},
+		{
+			Name: "invitation-repository",
+			Build: func(ctn di.Container) (interface{}, error) {
+				db := ctn.Get("db").(*gorm.DB)
+				return postgresql.NewInvitationRepository(db), nil
+			},
+		},
+		{
+			Name: "tx-manager",
+			Build: func(ctn di.Container) (interface{}, error) {
+				db := ctn.Get("db").(*gorm.DB)
+				return postgresql.NewTxManager(db), nil
+			},
+		},
+		{
+			Name: "organization-repository",
+			Build: func(ctn di.Container) (interface{}, error) {
+				db := ctn.Get("db").(*gorm.DB)
+				return postgresql.NewOrganizationRepository(db), nil
+			},
+		},
+
 		// SERVICE
+		{
+			Name: "token-revoker",
+			Build: func(ctn di.Container) (interface{}, error) {
+				var (
+					sessions   = ctn.Get("session-repository").(repository.SessionRepository)
+					repository = ctn.Get("user-repository").(repository.UserRepository)
+					jwt        = ctn.Get("jwt").(*jwt.TokenGenerator)
+				)
+
+				return service.NewTokenRevoker(repository, sessions, jwt), nil
+			},
+		},
+		{
+			Name: "rbac-service",
+			Build: func(ctn di.Container) (interface{}, error) {
+				var (
+					cfg   = ctn.Get("config").(*config.Config)
+					roles = ctn.Get("role-repository").(repository.RoleRepository)
+					cache = ctn.Get("cache").(cache.Cache)
+				)
+
+				return service.NewRBACService(roles, cache, time.Duration(cfg.Context.Timeout)*time.Second), nil
+			},
+		},
+		{
+			Name: "policy-service",
+			Build: func(ctn di.Container) (interface{}, error) {
+				rbac := ctn.Get("rbac-service").(*service.RBACService)
+				return service.NewPolicyService(rbac), nil
+			},
+		},
+		{
+			Name: "email-verification-service",
+			Build: func(ctn di.Container) (interface{}, error) {
+				var (
+					cfg        = ctn.Get("config").(*config.Config)
+					repository = ctn.Get("user-repository").(repository.UserRepository)
+					cache      = ctn.Get("cache").(cache.Cache)
+					mailer     = ctn.Get("mailer").(mail.Sender)
+					jwt        = ctn.Get("jwt").(*jwt.TokenGenerator)
+					logger     = ctn.Get("logger").(*logger.Logger)
+				)
+
+				return service.NewEmailVerificationService(
+					repository,
+					cache,
+					mailer,
+					jwt,
+					logger,
+					cfg,
+					time.Duration(cfg.Context.Timeout)*time.Second,
+				), nil
+			},
+		},
 		{
 			Name: "user-service",
 			Build: func(ctn di.Container) (interface{}, error) {
 				cfg := ctn.Get("config").(*config.Config)
+				tx := ctn.Get("tx-manager").(repository.TxManager)
+				revoker := ctn.Get("token-revoker").(*service.TokenRevoker)
+				verification := ctn.Get("email-verification-service").(*service.EmailVerificationService)
+				roles := ctn.Get("role-repository").(repository.RoleRepository)
+				organizations := ctn.Get("organization-repository").(repository.OrganizationRepository)
+				cursors := ctn.Get("cursor-codec").(*cursor.Codec)
+				retention := ctn.Get("user-retention").(*service.UserRetention)
 				repository := ctn.Get("user-repository").(repository.UserRepository)
 
 				return service.NewUserService(
 					repository,
+					tx,
+					roles,
+					organizations,
+					revoker,
+					verification,
+					cursors,
+					retention,
 					time.Duration(cfg.Context.Timeout)*time.Second,
 				), nil
 			},
 		},
 		{
-			Name: "auth-service",
+			Name: "user-retention",
 			Build: func(ctn di.Container) (interface{}, error) {
 				var (
 					cfg        = ctn.Get("config").(*config.Config)
-					repository = ctn.Get("user-repository").(repository.UserRepository)
 					logger     = ctn.Get("logger").(*logger.Logger)
-					jwt        = ctn.Get("jwt").(*jwt.TokenGenerator)
+					repository = ctn.Get("user-repository").(repository.UserRepository)
+				)
+
+				gracePeriod, _ := time.ParseDuration(cfg.Retention.GracePeriod)
+				interval, _ := time.ParseDuration(cfg.Retention.Interval)
+
+				retention := service.NewUserRetention(
+					repository,
+					logger,
+					cfg.Retention.PurgeEnabled(),
+					gracePeriod,
+					time.Duration(cfg.Context.Timeout)*time.Second,
+				)
+				if retention.Enabled() {
+					go retention.Run(context.Background(), interval)
+				}
+				return retention, nil
+			},
+		},
+		{
+			Name: "organization-service",
+			Build: func(ctn di.Container) (interface{}, error) {
+				var (
+					cfg        = ctn.Get("config").(*config.Config)
+					repository = ctn.Get("organization-repository").(repository.OrganizationRepository)
+				)
+
+				return service.NewOrganizationService(
+					repository,
+					time.Duration(cfg.Context.Timeout)*time.Second,
+				), nil
+			},
+		},
+		{
+			Name: "invitation-service",
+			Build: func(ctn di.Container) (interface{}, error) {
+				var (
+					cfg           = ctn.Get("config").(*config.Config)
+					tx            = ctn.Get("tx-manager").(repository.TxManager)
+					userRepo      = ctn.Get("user-repository").(repository.UserRepository)
+					organizations = ctn.Get("organization-repository").(repository.OrganizationRepository)
+					users         = ctn.Get("user-service").(*service.UserService)
+					mailer        = ctn.Get("mailer").(mail.Sender)
+					logger        = ctn.Get("logger").(*logger.Logger)
+					jwt           = ctn.Get("jwt").(*jwt.TokenGenerator)
+					repository    = ctn.Get("invitation-repository").(repository.InvitationRepository)
+				)
+
+				return service.NewInvitationService(
+					repository,
+					tx,
+					userRepo,
+					organizations,
+					users,
+					mailer,
+					logger,
+					jwt,
+					cfg,
+					time.Duration(cfg.Context.Timeout)*time.Second,
+				), nil
+			},
+		},
+		{
+			Name: "mfa-service",
+			Build: func(ctn di.Container) (interface{}, error) {
+				var (
+					cfg           = ctn.Get("config").(*config.Config)
+					recoveryCodes = ctn.Get("recovery-code-repository").(repository.RecoveryCodeRepository)
+					repository    = ctn.Get("user-repository").(repository.UserRepository)
+					cache         = ctn.Get("cache").(cache.Cache)
+				)
+
+				return service.NewMFAService(
+					repository,
+					recoveryCodes,
+					cache,
+					cfg,
+					time.Duration(cfg.Context.Timeout)*time.Second,
+				), nil
+			},
+		},
+		{
+			Name: "auth-service",
+			Build: func(ctn di.Container) (interface{}, error) {
+				var (
+					cfg           = ctn.Get("config").(*config.Config)
+					sessions      = ctn.Get("session-repository").(repository.SessionRepository)
+					revoker       = ctn.Get("token-revoker").(*service.TokenRevoker)
+					mfa           = ctn.Get("mfa-service").(*service.MFAService)
+					users         = ctn.Get("user-service").(*service.UserService)
+					organizations = ctn.Get("organization-service").(*service.OrganizationService)
+					repository    = ctn.Get("user-repository").(repository.UserRepository)
+					cache         = ctn.Get("cache").(cache.Cache)
+					mailer        = ctn.Get("mailer").(mail.Sender)
+					logger        = ctn.Get("logger").(*logger.Logger)
+					jwt           = ctn.Get("jwt").(*jwt.TokenGenerator)
 				)
 
 				return service.NewAuthService(
 					repository,
+					sessions,
+					revoker,
+					mfa,
+					users,
+					organizations,
+					cache,
+					mailer,
 					logger,
 					cfg,
 					jwt,
@@ -73,6 +289,41 @@ func RegisterModul(builder *di.Builder) *di.Builder {
 				return nil, nil
 			},
 		},
+		{
+			Name: "role-handler",
+			Build: func(ctn di.Container) (interface{}, error) {
+				handler.RegisterRoleRoutes(&ctn)
+				return nil, nil
+			},
+		},
+		{
+			Name: "organization-handler",
+			Build: func(ctn di.Container) (interface{}, error) {
+				handler.RegisterOrganizationRoutes(&ctn)
+				return nil, nil
+			},
+		},
+		{
+			Name: "invitation-handler",
+			Build: func(ctn di.Container) (interface{}, error) {
+				handler.RegisterInvitationRoutes(&ctn)
+				return nil, nil
+			},
+		},
+		{
+			Name: "policy-handler",
+			Build: func(ctn di.Container) (interface{}, error) {
+				handler.RegisterPolicyRoutes(&ctn)
+				return nil, nil
+			},
+		},
+		{
+			Name: "jwks-handler",
+			Build: func(ctn di.Container) (interface{}, error) {
+				handler.RegisterJWKSRoutes(&ctn)
+				return nil, nil
+			},
+		},
 	}
 
 	for _, def := range definitions {
@@ -5,7 +5,9 @@ import (
 	"github.com/sarulabs/di/v2"
 )
 
-func Build(engine *gin.Engine) (*di.Container, error) {
+// Build wires the application on engine. Profiles are applied in order over
+// the default definitions.
+func Build(engine *gin.Engine, profiles ...Profile) (*di.Container, error) {
 	builder, err := di.NewBuilder()
 	if err != nil {
 		return nil, err
@@ -27,12 +29,24 @@ func Build(engine *gin.Engine) (*di.Container, error) {
 	// Register feature/module-specific
 	RegisterModul(builder)
 
+	for _, profile := range profiles {
+		if err := profile(builder); err != nil {
+			return nil, err
+		}
+	}
+
 	// Build the container
 	ctn := builder.Build()
 	var (
 		_ = ctn.Get("user-handler")
 		_ = ctn.Get("auth-handler")
+		_ = ctn.Get("role-handler")
+		_ = ctn.Get("organization-handler")
+		_ = ctn.Get("invitation-handler")
+		_ = ctn.Get("policy-handler")
+		_ = ctn.Get("jwks-handler")
 		_ = ctn.Get("auth-middleware")
+		_ = ctn.Get("user-retention")
 	)
 
 	return &ctn, nil
@@ -2,15 +2,20 @@ package container
 
 import (
 	"context"
+	"crypto/rand"
 	"fmt"
+	"time"
 
 	"github.com/HasanNugroho/gin-clean/config"
 	"github.com/HasanNugroho/gin-clean/internal/infrastructure/presistence/cache"
 	"github.com/HasanNugroho/gin-clean/internal/infrastructure/presistence/postgresql"
 	"github.com/HasanNugroho/gin-clean/internal/interfaces/http/middleware"
 	"github.com/HasanNugroho/gin-clean/internal/service"
+	"github.com/HasanNugroho/gin-clean/pkg/cursor"
 	"github.com/HasanNugroho/gin-clean/pkg/jwt"
 	"github.com/HasanNugroho/gin-clean/pkg/logger"
+	"github.com/HasanNugroho/gin-clean/pkg/mail"
+	"github.com/HasanNugroho/gin-clean/pkg/policy"
 	"github.com/gin-gonic/gin"
 	"github.com/go-playground/validator/v10"
 	"github.com/sarulabs/di/v2"
@@ -91,8 +96,80 @@ func RegisterDependency(builder *di.Builder) (*di.Builder, error) {
 			Name: "jwt",
 			Build: func(ctn di.Container) (interface{}, error) {
 				cfg := ctn.Get("config").(*config.Config)
-				cache := ctn.Get("cache").(*cache.RedisCache)
-				return jwt.SetJWTHelper(cfg, cache), nil
+				cache := ctn.Get("cache").(cache.Cache)
+				log := ctn.Get("logger").(*logger.Logger)
+
+				tokenGenerator, err := jwt.SetJWTHelper(cfg, cache)
+				if err != nil {
+					log.Fatal("❌ Failed to load JWT signing key", err)
+					return nil, err
+				}
+				return tokenGenerator, nil
+			},
+		},
+
+		// Cursor codec for keyset pagination
+		{
+			Name: "cursor-codec",
+			Build: func(ctn di.Container) (interface{}, error) {
+				cfg := ctn.Get("config").(*config.Config)
+				log := ctn.Get("logger").(*logger.Logger)
+
+				secret := []byte(cfg.Secret.CursorSecret)
+				if len(secret) == 0 {
+					// Cursors handed out before a restart stop being accepted.
+					log.Warn("⚠️ CURSOR_SECRET is not set, signing cursors with a random key")
+					secret = make([]byte, 32)
+					if _, err := rand.Read(secret); err != nil {
+						return nil, err
+					}
+				}
+				return cursor.NewCodec(secret), nil
+			},
+		},
+
+		// Mail
+		{
+			Name: "mailer",
+			Build: func(ctn di.Container) (interface{}, error) {
+				cfg := ctn.Get("config").(*config.Config)
+				log := ctn.Get("logger").(*logger.Logger)
+
+				sender, err := mail.NewSender(cfg, log)
+				if err != nil {
+					log.Fatal("❌ Failed to initialize mail sender", err)
+					return nil, err
+				}
+				return sender, nil
+			},
+		},
+
+		// Policy engine, only loaded when POLICY_FILE is set
+		{
+			Name: "policy-engine",
+			Build: func(ctn di.Container) (interface{}, error) {
+				cfg := ctn.Get("config").(*config.Config)
+				log := ctn.Get("logger").(*logger.Logger)
+
+				if cfg.Policy.File == "" {
+					return (*policy.Engine)(nil), nil
+				}
+
+				engine, err := policy.Load(cfg.Policy.File)
+				if err != nil {
+					log.Fatal("❌ Failed to load policy file", err)
+					return nil, err
+				}
+
+				interval, _ := time.ParseDuration(cfg.Policy.ReloadInterval)
+				go engine.Watch(context.Background(), interval, func(err error) {
+					if err != nil {
+						log.Error("Failed to reload policy file, keeping previous rules", err, "path", cfg.Policy.File)
+						return
+					}
+					log.Info("Policy file reloaded", "path", cfg.Policy.File)
+				})
+				return engine, nil
 			},
 		},
 
@@ -114,19 +191,43 @@ func RegisterDependency(builder *di.Builder) (*di.Builder, error) {
 			Name: "auth-middleware",
 			Build: func(ctn di.Container) (interface{}, error) {
 				var (
-					log     = ctn.Get("logger").(*logger.Logger)
-					service = ctn.Get("user-service").(*service.UserService)
-					jwt     = ctn.Get("jwt").(*jwt.TokenGenerator)
-					cache   = ctn.Get("cache").(*cache.RedisCache)
+					log           = ctn.Get("logger").(*logger.Logger)
+					organizations = ctn.Get("organization-service").(*service.OrganizationService)
+					service       = ctn.Get("user-service").(*service.UserService)
+					jwt           = ctn.Get("jwt").(*jwt.TokenGenerator)
+					cfg           = ctn.Get("config").(*config.Config)
 				)
 				return middleware.NewAuthMiddleware(
 					log,
 					service,
+					organizations,
 					jwt,
-					cache,
+					cfg,
 				), nil
 			},
 		},
+		{
+			Name: "permission-middleware",
+			Build: func(ctn di.Container) (interface{}, error) {
+				var (
+					log  = ctn.Get("logger").(*logger.Logger)
+					rbac = ctn.Get("rbac-service").(*service.RBACService)
+				)
+				return middleware.NewPermissionMiddleware(log, rbac), nil
+			},
+		},
+		{
+			Name: "policy-middleware",
+			Build: func(ctn di.Container) (interface{}, error) {
+				var (
+					log    = ctn.Get("logger").(*logger.Logger)
+					auth   = ctn.Get("auth-middleware").(*middleware.AuthMiddleware)
+					engine = ctn.Get("policy-engine").(*policy.Engine)
+					cfg    = ctn.Get("config").(*config.Config)
+				)
+				return middleware.NewPolicyMiddleware(log, auth, engine, cfg), nil
+			},
+		},
 		// Initialize rate-limiter
 		{
 			Name: "rate-limit",
@@ -135,7 +236,7 @@ func RegisterDependency(builder *di.Builder) (*di.Builder, error) {
 				var (
 					log   = ctn.Get("logger").(*logger.Logger)
 					cfg   = ctn.Get("config").(*config.Config)
-					cache = ctn.Get("cache").(*cache.RedisCache)
+					cache = ctn.Get("cache").(cache.Cache)
 				)
 				rateLimit, err := middleware.NewRateLimiter(cfg, cache)
 				if err != nil {
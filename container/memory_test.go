@@ -0,0 +1,322 @@
+package container
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/HasanNugroho/gin-clean/config"
+	"github.com/HasanNugroho/gin-clean/internal/interfaces/http/middleware"
+	"github.com/HasanNugroho/gin-clean/pkg/logger"
+	"github.com/HasanNugroho/gin-clean/pkg/totp"
+	"github.com/gin-gonic/gin"
+)
+
+// testServer is the application running on the Memory profile.
+type testServer struct {
+	t      *testing.T
+	engine *gin.Engine
+}
+
+// newTestServer starts the application with access tokens valid for
+// tokenExpiry, which is also how long refresh tokens wait before they can be
+// used.
+func newTestServer(t *testing.T, tokenExpiry time.Duration) *testServer {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	cfg := &config.Config{
+		Server: config.Server{Name: "test", LogLevel: 5},
+		Secret: config.Secret{
+			Jwt:                "test-secret",
+			TokenExpiry:        tokenExpiry.String(),
+			RefreshTokenExpiry: "1h",
+		},
+		Context:      config.Context{Timeout: int(5 * time.Second)},
+		Security:     config.Security{RateLimit: "1000-M"},
+		Registration: config.Registration{AutoLogin: true},
+	}
+
+	engine := gin.New()
+	engine.Use(middleware.ErrorHandler(logger.NewLogger(5)))
+
+	ctn, err := Build(engine, WithConfig(cfg), Memory)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ctn.Clean() })
+
+	return &testServer{t: t, engine: engine}
+}
+
+// response is the envelope of every response of the API.
+type response struct {
+	Data json.RawMessage `json:"data"`
+	Meta struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	} `json:"meta"`
+}
+
+// do sends a request with a JSON body, authenticated with token if not
+// empty, and checks the status of the response.
+func (s *testServer) do(method, path, token string, body interface{}, status int, headers ...string) (*httptest.ResponseRecorder, response) {
+	s.t.Helper()
+
+	var payload bytes.Buffer
+	if body != nil {
+		if err := json.NewEncoder(&payload).Encode(body); err != nil {
+			s.t.Fatal(err)
+		}
+	}
+
+	req := httptest.NewRequest(method, path, &payload)
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	for i := 0; i+1 < len(headers); i += 2 {
+		req.Header.Set(headers[i], headers[i+1])
+	}
+
+	recorder := httptest.NewRecorder()
+	s.engine.ServeHTTP(recorder, req)
+	if recorder.Code != status {
+		s.t.Fatalf("%s %s = %d, want %d: %s", method, path, recorder.Code, status, recorder.Body)
+	}
+
+	var resp response
+	if err := json.Unmarshal(recorder.Body.Bytes(), &resp); err != nil {
+		s.t.Fatalf("%s %s: invalid response body %q: %v", method, path, recorder.Body, err)
+	}
+	return recorder, resp
+}
+
+type tokens struct {
+	Token        string `json:"token"`
+	RefreshToken string `json:"refresh_token"`
+}
+
+func (s *testServer) tokens(resp response) tokens {
+	s.t.Helper()
+	var result tokens
+	if err := json.Unmarshal(resp.Data, &result); err != nil {
+		s.t.Fatal(err)
+	}
+	if result.Token == "" || result.RefreshToken == "" {
+		s.t.Fatalf("response carries no tokens: %s", resp.Data)
+	}
+	return result
+}
+
+type user struct {
+	ID      string `json:"id"`
+	Name    string `json:"name"`
+	Email   string `json:"email"`
+	Version int    `json:"version"`
+}
+
+func (s *testServer) me(token string) user {
+	s.t.Helper()
+	_, resp := s.do(http.MethodGet, "/api/v1/users/me", token, nil, http.StatusOK)
+	var result user
+	if err := json.Unmarshal(resp.Data, &result); err != nil {
+		s.t.Fatal(err)
+	}
+	return result
+}
+
+var jane = map[string]string{
+	"name":         "Jane",
+	"email":        "jane@example.com",
+	"phone_number": "0812345678",
+	"password":     "secret123",
+}
+
+func TestSessionLifecycle(t *testing.T) {
+	s := newTestServer(t, 2*time.Second)
+
+	_, resp := s.do(http.MethodPost, "/api/v1/auth/register", "", jane, http.StatusCreated)
+	registered := s.tokens(resp)
+	if got := s.me(registered.Token); got.Email != jane["email"] {
+		t.Errorf("registered account = %+v", got)
+	}
+
+	s.do(http.MethodPost, "/api/v1/auth/register", "", jane, http.StatusConflict)
+
+	s.do(http.MethodPost, "/api/v1/auth/login", "", map[string]string{
+		"email": jane["email"], "password": "wrong-password",
+	}, http.StatusUnauthorized)
+
+	_, resp = s.do(http.MethodPost, "/api/v1/auth/login", "", map[string]string{
+		"email": jane["email"], "password": jane["password"],
+	}, http.StatusOK)
+	login := s.tokens(resp)
+
+	// Neither token authenticates in place of the other.
+	s.do(http.MethodGet, "/api/v1/users/me", login.RefreshToken, nil, http.StatusUnauthorized)
+	s.do(http.MethodPost, "/api/v1/auth/refresh", "", map[string]string{
+		"refresh_token": login.Token,
+	}, http.StatusUnauthorized)
+
+	// A refresh token is not valid before the access token issued with it
+	// has expired.
+	s.do(http.MethodPost, "/api/v1/auth/refresh", "", map[string]string{
+		"refresh_token": login.RefreshToken,
+	}, http.StatusUnauthorized)
+
+	// Logging out of the registration session leaves the others alone.
+	s.do(http.MethodPost, "/api/v1/auth/logout", registered.Token, map[string]string{
+		"refresh_token": registered.RefreshToken,
+	}, http.StatusOK)
+	s.do(http.MethodGet, "/api/v1/users/me", registered.Token, nil, http.StatusUnauthorized)
+
+	// Token times are in whole seconds, so a 2s token stays valid for at
+	// least a second and the refresh token is valid after at most 2s.
+	time.Sleep(2100 * time.Millisecond)
+
+	_, resp = s.do(http.MethodPost, "/api/v1/auth/refresh", "", map[string]string{
+		"refresh_token": login.RefreshToken,
+	}, http.StatusOK)
+	refreshed := s.tokens(resp)
+	s.me(refreshed.Token)
+
+	s.do(http.MethodPost, "/api/v1/auth/refresh", "", map[string]string{
+		"refresh_token": registered.RefreshToken,
+	}, http.StatusUnauthorized)
+
+	// A refresh token is single-use, and reusing one ends its session.
+	s.do(http.MethodPost, "/api/v1/auth/refresh", "", map[string]string{
+		"refresh_token": login.RefreshToken,
+	}, http.StatusUnauthorized)
+	s.do(http.MethodGet, "/api/v1/users/me", refreshed.Token, nil, http.StatusUnauthorized)
+}
+
+func TestLogoutRejectsRefreshTokenOfAnotherUser(t *testing.T) {
+	s := newTestServer(t, 15*time.Minute)
+
+	_, resp := s.do(http.MethodPost, "/api/v1/auth/register", "", jane, http.StatusCreated)
+	janeTokens := s.tokens(resp)
+
+	john := map[string]string{
+		"name":         "John",
+		"email":        "john@example.com",
+		"phone_number": "0812345679",
+		"password":     "secret123",
+	}
+	_, resp = s.do(http.MethodPost, "/api/v1/auth/register", "", john, http.StatusCreated)
+	johnTokens := s.tokens(resp)
+
+	s.do(http.MethodPost, "/api/v1/auth/logout", janeTokens.Token, map[string]string{
+		"refresh_token": johnTokens.RefreshToken,
+	}, http.StatusUnauthorized)
+
+	// John's session is still open.
+	s.do(http.MethodPost, "/api/v1/auth/logout", johnTokens.Token, map[string]string{
+		"refresh_token": johnTokens.RefreshToken,
+	}, http.StatusOK)
+}
+
+func TestUnauthenticated(t *testing.T) {
+	s := newTestServer(t, 15*time.Minute)
+
+	s.do(http.MethodGet, "/api/v1/users/me", "", nil, http.StatusUnauthorized)
+	s.do(http.MethodGet, "/api/v1/users/me", "not-a-token", nil, http.StatusUnauthorized)
+	s.do(http.MethodPost, "/api/v1/auth/register", "", map[string]string{"email": "jane"}, http.StatusBadRequest)
+}
+
+func TestPatchWithETag(t *testing.T) {
+	s := newTestServer(t, 15*time.Minute)
+
+	_, resp := s.do(http.MethodPost, "/api/v1/auth/register", "", jane, http.StatusCreated)
+	token := s.tokens(resp).Token
+	me := s.me(token)
+
+	path := "/api/v1/users/" + me.ID
+	recorder, _ := s.do(http.MethodGet, path, token, nil, http.StatusOK)
+	etag := recorder.Header().Get("ETag")
+	if etag == "" {
+		t.Fatal("GET returned no ETag")
+	}
+
+	recorder, _ = s.do(http.MethodPatch, path, token, map[string]string{"name": "Janet"}, http.StatusOK, "If-Match", etag)
+	if updated := recorder.Header().Get("ETag"); updated == "" || updated == etag {
+		t.Errorf("ETag after PATCH = %q, was %q", updated, etag)
+	}
+
+	// The tag read before the update is stale.
+	s.do(http.MethodPatch, path, token, map[string]string{"name": "Jan"}, http.StatusPreconditionFailed, "If-Match", etag)
+	s.do(http.MethodPatch, path, token, map[string]string{"name": "Jan"}, http.StatusPreconditionFailed, "If-Match", "W/1")
+
+	// Changes must name the version they are based on.
+	s.do(http.MethodPatch, path, token, map[string]string{"name": "Jan"}, http.StatusPreconditionRequired)
+	s.do(http.MethodPatch, path, token, map[string]string{"name": "Jan"}, http.StatusPreconditionRequired, "If-Match", "*")
+	s.do(http.MethodDelete, path, token, nil, http.StatusPreconditionRequired)
+	if got := s.me(token); got.Name != "Janet" {
+		t.Errorf("name = %q, want Janet", got.Name)
+	}
+
+	s.do(http.MethodPatch, path, token, map[string]interface{}{"name": nil}, http.StatusBadRequest)
+}
+
+func TestMFALoginWithRecoveryCode(t *testing.T) {
+	s := newTestServer(t, 15*time.Minute)
+
+	_, resp := s.do(http.MethodPost, "/api/v1/auth/register", "", jane, http.StatusCreated)
+	token := s.tokens(resp).Token
+
+	_, resp = s.do(http.MethodPost, "/api/v1/auth/mfa/enroll", token, nil, http.StatusOK)
+	var enrollment struct {
+		Secret string `json:"secret"`
+	}
+	if err := json.Unmarshal(resp.Data, &enrollment); err != nil {
+		t.Fatal(err)
+	}
+	code, err := totp.Code(enrollment.Secret, totp.Counter(time.Now()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, resp = s.do(http.MethodPost, "/api/v1/auth/mfa/confirm", token, map[string]string{"code": code}, http.StatusOK)
+	var recovery struct {
+		RecoveryCodes []string `json:"recovery_codes"`
+	}
+	if err := json.Unmarshal(resp.Data, &recovery); err != nil {
+		t.Fatal(err)
+	}
+	if len(recovery.RecoveryCodes) == 0 {
+		t.Fatalf("no recovery codes: %s", resp.Data)
+	}
+
+	login := func() string {
+		t.Helper()
+		_, resp := s.do(http.MethodPost, "/api/v1/auth/login", "", map[string]string{
+			"email": jane["email"], "password": jane["password"],
+		}, http.StatusOK)
+		var challenge struct {
+			MFARequired bool   `json:"mfa_required"`
+			MFAToken    string `json:"mfa_token"`
+			Token       string `json:"token"`
+		}
+		if err := json.Unmarshal(resp.Data, &challenge); err != nil {
+			t.Fatal(err)
+		}
+		if !challenge.MFARequired || challenge.MFAToken == "" || challenge.Token != "" {
+			t.Fatalf("login of an MFA account = %s", resp.Data)
+		}
+		return challenge.MFAToken
+	}
+
+	_, resp = s.do(http.MethodPost, "/api/v1/auth/mfa/verify", "", map[string]string{
+		"mfa_token": login(), "recovery_code": recovery.RecoveryCodes[0],
+	}, http.StatusOK)
+	s.me(s.tokens(resp).Token)
+
+	// Recovery codes are single-use.
+	s.do(http.MethodPost, "/api/v1/auth/mfa/verify", "", map[string]string{
+		"mfa_token": login(), "recovery_code": recovery.RecoveryCodes[0],
+	}, http.StatusUnauthorized)
+}
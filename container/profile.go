@@ -0,0 +1,108 @@
+package container
+
+import (
+	"github.com/HasanNugroho/gin-clean/config"
+	"github.com/HasanNugroho/gin-clean/internal/infrastructure/presistence/cache"
+	"github.com/HasanNugroho/gin-clean/internal/infrastructure/presistence/memory"
+	"github.com/sarulabs/di/v2"
+)
+
+// Profile replaces definitions of the container once every default one is
+// registered, to swap the backends of the application.
+type Profile func(builder *di.Builder) error
+
+// WithConfig provides the configuration instead of loading it from .env and
+// the environment. Settings left empty get the same defaults as loaded ones.
+func WithConfig(cfg *config.Config) Profile {
+	return func(builder *di.Builder) error {
+		if err := cfg.Normalize(); err != nil {
+			return err
+		}
+
+		return builder.Add(di.Def{
+			Name: "config",
+			Build: func(ctn di.Container) (interface{}, error) {
+				return cfg, nil
+			},
+		})
+	}
+}
+
+// Memory keeps the data and the cache in the memory of the process, so the
+// application runs, handlers included, without PostgreSQL or Redis. Every
+// container built with it starts from an empty store seeded like the
+// migrations do.
+func Memory(builder *di.Builder) error {
+	definitions := []di.Def{
+		{
+			Name: "memory-store",
+			Build: func(ctn di.Container) (interface{}, error) {
+				return memory.NewStore(), nil
+			},
+		},
+		{
+			Name: "cache",
+			Build: func(ctn di.Container) (interface{}, error) {
+				return cache.NewMemoryCache(), nil
+			},
+		},
+
+		// REPOSITORY
+		{
+			Name: "user-repository",
+			Build: func(ctn di.Container) (interface{}, error) {
+				store := ctn.Get("memory-store").(*memory.Store)
+				return memory.NewUserRepository(store), nil
+			},
+		},
+		{
+			Name: "session-repository",
+			Build: func(ctn di.Container) (interface{}, error) {
+				store := ctn.Get("memory-store").(*memory.Store)
+				return memory.NewSessionRepository(store), nil
+			},
+		},
+		{
+			Name: "role-repository",
+			Build: func(ctn di.Container) (interface{}, error) {
+				store := ctn.Get("memory-store").(*memory.Store)
+				return memory.NewRoleRepository(store), nil
+			},
+		},
+		{
+			Name: "recovery-code-repository",
+			Build: func(ctn di.Container) (interface{}, error) {
+				store := ctn.Get("memory-store").(*memory.Store)
+				return memory.NewRecoveryCodeRepository(store), nil
+			},
+		},
+		{
+			Name: "invitation-repository",
+			Build: func(ctn di.Container) (interface{}, error) {
+				store := ctn.Get("memory-store").(*memory.Store)
+				return memory.NewInvitationRepository(store), nil
+			},
+		},
+		{
+			Name: "tx-manager",
+			Build: func(ctn di.Container) (interface{}, error) {
+				store := ctn.Get("memory-store").(*memory.Store)
+				return memory.NewTxManager(store), nil
+			},
+		},
+		{
+			Name: "organization-repository",
+			Build: func(ctn di.Container) (interface{}, error) {
+				store := ctn.Get("memory-store").(*memory.Store)
+				return memory.NewOrganizationRepository(store), nil
+			},
+		},
+	}
+
+	for _, def := range definitions {
+		if err := builder.Add(def); err != nil {
+			return err
+		}
+	}
+	return nil
+}
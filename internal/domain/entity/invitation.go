@@ -0,0 +1,29 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/HasanNugroho/gin-clean/pkg/constants"
+)
+
+// Invitation lets someone create an account with a preassigned role. The link
+// mailed to them carries a signed token of which only the hash is stored, and
+// resending the invitation replaces it.
+type Invitation struct {
+	ID             string         `gorm:"primaryKey;type:uuid;default:uuid_generate_v4();" json:"id"`
+	Email          string         `gorm:"not null;index" json:"email"`
+	Role           constants.Role `gorm:"not null;default:'user'" json:"role"`
+	OrganizationID *string        `gorm:"type:uuid;index" json:"organization_id,omitempty"`
+	InvitedBy      *string        `gorm:"type:uuid" json:"invited_by,omitempty"`
+	TokenHash      string         `gorm:"uniqueIndex;not null" json:"-"`
+	ExpiresAt      time.Time      `json:"expires_at"`
+	AcceptedAt     *time.Time     `json:"accepted_at,omitempty"`
+	RevokedAt      *time.Time     `json:"revoked_at,omitempty"`
+	CreatedAt      time.Time      `json:"created_at"`
+	UpdatedAt      time.Time      `json:"updated_at"`
+}
+
+// IsPending reports whether the invitation can still be accepted.
+func (i *Invitation) IsPending(now time.Time) bool {
+	return i.AcceptedAt == nil && i.RevokedAt == nil && now.Before(i.ExpiresAt)
+}
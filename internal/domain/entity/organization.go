@@ -0,0 +1,27 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/HasanNugroho/gin-clean/pkg/constants"
+)
+
+// Organization is a tenant. Users belong to organizations through
+// memberships and hold a role per organization.
+type Organization struct {
+	ID        string    `gorm:"primaryKey;type:uuid;default:uuid_generate_v4();" json:"id"`
+	Name      string    `gorm:"not null" json:"name"`
+	Slug      string    `gorm:"uniqueIndex;not null" json:"slug"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type Membership struct {
+	OrganizationID string         `gorm:"primaryKey;type:uuid" json:"organization_id"`
+	UserID         string         `gorm:"primaryKey;type:uuid" json:"user_id"`
+	Role           constants.Role `gorm:"not null;default:'user'" json:"role"`
+	CreatedAt      time.Time      `json:"created_at"`
+
+	Organization *Organization `gorm:"foreignKey:OrganizationID" json:"organization,omitempty"`
+	User         *User         `gorm:"foreignKey:UserID" json:"user,omitempty"`
+}
@@ -0,0 +1,46 @@
+package entity
+
+import (
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// RecoveryCode is a one-time code that stands in for a TOTP code when the
+// user has lost their authenticator. Only its bcrypt hash is stored.
+type RecoveryCode struct {
+	ID         string     `gorm:"primaryKey;type:uuid;default:uuid_generate_v4();" json:"id"`
+	UserID     string     `gorm:"type:uuid;not null;index" json:"user_id"`
+	CipherText string     `gorm:"not null" json:"-"`
+	UsedAt     *time.Time `json:"used_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+func (RecoveryCode) TableName() string {
+	return "mfa_recovery_codes"
+}
+
+func NewRecoveryCode(userID, code string) (*RecoveryCode, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(NormalizeRecoveryCode(code)), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, err
+	}
+
+	return &RecoveryCode{
+		UserID:     userID,
+		CipherText: string(hash),
+	}, nil
+}
+
+func (r *RecoveryCode) Matches(code string) bool {
+	err := bcrypt.CompareHashAndPassword([]byte(r.CipherText), []byte(NormalizeRecoveryCode(code)))
+	return err == nil
+}
+
+// NormalizeRecoveryCode makes codes comparable regardless of case, spaces and
+// the dash they are displayed with.
+func NormalizeRecoveryCode(code string) string {
+	code = strings.ToLower(code)
+	return strings.NewReplacer("-", "", " ", "").Replace(code)
+}
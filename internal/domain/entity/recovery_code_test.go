@@ -0,0 +1,28 @@
+package entity
+
+import "testing"
+
+func TestRecoveryCodeMatches(t *testing.T) {
+	code, err := NewRecoveryCode("user", "abcde-fghij")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if code.CipherText == "" || code.CipherText == "abcdefghij" {
+		t.Fatalf("cipher text = %q, want a hash", code.CipherText)
+	}
+
+	tests := map[string]bool{
+		"abcde-fghij":   true,
+		"ABCDE-FGHIJ":   true,
+		"abcdefghij":    true,
+		" abcde fghij ": true,
+		"abcde-fghik":   false,
+		"abcde":         false,
+		"":              false,
+	}
+	for input, want := range tests {
+		if got := code.Matches(input); got != want {
+			t.Errorf("Matches(%q) = %v, want %v", input, got, want)
+		}
+	}
+}
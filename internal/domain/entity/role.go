@@ -0,0 +1,21 @@
+package entity
+
+import "time"
+
+type Role struct {
+	Name        string    `gorm:"primaryKey" json:"name"`
+	Description string    `json:"description"`
+	Permissions []string  `gorm:"-" json:"permissions"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+type Permission struct {
+	Name        string `gorm:"primaryKey" json:"name"`
+	Description string `json:"description"`
+}
+
+// RolePermission assigns a permission to a role.
+type RolePermission struct {
+	RoleName       string `gorm:"primaryKey"`
+	PermissionName string `gorm:"primaryKey"`
+}
@@ -0,0 +1,23 @@
+package entity
+
+import "time"
+
+// Session is a login on a single device. Its ID doubles as the refresh token
+// family id and is carried as the "sid" claim of every token issued for it.
+type Session struct {
+	ID     string `gorm:"primaryKey;type:uuid;default:uuid_generate_v4();" json:"id"`
+	UserID string `gorm:"type:uuid;not null;index" json:"user_id"`
+	// OrganizationID is the organization the session acts in, if any.
+	OrganizationID *string    `gorm:"type:uuid" json:"organization_id,omitempty"`
+	Device         string     `json:"device"`
+	IPAddress      string     `json:"ip_address"`
+	UserAgent      string     `json:"user_agent"`
+	CreatedAt      time.Time  `json:"created_at"`
+	LastSeenAt     time.Time  `json:"last_seen_at"`
+	ExpiresAt      time.Time  `json:"expires_at"`
+	RevokedAt      *time.Time `json:"revoked_at,omitempty"`
+}
+
+func (s *Session) IsActive(now time.Time) bool {
+	return s.RevokedAt == nil && now.Before(s.ExpiresAt)
+}
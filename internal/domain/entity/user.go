@@ -10,16 +10,25 @@ import (
 )
 
 type User struct {
-	ID          string         `gorm:"primaryKey;type:uuid;default:uuid_generate_v4();" json:"id"`
-	Name        string         `gorm:"not null" json:"name"`
-	Email       string         `gorm:"uniqueIndex" json:"email"`
-	PhoneNumber string         `gorm:"not null" json:"phone_number"`
-	CipherText  string         `json:"-"`
-	Role        constants.Role `gorm:"default:'user'" json:"role"`
-	IsActive    bool           `gorm:"default:true" json:"is_active"`
-	CreatedAt   time.Time      `json:"created_at"`
-	UpdatedAt   time.Time      `json:"updated_at"`
-	DeletedAt   gorm.DeletedAt `gorm:"index" json:"-"`
+	ID              string         `gorm:"primaryKey;type:uuid;default:uuid_generate_v4();" json:"id"`
+	Name            string         `gorm:"not null" json:"name"`
+	Email           string         `gorm:"uniqueIndex:idx_users_email_active,where:deleted_at IS NULL" json:"email"`
+	PhoneNumber     string         `gorm:"not null" json:"phone_number"`
+	CipherText      string         `json:"-"`
+	Role            constants.Role `gorm:"default:'user'" json:"role"`
+	IsActive        bool           `gorm:"default:true" json:"is_active"`
+	TokenVersion    int            `gorm:"not null;default:0" json:"-"`
+	Version         int            `gorm:"not null;default:1" json:"version"`
+	EmailVerifiedAt *time.Time     `json:"email_verified_at"`
+	MFAEnabled      bool           `gorm:"column:mfa_enabled;not null;default:false" json:"mfa_enabled"`
+	MFASecret       string         `gorm:"column:mfa_secret" json:"-"`
+	CreatedAt       time.Time      `json:"created_at"`
+	UpdatedAt       time.Time      `json:"updated_at"`
+	DeletedAt       gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+func (u *User) IsEmailVerified() bool {
+	return u.EmailVerifiedAt != nil
 }
 
 func (u *User) VerifyPassword(plainPassword string) bool {
@@ -0,0 +1,28 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/HasanNugroho/gin-clean/internal/domain/entity"
+)
+
+// InvitationRepository scopes its methods to the organization of ctx, if any,
+// except for those used by invitees, who act in no organization yet.
+type InvitationRepository interface {
+	Create(ctx context.Context, invitation *entity.Invitation) error
+	GetByID(ctx context.Context, id string) (*entity.Invitation, error)
+	// GetByTokenHash is not scoped.
+	GetByTokenHash(ctx context.Context, tokenHash string) (*entity.Invitation, error)
+	// GetPendingByEmail returns the invitation of the email that can still be
+	// accepted, if any.
+	GetPendingByEmail(ctx context.Context, email string) (*entity.Invitation, error)
+	ListPending(ctx context.Context) ([]entity.Invitation, error)
+	// Renew replaces the token of an invitation that was neither accepted nor
+	// revoked, expired ones included, and extends it.
+	Renew(ctx context.Context, id string, tokenHash string, expiresAt time.Time) error
+	// MarkAccepted reports false when the invitation was no longer pending. It
+	// is not scoped.
+	MarkAccepted(ctx context.Context, id string, acceptedAt time.Time) (bool, error)
+	Revoke(ctx context.Context, id string, revokedAt time.Time) error
+}
@@ -0,0 +1,22 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/HasanNugroho/gin-clean/internal/domain/entity"
+)
+
+type OrganizationRepository interface {
+	// Create stores the organization together with the membership of its
+	// first member.
+	Create(ctx context.Context, organization *entity.Organization, owner *entity.Membership) error
+	GetByID(ctx context.Context, id string) (*entity.Organization, error)
+	GetBySlug(ctx context.Context, slug string) (*entity.Organization, error)
+	GetMembership(ctx context.Context, organizationID, userID string) (*entity.Membership, error)
+	// ListMemberships returns the memberships of a user with their organization.
+	ListMemberships(ctx context.Context, userID string) ([]entity.Membership, error)
+	// ListMembers returns the memberships of an organization with their user.
+	ListMembers(ctx context.Context, organizationID string) ([]entity.Membership, error)
+	AddMember(ctx context.Context, membership *entity.Membership) error
+	RemoveMember(ctx context.Context, organizationID, userID string) error
+}
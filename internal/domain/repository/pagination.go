@@ -0,0 +1,24 @@
+package repository
+
+import "time"
+
+// Cursor is a position in a listing ordered by (created_at, id).
+type Cursor struct {
+	CreatedAt time.Time `json:"t"`
+	ID        string    `json:"id"`
+}
+
+// CursorPage asks for up to Limit rows right after After, or right before
+// Before, or from the start of the listing when both are nil.
+type CursorPage struct {
+	After  *Cursor
+	Before *Cursor
+	Limit  int
+}
+
+// CursorResult holds the positions to continue from in either direction. They
+// are nil at the ends of the listing.
+type CursorResult struct {
+	Next *Cursor
+	Prev *Cursor
+}
@@ -0,0 +1,17 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/HasanNugroho/gin-clean/internal/domain/entity"
+)
+
+type RecoveryCodeRepository interface {
+	ListUnusedByUser(ctx context.Context, userID string) ([]entity.RecoveryCode, error)
+	// Replace deletes every code of the user and stores the given ones.
+	Replace(ctx context.Context, userID string, codes []entity.RecoveryCode) error
+	// MarkUsed reports false when the code had already been used.
+	MarkUsed(ctx context.Context, id string, usedAt time.Time) (bool, error)
+	DeleteByUser(ctx context.Context, userID string) error
+}
@@ -0,0 +1,21 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/HasanNugroho/gin-clean/internal/domain/entity"
+)
+
+type RoleRepository interface {
+	List(ctx context.Context) ([]entity.Role, error)
+	GetByName(ctx context.Context, name string) (*entity.Role, error)
+	Create(ctx context.Context, role *entity.Role) error
+	Delete(ctx context.Context, name string) error
+	// InUse reports whether any user, deleted ones included, or any
+	// organization membership holds the role.
+	InUse(ctx context.Context, name string) (bool, error)
+	ListPermissions(ctx context.Context) ([]entity.Permission, error)
+	PermissionsOf(ctx context.Context, role string) ([]string, error)
+	Grant(ctx context.Context, role string, permissions []string) error
+	Revoke(ctx context.Context, role string, permission string) error
+}
@@ -0,0 +1,17 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/HasanNugroho/gin-clean/internal/domain/entity"
+)
+
+type SessionRepository interface {
+	Create(ctx context.Context, session *entity.Session) error
+	GetByID(ctx context.Context, id string) (*entity.Session, error)
+	ListActiveByUser(ctx context.Context, userID string) ([]entity.Session, error)
+	Touch(ctx context.Context, session *entity.Session) error
+	Revoke(ctx context.Context, id string, revokedAt time.Time) error
+	RevokeAllByUser(ctx context.Context, userID string, revokedAt time.Time) error
+}
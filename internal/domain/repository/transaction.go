@@ -0,0 +1,14 @@
+package repository
+
+import "context"
+
+// TxManager groups repository calls into a unit of work.
+type TxManager interface {
+	// WithinTransaction runs fn in a transaction carried by the context passed
+	// to it, which repositories called with that context join. The
+	// transaction commits when fn returns nil and rolls back otherwise. Calls
+	// within fn join the outer transaction. fn may run again when the
+	// transaction is retried after a conflict with a concurrent one, so it
+	// must not depend on state it changed in an earlier run.
+	WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error
+}
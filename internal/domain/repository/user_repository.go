@@ -2,14 +2,56 @@ package repository
 
 import (
 	"context"
+	"time"
 
 	"github.com/HasanNugroho/gin-clean/internal/domain/entity"
 )
 
+// UserFilter narrows and orders a user listing. Zero values do not filter.
+// Sort is a column name, optionally prefixed with "-" for descending order.
+type UserFilter struct {
+	Role        string
+	IsActive    *bool
+	CreatedFrom *time.Time
+	CreatedTo   *time.Time
+	Search      string
+	Sort        string
+	Offset      int
+	Limit       int
+}
+
+// UserMatch is a user found by a search, along with how well it matches.
+type UserMatch struct {
+	User entity.User
+	Rank float64
+}
+
 type UserRepository interface {
 	Create(ctx context.Context, user *entity.User) error
 	GetByID(ctx context.Context, id string) (*entity.User, error)
 	GetByEmail(ctx context.Context, email string) (*entity.User, error)
+	// List returns a page of the users matching the filter and the number of
+	// matching users across all pages.
+	List(ctx context.Context, filter UserFilter) ([]entity.User, int64, error)
+	// ListByCursor returns the page of users matching the filter at the
+	// position of page, ordered by (created_at, id).
+	ListByCursor(ctx context.Context, filter UserFilter, page CursorPage) ([]entity.User, CursorResult, error)
+	// Search returns up to limit users matching the words of text as prefixes
+	// or, to tolerate typos, by similarity, best matches first.
+	Search(ctx context.Context, text string, limit int) ([]UserMatch, error)
+	// Update saves the user if it is still at user.Version, which it then
+	// increments, and fails with ErrPreconditionFailed otherwise.
 	Update(ctx context.Context, user *entity.User) error
 	Delete(ctx context.Context, id string) error
+	// ListDeleted returns a page of the soft-deleted users, most recently
+	// deleted first, and their total number.
+	ListDeleted(ctx context.Context, offset, limit int) ([]entity.User, int64, error)
+	GetDeletedByID(ctx context.Context, id string) (*entity.User, error)
+	Restore(ctx context.Context, id string) error
+	// Purge permanently deletes a soft-deleted user.
+	Purge(ctx context.Context, id string) error
+	// PurgeDeletedBefore permanently deletes the users soft-deleted before
+	// the given time and returns their number.
+	PurgeDeletedBefore(ctx context.Context, before time.Time) (int64, error)
+	IncrementTokenVersion(ctx context.Context, id string) (int, error)
 }
@@ -7,7 +7,14 @@ import (
 )
 
 type AuthService interface {
-	Login(ctx context.Context, request dto.LoginRequest) (result dto.AuthResponse, err error)
-	RefreshToken(ctx context.Context, request dto.RenewalTokenRequest) (result dto.AuthResponse, err error)
+	Login(ctx context.Context, request dto.LoginRequest, client dto.ClientInfo) (result dto.AuthResponse, err error)
+	Register(ctx context.Context, request dto.RegisterUserRequest, client dto.ClientInfo) (result dto.AuthResponse, err error)
+	VerifyMFA(ctx context.Context, request dto.MFAVerifyRequest, client dto.ClientInfo) (result dto.AuthResponse, err error)
+	RefreshToken(ctx context.Context, request dto.RenewalTokenRequest, client dto.ClientInfo) (result dto.AuthResponse, err error)
 	Logout(ctx context.Context, accessToken string, request dto.RenewalTokenRequest) (err error)
+	LogoutAll(ctx context.Context, userID string) (err error)
+	ForgotPassword(ctx context.Context, request dto.ForgotPasswordRequest) (err error)
+	ResetPassword(ctx context.Context, request dto.ResetPasswordRequest) (err error)
+	ListSessions(ctx context.Context, userID string, currentSessionID string) (result []dto.SessionResponse, err error)
+	RevokeSession(ctx context.Context, userID string, sessionID string) (err error)
 }
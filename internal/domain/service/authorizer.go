@@ -0,0 +1,46 @@
+package service
+
+import (
+	"context"
+
+	"github.com/HasanNugroho/gin-clean/internal/domain/entity"
+)
+
+// Actions understood by the built-in policies. Unless a policy says otherwise,
+// an action on a resource type maps to the "<type>:<action>" permission.
+const (
+	ActionCreate = "create"
+	ActionRead   = "read"
+	ActionUpdate = "update"
+	ActionDelete = "delete"
+	// ActionUpdatePrivileges covers changes to the role or status of a user,
+	// which users may not make to their own account.
+	ActionUpdatePrivileges = "update_privileges"
+)
+
+// ResourceUsers is the resource type of user records.
+const ResourceUsers = "users"
+
+// Resource identifies what an action is performed on. OwnerID is the user the
+// resource belongs to, if any.
+type Resource struct {
+	Type       string
+	ID         string
+	OwnerID    string
+	Attributes map[string]interface{}
+}
+
+type Authorizer interface {
+	// Authorize returns errors.ErrForbidden unless actor may perform action on
+	// resource.
+	Authorize(ctx context.Context, actor *entity.User, action string, resource Resource) (err error)
+}
+
+// UserResource describes a user record, which is owned by the user itself.
+func UserResource(id string) Resource {
+	return Resource{
+		Type:    ResourceUsers,
+		ID:      id,
+		OwnerID: id,
+	}
+}
@@ -0,0 +1,14 @@
+package service
+
+import (
+	"context"
+
+	"github.com/HasanNugroho/gin-clean/internal/domain/entity"
+	"github.com/HasanNugroho/gin-clean/internal/interfaces/http/dto"
+)
+
+type EmailVerificationService interface {
+	SendVerification(ctx context.Context, user *entity.User) (err error)
+	VerifyEmail(ctx context.Context, token string) (err error)
+	ResendVerification(ctx context.Context, request dto.ResendVerificationRequest) (err error)
+}
@@ -0,0 +1,16 @@
+package service
+
+import (
+	"context"
+
+	"github.com/HasanNugroho/gin-clean/internal/domain/entity"
+	"github.com/HasanNugroho/gin-clean/internal/interfaces/http/dto"
+)
+
+type InvitationService interface {
+	Invite(ctx context.Context, inviter *entity.User, req *dto.CreateInvitationRequest) (invitation *entity.Invitation, err error)
+	ListPending(ctx context.Context) (invitations []entity.Invitation, err error)
+	Resend(ctx context.Context, id string) (err error)
+	Revoke(ctx context.Context, id string) (err error)
+	Accept(ctx context.Context, req *dto.AcceptInvitationRequest) (email string, err error)
+}
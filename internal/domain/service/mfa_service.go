@@ -0,0 +1,14 @@
+package service
+
+import (
+	"context"
+
+	"github.com/HasanNugroho/gin-clean/internal/interfaces/http/dto"
+)
+
+type MFAService interface {
+	Enroll(ctx context.Context, userID string) (result dto.MFAEnrollResponse, err error)
+	Confirm(ctx context.Context, userID string, request dto.MFACodeRequest) (result dto.MFARecoveryCodesResponse, err error)
+	RegenerateRecoveryCodes(ctx context.Context, userID string, request dto.MFACodeRequest) (result dto.MFARecoveryCodesResponse, err error)
+	Disable(ctx context.Context, userID string, request dto.MFACodeRequest) (err error)
+}
@@ -0,0 +1,16 @@
+package service
+
+import (
+	"context"
+
+	"github.com/HasanNugroho/gin-clean/internal/domain/entity"
+	"github.com/HasanNugroho/gin-clean/internal/interfaces/http/dto"
+)
+
+type OrganizationService interface {
+	Create(ctx context.Context, owner *entity.User, req *dto.CreateOrganizationRequest) (organization *entity.Organization, err error)
+	ListMine(ctx context.Context, userID string) (memberships []entity.Membership, err error)
+	ListMembers(ctx context.Context, organizationID string) (memberships []entity.Membership, err error)
+	RemoveMember(ctx context.Context, organizationID string, userID string) (err error)
+	Membership(ctx context.Context, organizationID string, userID string) (membership *entity.Membership, err error)
+}
@@ -0,0 +1,19 @@
+package service
+
+import (
+	"context"
+
+	"github.com/HasanNugroho/gin-clean/internal/domain/entity"
+	"github.com/HasanNugroho/gin-clean/internal/interfaces/http/dto"
+)
+
+type RBACService interface {
+	ListRoles(ctx context.Context) (roles []entity.Role, err error)
+	GetRole(ctx context.Context, name string) (role *entity.Role, err error)
+	CreateRole(ctx context.Context, req *dto.CreateRoleRequest) (err error)
+	DeleteRole(ctx context.Context, name string) (err error)
+	ListPermissions(ctx context.Context) (permissions []entity.Permission, err error)
+	GrantPermissions(ctx context.Context, role string, req *dto.GrantPermissionsRequest) (err error)
+	RevokePermission(ctx context.Context, role string, permission string) (err error)
+	HasPermission(ctx context.Context, user *entity.User, permission string) (ok bool, err error)
+}
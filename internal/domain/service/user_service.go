@@ -8,9 +8,19 @@ import (
 )
 
 type UserService interface {
-	Create(ctx context.Context, req *dto.CreateUserRequest) (err error)
+	Create(ctx context.Context, actor *entity.User, req *dto.CreateUserRequest) (user *entity.User, err error)
+	Register(ctx context.Context, req *dto.RegisterUserRequest) (user *entity.User, err error)
 	GetById(ctx context.Context, id string) (user *entity.User, err error)
 	GetByEmail(ctx context.Context, email string) (user *entity.User, err error)
-	Update(ctx context.Context, id string, user *dto.UpdateUserRequest) (err error)
-	Delete(ctx context.Context, id string) (err error)
+	List(ctx context.Context, query *dto.ListUsersQuery) (users []entity.User, total int64, err error)
+	ListByCursor(ctx context.Context, query *dto.ListUsersQuery) (users []entity.User, next, prev string, err error)
+	Search(ctx context.Context, query *dto.SearchUsersQuery) (results []dto.UserSearchResult, err error)
+	Update(ctx context.Context, actor *entity.User, id string, updatedUser *dto.UpdateUserRequest) (user *entity.User, err error)
+	Patch(ctx context.Context, actor *entity.User, id string, patch *dto.PatchUserRequest) (user *entity.User, err error)
+	UpdateProfile(ctx context.Context, id string, req *dto.UpdateProfileRequest) (err error)
+	ChangePassword(ctx context.Context, id string, req *dto.ChangePasswordRequest) (err error)
+	Delete(ctx context.Context, id string, version int) (err error)
+	ListDeleted(ctx context.Context, query *dto.ListDeletedUsersQuery) (users []dto.DeletedUserResponse, total int64, err error)
+	Restore(ctx context.Context, id string) (user *entity.User, err error)
+	Purge(ctx context.Context, id string) (err error)
 }
@@ -0,0 +1,34 @@
+package cache
+
+import (
+	"context"
+	"time"
+)
+
+// Cache is a key-value store with expiring entries. Values are stored JSON
+// encoded, and reading a missing key fails with errors.ErrNotFound.
+type Cache interface {
+	Get(ctx context.Context, key string, value interface{}) error
+	// Take reads the value stored at key and deletes it in the same
+	// operation, so that only one caller can ever obtain it.
+	Take(ctx context.Context, key string, value interface{}) error
+	Incr(ctx context.Context, key string) int
+	Set(ctx context.Context, key string, value interface{}, expiration time.Duration) error
+	// CompareAndSwap atomically replaces the value of key with newValue when
+	// the stored value equals oldValue. It reports whether the swap happened.
+	CompareAndSwap(ctx context.Context, key string, oldValue, newValue interface{}, expiration time.Duration) (bool, error)
+	// SetNX stores value only when key does not exist yet. It reports whether
+	// the value was stored.
+	SetNX(ctx context.Context, key string, value interface{}, expiration time.Duration) (bool, error)
+	Expire(ctx context.Context, key string, expiration time.Duration) error
+	Delete(ctx context.Context, keys ...string) error
+	Exist(ctx context.Context, key string) (int64, error)
+	TTL(ctx context.Context, key string) (time.Duration, error)
+	Ping(ctx context.Context) error
+	Close() error
+}
+
+var (
+	_ Cache = (*RedisCache)(nil)
+	_ Cache = (*MemoryCache)(nil)
+)
@@ -0,0 +1,212 @@
+package cache
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/HasanNugroho/gin-clean/pkg/errors"
+)
+
+type memoryEntry struct {
+	value     []byte
+	expiresAt time.Time
+}
+
+func (e memoryEntry) expired(now time.Time) bool {
+	return !e.expiresAt.IsZero() && !now.Before(e.expiresAt)
+}
+
+// MemoryCache is a Cache held in the memory of the process, for tests and
+// single-instance development setups. It behaves like RedisCache, but its
+// entries are lost on restart and not shared between instances.
+type MemoryCache struct {
+	mu      sync.Mutex
+	entries map[string]memoryEntry
+}
+
+func NewMemoryCache() *MemoryCache {
+	return &MemoryCache{entries: map[string]memoryEntry{}}
+}
+
+// lookup returns the live entry of key, dropping it when it has expired. The
+// caller holds mu.
+func (c *MemoryCache) lookup(key string) (memoryEntry, bool) {
+	entry, ok := c.entries[key]
+	if !ok {
+		return entry, false
+	}
+	if entry.expired(time.Now()) {
+		delete(c.entries, key)
+		return entry, false
+	}
+	return entry, true
+}
+
+// store sets the value of key. An expiration of 0 or less keeps it forever.
+// The caller holds mu.
+func (c *MemoryCache) store(key string, value []byte, expiration time.Duration) {
+	entry := memoryEntry{value: value}
+	if expiration > 0 {
+		entry.expiresAt = time.Now().Add(expiration)
+	}
+	c.entries[key] = entry
+}
+
+func (c *MemoryCache) Get(ctx context.Context, key string, value interface{}) error {
+	c.mu.Lock()
+	entry, ok := c.lookup(key)
+	c.mu.Unlock()
+
+	if !ok {
+		return errors.ErrNotFound.WithMessage("cache key not found")
+	}
+	return json.Unmarshal(entry.value, value)
+}
+
+func (c *MemoryCache) Take(ctx context.Context, key string, value interface{}) error {
+	c.mu.Lock()
+	entry, ok := c.lookup(key)
+	delete(c.entries, key)
+	c.mu.Unlock()
+
+	if !ok {
+		return errors.ErrNotFound.WithMessage("cache key not found")
+	}
+	return json.Unmarshal(entry.value, value)
+}
+
+// Incr returns 0 when the stored value is not an integer, like RedisCache.
+func (c *MemoryCache) Incr(ctx context.Context, key string) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.lookup(key)
+	count := 0
+	if ok {
+		var err error
+		if count, err = strconv.Atoi(string(entry.value)); err != nil {
+			return 0
+		}
+	}
+	count++
+
+	// Like INCR, the expiration of the key is kept.
+	entry.value = []byte(strconv.Itoa(count))
+	c.entries[key] = entry
+	return count
+}
+
+func (c *MemoryCache) Set(ctx context.Context, key string, value interface{}, expiration time.Duration) error {
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	c.store(key, encoded, expiration)
+	c.mu.Unlock()
+	return nil
+}
+
+func (c *MemoryCache) CompareAndSwap(ctx context.Context, key string, oldValue, newValue interface{}, expiration time.Duration) (bool, error) {
+	oldBytes, err := json.Marshal(oldValue)
+	if err != nil {
+		return false, err
+	}
+	newBytes, err := json.Marshal(newValue)
+	if err != nil {
+		return false, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.lookup(key)
+	if !ok || !bytes.Equal(entry.value, oldBytes) {
+		return false, nil
+	}
+	c.store(key, newBytes, expiration)
+	return true, nil
+}
+
+func (c *MemoryCache) SetNX(ctx context.Context, key string, value interface{}, expiration time.Duration) (bool, error) {
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return false, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, ok := c.lookup(key); ok {
+		return false, nil
+	}
+	c.store(key, encoded, expiration)
+	return true, nil
+}
+
+// Expire deletes the key right away when expiration is 0 or less, like
+// EXPIRE.
+func (c *MemoryCache) Expire(ctx context.Context, key string, expiration time.Duration) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.lookup(key)
+	if !ok {
+		return nil
+	}
+	if expiration <= 0 {
+		delete(c.entries, key)
+		return nil
+	}
+	entry.expiresAt = time.Now().Add(expiration)
+	c.entries[key] = entry
+	return nil
+}
+
+func (c *MemoryCache) Delete(ctx context.Context, keys ...string) error {
+	c.mu.Lock()
+	for _, key := range keys {
+		delete(c.entries, key)
+	}
+	c.mu.Unlock()
+	return nil
+}
+
+func (c *MemoryCache) Exist(ctx context.Context, key string) (int64, error) {
+	c.mu.Lock()
+	_, ok := c.lookup(key)
+	c.mu.Unlock()
+
+	if !ok {
+		return 0, nil
+	}
+	return 1, nil
+}
+
+// TTL returns -1 for a key without expiration, like the TTL command.
+func (c *MemoryCache) TTL(ctx context.Context, key string) (time.Duration, error) {
+	c.mu.Lock()
+	entry, ok := c.lookup(key)
+	c.mu.Unlock()
+
+	if !ok {
+		return 0, errors.ErrNotFound.WithMessage("cache key not found")
+	}
+	if entry.expiresAt.IsZero() {
+		return -1, nil
+	}
+	return time.Until(entry.expiresAt), nil
+}
+
+func (c *MemoryCache) Ping(ctx context.Context) error {
+	return nil
+}
+
+func (c *MemoryCache) Close() error {
+	return nil
+}
@@ -0,0 +1,163 @@
+package cache
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/HasanNugroho/gin-clean/pkg/errors"
+)
+
+func TestMemoryCacheGetSet(t *testing.T) {
+	ctx := context.Background()
+	c := NewMemoryCache()
+
+	var value string
+	if err := c.Get(ctx, "key", &value); errors.StatusCode(err) != http.StatusNotFound {
+		t.Errorf("missing key: err = %v, want 404", err)
+	}
+
+	if err := c.Set(ctx, "key", "value", 0); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Get(ctx, "key", &value); err != nil || value != "value" {
+		t.Errorf("Get = %q, %v", value, err)
+	}
+	if n, err := c.Exist(ctx, "key"); err != nil || n != 1 {
+		t.Errorf("Exist = %d, %v, want 1", n, err)
+	}
+
+	if err := c.Delete(ctx, "key"); err != nil {
+		t.Fatal(err)
+	}
+	if n, _ := c.Exist(ctx, "key"); n != 0 {
+		t.Errorf("Exist after Delete = %d, want 0", n)
+	}
+}
+
+func TestMemoryCacheExpiration(t *testing.T) {
+	ctx := context.Background()
+	c := NewMemoryCache()
+
+	if err := c.Set(ctx, "short", 1, 10*time.Millisecond); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Set(ctx, "forever", 1, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	if ttl, err := c.TTL(ctx, "short"); err != nil || ttl <= 0 || ttl > 10*time.Millisecond {
+		t.Errorf("TTL(short) = %v, %v", ttl, err)
+	}
+	if ttl, err := c.TTL(ctx, "forever"); err != nil || ttl != -1 {
+		t.Errorf("TTL(forever) = %v, %v, want -1", ttl, err)
+	}
+
+	time.Sleep(20 * time.Millisecond)
+	var value int
+	if err := c.Get(ctx, "short", &value); err == nil {
+		t.Error("an expired key was returned")
+	}
+	if _, err := c.TTL(ctx, "short"); errors.StatusCode(err) != http.StatusNotFound {
+		t.Errorf("TTL of an expired key: err = %v, want 404", err)
+	}
+
+	if err := c.Expire(ctx, "forever", time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	if ttl, _ := c.TTL(ctx, "forever"); ttl <= 0 {
+		t.Errorf("TTL after Expire = %v", ttl)
+	}
+	if err := c.Expire(ctx, "forever", 0); err != nil {
+		t.Fatal(err)
+	}
+	if n, _ := c.Exist(ctx, "forever"); n != 0 {
+		t.Error("Expire with 0 kept the key")
+	}
+}
+
+func TestMemoryCacheTake(t *testing.T) {
+	ctx := context.Background()
+	c := NewMemoryCache()
+
+	if err := c.Set(ctx, "key", "value", time.Minute); err != nil {
+		t.Fatal(err)
+	}
+
+	var value string
+	if err := c.Take(ctx, "key", &value); err != nil || value != "value" {
+		t.Errorf("Take = %q, %v", value, err)
+	}
+	if err := c.Take(ctx, "key", &value); errors.StatusCode(err) != http.StatusNotFound {
+		t.Errorf("second Take: err = %v, want 404", err)
+	}
+}
+
+func TestMemoryCacheIncr(t *testing.T) {
+	ctx := context.Background()
+	c := NewMemoryCache()
+
+	for want := 1; want <= 3; want++ {
+		if got := c.Incr(ctx, "counter"); got != want {
+			t.Errorf("Incr = %d, want %d", got, want)
+		}
+	}
+
+	// The expiration set on a counter survives increments.
+	if err := c.Expire(ctx, "counter", time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	c.Incr(ctx, "counter")
+	if ttl, _ := c.TTL(ctx, "counter"); ttl <= 0 {
+		t.Errorf("TTL after Incr = %v", ttl)
+	}
+
+	if err := c.Set(ctx, "text", "value", 0); err != nil {
+		t.Fatal(err)
+	}
+	if got := c.Incr(ctx, "text"); got != 0 {
+		t.Errorf("Incr of a string = %d, want 0", got)
+	}
+}
+
+func TestMemoryCacheSetNX(t *testing.T) {
+	ctx := context.Background()
+	c := NewMemoryCache()
+
+	if ok, err := c.SetNX(ctx, "key", "first", time.Minute); err != nil || !ok {
+		t.Errorf("SetNX of a new key = %v, %v", ok, err)
+	}
+	if ok, err := c.SetNX(ctx, "key", "second", time.Minute); err != nil || ok {
+		t.Errorf("SetNX of an existing key = %v, %v", ok, err)
+	}
+
+	var value string
+	if err := c.Get(ctx, "key", &value); err != nil || value != "first" {
+		t.Errorf("Get = %q, %v, want first", value, err)
+	}
+}
+
+func TestMemoryCacheCompareAndSwap(t *testing.T) {
+	ctx := context.Background()
+	c := NewMemoryCache()
+
+	if ok, err := c.CompareAndSwap(ctx, "key", "a", "b", time.Minute); err != nil || ok {
+		t.Errorf("swap of a missing key = %v, %v", ok, err)
+	}
+
+	if err := c.Set(ctx, "key", "a", time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	if ok, err := c.CompareAndSwap(ctx, "key", "other", "b", time.Minute); err != nil || ok {
+		t.Errorf("swap with a wrong old value = %v, %v", ok, err)
+	}
+	if ok, err := c.CompareAndSwap(ctx, "key", "a", "b", time.Minute); err != nil || !ok {
+		t.Errorf("swap = %v, %v", ok, err)
+	}
+
+	var value string
+	if err := c.Get(ctx, "key", &value); err != nil || value != "b" {
+		t.Errorf("Get = %q, %v, want b", value, err)
+	}
+}
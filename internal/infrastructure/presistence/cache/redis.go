@@ -12,6 +12,16 @@ import (
 	"github.com/redis/go-redis/v9"
 )
 
+// compareAndSwapScript replaces the value stored at KEYS[1] with ARGV[2] only
+// when it currently equals ARGV[1], resetting the expiration to ARGV[3] ms.
+var compareAndSwapScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	redis.call("SET", KEYS[1], ARGV[2], "PX", ARGV[3])
+	return 1
+end
+return 0
+`)
+
 type RedisCache struct {
 	client *redis.Client
 }
@@ -42,6 +52,19 @@ func (c *RedisCache) Get(ctx context.Context, key string, value interface{}) err
 	return err
 }
 
+// Take reads the value stored at key and deletes it in the same operation, so
+// that only one caller can ever obtain it.
+func (c *RedisCache) Take(ctx context.Context, key string, value interface{}) error {
+	values, err := c.client.GetDel(ctx, key).Result()
+	if err != nil {
+		if err == redis.Nil {
+			return errors.ErrNotFound.WithMessage("cache key not found")
+		}
+		return err
+	}
+	return json.Unmarshal([]byte(values), value)
+}
+
 func (c *RedisCache) Incr(ctx context.Context, key string) int {
 	counts, err := c.client.Incr(ctx, key).Result()
 	if err != nil {
@@ -61,6 +84,39 @@ func (c *RedisCache) Set(ctx context.Context, key string, value interface{}, exp
 	return c.client.Set(ctx, key, string(bytes), expiration).Err()
 }
 
+// CompareAndSwap atomically replaces the value of key with newValue when the
+// stored value equals oldValue. It reports whether the swap happened.
+func (c *RedisCache) CompareAndSwap(ctx context.Context, key string, oldValue, newValue interface{}, expiration time.Duration) (bool, error) {
+	oldBytes, err := json.Marshal(oldValue)
+	if err != nil {
+		return false, err
+	}
+	newBytes, err := json.Marshal(newValue)
+	if err != nil {
+		return false, err
+	}
+
+	swapped, err := compareAndSwapScript.Run(ctx, c.client, []string{key}, string(oldBytes), string(newBytes), expiration.Milliseconds()).Int()
+	if err != nil {
+		return false, err
+	}
+	return swapped == 1, nil
+}
+
+// SetNX stores value only when key does not exist yet. It reports whether the
+// value was stored.
+func (c *RedisCache) SetNX(ctx context.Context, key string, value interface{}, expiration time.Duration) (bool, error) {
+	bytes, err := json.Marshal(value)
+	if err != nil {
+		return false, err
+	}
+	return c.client.SetNX(ctx, key, string(bytes), expiration).Result()
+}
+
+func (c *RedisCache) Expire(ctx context.Context, key string, expiration time.Duration) error {
+	return c.client.Expire(ctx, key, expiration).Err()
+}
+
 func (c *RedisCache) Delete(ctx context.Context, keys ...string) error {
 	return c.client.Del(ctx, keys...).Err()
 }
@@ -0,0 +1,178 @@
+package memory
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/HasanNugroho/gin-clean/internal/domain/entity"
+	"github.com/HasanNugroho/gin-clean/internal/domain/repository"
+	"github.com/HasanNugroho/gin-clean/pkg/errors"
+	"github.com/HasanNugroho/gin-clean/pkg/tenant"
+)
+
+type invitationRepository struct {
+	store *Store
+}
+
+func NewInvitationRepository(store *Store) repository.InvitationRepository {
+	return &invitationRepository{
+		store: store,
+	}
+}
+
+// inScope reports whether the invitation belongs to the organization of ctx,
+// if any.
+func inScope(ctx context.Context, invitation entity.Invitation) bool {
+	organizationID, ok := tenant.FromContext(ctx)
+	if !ok {
+		return true
+	}
+	return invitation.OrganizationID != nil && *invitation.OrganizationID == organizationID
+}
+
+func (i *invitationRepository) Create(ctx context.Context, invitation *entity.Invitation) error {
+	i.store.mu.Lock()
+	defer i.store.mu.Unlock()
+
+	row := *invitation
+	if row.ID == "" {
+		row.ID = newID()
+	} else if _, ok := i.store.invitations[row.ID]; ok {
+		return errors.ErrConflict.WithMessage("id already exists (invitations_pkey)")
+	}
+	for _, other := range i.store.invitations {
+		if other.TokenHash == row.TokenHash {
+			return errors.ErrConflict.WithMessage("token_hash already exists (invitations_token_hash_key)")
+		}
+	}
+	if _, ok := i.store.roles[string(row.Role)]; !ok {
+		return errors.ErrUnprocessable.WithMessage("role refers to a record that does not exist (invitations_role_fkey)")
+	}
+	if row.OrganizationID != nil {
+		if _, ok := i.store.organizations[*row.OrganizationID]; !ok {
+			return errors.ErrUnprocessable.WithMessage("organization_id refers to a record that does not exist (invitations_organization_id_fkey)")
+		}
+	}
+	now := time.Now()
+	if row.CreatedAt.IsZero() {
+		row.CreatedAt = now
+	}
+	if row.UpdatedAt.IsZero() {
+		row.UpdatedAt = now
+	}
+
+	put(ctx, i.store.invitations, row.ID, row)
+	*invitation = row
+	return nil
+}
+
+func (i *invitationRepository) GetByID(ctx context.Context, id string) (*entity.Invitation, error) {
+	i.store.mu.RLock()
+	defer i.store.mu.RUnlock()
+
+	invitation, ok := i.store.invitations[id]
+	if !ok || !inScope(ctx, invitation) {
+		return nil, errors.ErrNotFound
+	}
+
+	return &invitation, nil
+}
+
+func (i *invitationRepository) GetByTokenHash(ctx context.Context, tokenHash string) (*entity.Invitation, error) {
+	i.store.mu.RLock()
+	defer i.store.mu.RUnlock()
+
+	for _, invitation := range i.store.invitations {
+		if invitation.TokenHash == tokenHash {
+			return &invitation, nil
+		}
+	}
+
+	return nil, errors.ErrNotFound
+}
+
+func (i *invitationRepository) GetPendingByEmail(ctx context.Context, email string) (*entity.Invitation, error) {
+	i.store.mu.RLock()
+	defer i.store.mu.RUnlock()
+
+	now := time.Now()
+	for _, invitation := range i.store.invitations {
+		if inScope(ctx, invitation) && invitation.IsPending(now) && strings.EqualFold(invitation.Email, email) {
+			return &invitation, nil
+		}
+	}
+
+	return nil, errors.ErrNotFound
+}
+
+func (i *invitationRepository) ListPending(ctx context.Context) ([]entity.Invitation, error) {
+	i.store.mu.RLock()
+	defer i.store.mu.RUnlock()
+
+	now := time.Now()
+	invitations := []entity.Invitation{}
+	for _, invitation := range i.store.invitations {
+		if inScope(ctx, invitation) && invitation.IsPending(now) {
+			invitations = append(invitations, invitation)
+		}
+	}
+	slices.SortFunc(invitations, func(a, b entity.Invitation) int {
+		return b.CreatedAt.Compare(a.CreatedAt)
+	})
+
+	return invitations, nil
+}
+
+func (i *invitationRepository) Renew(ctx context.Context, id string, tokenHash string, expiresAt time.Time) error {
+	i.store.mu.Lock()
+	defer i.store.mu.Unlock()
+
+	// Expired invitations can be renewed, accepted or revoked ones cannot.
+	invitation, ok := i.store.invitations[id]
+	if !ok || !inScope(ctx, invitation) || invitation.AcceptedAt != nil || invitation.RevokedAt != nil {
+		return errors.ErrNotFound
+	}
+	for _, other := range i.store.invitations {
+		if other.ID != id && other.TokenHash == tokenHash {
+			return errors.ErrConflict.WithMessage("token_hash already exists (invitations_token_hash_key)")
+		}
+	}
+
+	invitation.TokenHash = tokenHash
+	invitation.ExpiresAt = expiresAt
+	invitation.UpdatedAt = time.Now()
+	put(ctx, i.store.invitations, id, invitation)
+	return nil
+}
+
+func (i *invitationRepository) MarkAccepted(ctx context.Context, id string, acceptedAt time.Time) (bool, error) {
+	i.store.mu.Lock()
+	defer i.store.mu.Unlock()
+
+	invitation, ok := i.store.invitations[id]
+	if !ok || !invitation.IsPending(acceptedAt) {
+		return false, nil
+	}
+
+	invitation.AcceptedAt = &acceptedAt
+	invitation.UpdatedAt = acceptedAt
+	put(ctx, i.store.invitations, id, invitation)
+	return true, nil
+}
+
+func (i *invitationRepository) Revoke(ctx context.Context, id string, revokedAt time.Time) error {
+	i.store.mu.Lock()
+	defer i.store.mu.Unlock()
+
+	invitation, ok := i.store.invitations[id]
+	if !ok || !inScope(ctx, invitation) || !invitation.IsPending(revokedAt) {
+		return errors.ErrNotFound
+	}
+
+	invitation.RevokedAt = &revokedAt
+	invitation.UpdatedAt = revokedAt
+	put(ctx, i.store.invitations, id, invitation)
+	return nil
+}
@@ -0,0 +1,72 @@
+package memory
+
+import (
+	"cmp"
+	"slices"
+
+	"github.com/HasanNugroho/gin-clean/internal/domain/repository"
+)
+
+// compareCursors orders positions by (created_at, id).
+func compareCursors(a, b repository.Cursor) int {
+	if c := a.CreatedAt.Compare(b.CreatedAt); c != 0 {
+		return c
+	}
+	return cmp.Compare(a.ID, b.ID)
+}
+
+// keysetPaginate returns the page of rows at the position of page, ordered by
+// (created_at, id), along with the positions to continue from, exactly like
+// its counterpart of the postgresql package. position reads the key of a row.
+func keysetPaginate[T any](rows []T, page repository.CursorPage, position func(*T) repository.Cursor) ([]T, repository.CursorResult) {
+	var result repository.CursorResult
+
+	slices.SortFunc(rows, func(a, b T) int {
+		return compareCursors(position(&a), position(&b))
+	})
+
+	var start, end int
+	switch {
+	case page.Before != nil:
+		end, _ = slices.BinarySearchFunc(rows, *page.Before, func(row T, target repository.Cursor) int {
+			return compareCursors(position(&row), target)
+		})
+		start = max(end-page.Limit, 0)
+	case page.After != nil:
+		start, _ = slices.BinarySearchFunc(rows, *page.After, func(row T, target repository.Cursor) int {
+			c := compareCursors(position(&row), target)
+			if c == 0 {
+				// Rows after the cursor only.
+				return -1
+			}
+			return c
+		})
+		end = min(start+page.Limit, len(rows))
+	default:
+		end = min(page.Limit, len(rows))
+	}
+
+	more := end < len(rows)
+	rows = rows[start:end]
+	if len(rows) == 0 {
+		return rows, result
+	}
+
+	first, last := position(&rows[0]), position(&rows[len(rows)-1])
+	switch {
+	case page.Before != nil:
+		result.Next = &last
+		if start > 0 {
+			result.Prev = &first
+		}
+	default:
+		if more {
+			result.Next = &last
+		}
+		if page.After != nil {
+			result.Prev = &first
+		}
+	}
+
+	return rows, result
+}
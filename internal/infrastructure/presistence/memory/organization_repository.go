@@ -0,0 +1,192 @@
+package memory
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"github.com/HasanNugroho/gin-clean/internal/domain/entity"
+	"github.com/HasanNugroho/gin-clean/internal/domain/repository"
+	"github.com/HasanNugroho/gin-clean/pkg/errors"
+)
+
+type organizationRepository struct {
+	store *Store
+}
+
+func NewOrganizationRepository(store *Store) repository.OrganizationRepository {
+	return &organizationRepository{
+		store: store,
+	}
+}
+
+func (o *organizationRepository) Create(ctx context.Context, organization *entity.Organization, owner *entity.Membership) error {
+	o.store.mu.Lock()
+	defer o.store.mu.Unlock()
+
+	row := *organization
+	if row.ID == "" {
+		row.ID = newID()
+	} else if _, ok := o.store.organizations[row.ID]; ok {
+		return errors.ErrConflict.WithMessage("id already exists (organizations_pkey)")
+	}
+	for _, other := range o.store.organizations {
+		if other.Slug == row.Slug {
+			return errors.ErrConflict.WithMessage("slug already exists (organizations_slug_key)")
+		}
+	}
+	now := time.Now()
+	if row.CreatedAt.IsZero() {
+		row.CreatedAt = now
+	}
+	if row.UpdatedAt.IsZero() {
+		row.UpdatedAt = now
+	}
+
+	membership := *owner
+	membership.OrganizationID = row.ID
+	if err := o.checkMembership(&membership); err != nil {
+		return err
+	}
+
+	put(ctx, o.store.organizations, row.ID, row)
+	put(ctx, o.store.memberships, membershipKey{organizationID: row.ID, userID: membership.UserID}, membership)
+
+	*organization = row
+	*owner = membership
+	return nil
+}
+
+// checkMembership enforces the constraints of the memberships table on a row
+// about to be inserted, the organization aside, and fills in its creation
+// time. The caller holds mu.
+func (o *organizationRepository) checkMembership(membership *entity.Membership) error {
+	if _, ok := o.store.users[membership.UserID]; !ok {
+		return errors.ErrUnprocessable.WithMessage("user_id refers to a record that does not exist (memberships_user_id_fkey)")
+	}
+	if _, ok := o.store.roles[string(membership.Role)]; !ok {
+		return errors.ErrUnprocessable.WithMessage("role refers to a record that does not exist (memberships_role_fkey)")
+	}
+	if membership.CreatedAt.IsZero() {
+		membership.CreatedAt = time.Now()
+	}
+	membership.Organization, membership.User = nil, nil
+	return nil
+}
+
+func (o *organizationRepository) GetByID(ctx context.Context, id string) (*entity.Organization, error) {
+	o.store.mu.RLock()
+	defer o.store.mu.RUnlock()
+
+	organization, ok := o.store.organizations[id]
+	if !ok {
+		return nil, errors.ErrNotFound
+	}
+
+	return &organization, nil
+}
+
+func (o *organizationRepository) GetBySlug(ctx context.Context, slug string) (*entity.Organization, error) {
+	o.store.mu.RLock()
+	defer o.store.mu.RUnlock()
+
+	for _, organization := range o.store.organizations {
+		if organization.Slug == slug {
+			return &organization, nil
+		}
+	}
+
+	return nil, errors.ErrNotFound
+}
+
+func (o *organizationRepository) GetMembership(ctx context.Context, organizationID, userID string) (*entity.Membership, error) {
+	o.store.mu.RLock()
+	defer o.store.mu.RUnlock()
+
+	membership, ok := o.store.memberships[membershipKey{organizationID: organizationID, userID: userID}]
+	if !ok {
+		return nil, errors.ErrNotFound
+	}
+
+	return &membership, nil
+}
+
+func sortMemberships(memberships []entity.Membership) {
+	slices.SortFunc(memberships, func(a, b entity.Membership) int {
+		return a.CreatedAt.Compare(b.CreatedAt)
+	})
+}
+
+func (o *organizationRepository) ListMemberships(ctx context.Context, userID string) ([]entity.Membership, error) {
+	o.store.mu.RLock()
+	defer o.store.mu.RUnlock()
+
+	var memberships []entity.Membership
+	for key, membership := range o.store.memberships {
+		if key.userID != userID {
+			continue
+		}
+		organization := o.store.organizations[key.organizationID]
+		membership.Organization = &organization
+		memberships = append(memberships, membership)
+	}
+	sortMemberships(memberships)
+
+	return memberships, nil
+}
+
+func (o *organizationRepository) ListMembers(ctx context.Context, organizationID string) ([]entity.Membership, error) {
+	o.store.mu.RLock()
+	defer o.store.mu.RUnlock()
+
+	var memberships []entity.Membership
+	for key, membership := range o.store.memberships {
+		if key.organizationID != organizationID {
+			continue
+		}
+		user, ok := o.store.users[key.userID]
+		if !ok || user.DeletedAt.Valid {
+			continue
+		}
+		membership.User = &user
+		memberships = append(memberships, membership)
+	}
+	sortMemberships(memberships)
+
+	return memberships, nil
+}
+
+func (o *organizationRepository) AddMember(ctx context.Context, membership *entity.Membership) error {
+	o.store.mu.Lock()
+	defer o.store.mu.Unlock()
+
+	key := membershipKey{organizationID: membership.OrganizationID, userID: membership.UserID}
+	if _, ok := o.store.memberships[key]; ok {
+		return errors.ErrConflict.WithMessage("organization_id, user_id already exists (memberships_pkey)")
+	}
+	if _, ok := o.store.organizations[membership.OrganizationID]; !ok {
+		return errors.ErrUnprocessable.WithMessage("organization_id refers to a record that does not exist (memberships_organization_id_fkey)")
+	}
+
+	row := *membership
+	if err := o.checkMembership(&row); err != nil {
+		return err
+	}
+
+	put(ctx, o.store.memberships, key, row)
+	membership.CreatedAt = row.CreatedAt
+	return nil
+}
+
+func (o *organizationRepository) RemoveMember(ctx context.Context, organizationID, userID string) error {
+	o.store.mu.Lock()
+	defer o.store.mu.Unlock()
+
+	key := membershipKey{organizationID: organizationID, userID: userID}
+	if _, ok := o.store.memberships[key]; !ok {
+		return errors.ErrNotFound
+	}
+
+	remove(ctx, o.store.memberships, key)
+	return nil
+}
@@ -0,0 +1,90 @@
+package memory
+
+import (
+	"context"
+	"time"
+
+	"github.com/HasanNugroho/gin-clean/internal/domain/entity"
+	"github.com/HasanNugroho/gin-clean/internal/domain/repository"
+	"github.com/HasanNugroho/gin-clean/pkg/errors"
+)
+
+type recoveryCodeRepository struct {
+	store *Store
+}
+
+func NewRecoveryCodeRepository(store *Store) repository.RecoveryCodeRepository {
+	return &recoveryCodeRepository{
+		store: store,
+	}
+}
+
+func (r *recoveryCodeRepository) ListUnusedByUser(ctx context.Context, userID string) ([]entity.RecoveryCode, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	var codes []entity.RecoveryCode
+	for _, code := range r.store.recoveryCodes {
+		if code.UserID == userID && code.UsedAt == nil {
+			codes = append(codes, code)
+		}
+	}
+
+	return codes, nil
+}
+
+func (r *recoveryCodeRepository) Replace(ctx context.Context, userID string, codes []entity.RecoveryCode) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if len(codes) > 0 {
+		if _, ok := r.store.users[userID]; !ok {
+			return errors.ErrUnprocessable.WithMessage("user_id refers to a record that does not exist (mfa_recovery_codes_user_id_fkey)")
+		}
+	}
+
+	r.deleteByUser(ctx, userID)
+
+	now := time.Now()
+	for i := range codes {
+		if codes[i].ID == "" {
+			codes[i].ID = newID()
+		}
+		if codes[i].CreatedAt.IsZero() {
+			codes[i].CreatedAt = now
+		}
+		put(ctx, r.store.recoveryCodes, codes[i].ID, codes[i])
+	}
+	return nil
+}
+
+func (r *recoveryCodeRepository) MarkUsed(ctx context.Context, id string, usedAt time.Time) (bool, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	code, ok := r.store.recoveryCodes[id]
+	if !ok || code.UsedAt != nil {
+		return false, nil
+	}
+
+	code.UsedAt = &usedAt
+	put(ctx, r.store.recoveryCodes, id, code)
+	return true, nil
+}
+
+// deleteByUser is DeleteByUser for a caller that holds mu.
+func (r *recoveryCodeRepository) deleteByUser(ctx context.Context, userID string) {
+	for id, code := range r.store.recoveryCodes {
+		if code.UserID == userID {
+			remove(ctx, r.store.recoveryCodes, id)
+		}
+	}
+}
+
+func (r *recoveryCodeRepository) DeleteByUser(ctx context.Context, userID string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	r.deleteByUser(ctx, userID)
+	return nil
+}
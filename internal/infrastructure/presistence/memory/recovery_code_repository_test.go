@@ -0,0 +1,59 @@
+package memory
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/HasanNugroho/gin-clean/internal/domain/entity"
+	"github.com/HasanNugroho/gin-clean/pkg/errors"
+)
+
+func TestRecoveryCodeRepository(t *testing.T) {
+	ctx := context.Background()
+	store := NewStore()
+	codes := NewRecoveryCodeRepository(store)
+	user := createUser(t, NewUserRepository(store), ctx, "Jane", "jane@example.com")
+
+	if err := codes.Replace(ctx, user.ID, []entity.RecoveryCode{
+		{UserID: user.ID, CipherText: "a"},
+		{UserID: user.ID, CipherText: "b"},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	unused, err := codes.ListUnusedByUser(ctx, user.ID)
+	if err != nil || len(unused) != 2 {
+		t.Fatalf("ListUnusedByUser = %v, %v", unused, err)
+	}
+
+	// A code is used once only.
+	if ok, err := codes.MarkUsed(ctx, unused[0].ID, time.Now()); err != nil || !ok {
+		t.Errorf("MarkUsed = %v, %v", ok, err)
+	}
+	if ok, err := codes.MarkUsed(ctx, unused[0].ID, time.Now()); err != nil || ok {
+		t.Errorf("MarkUsed of a used code = %v, %v", ok, err)
+	}
+	if unused, _ := codes.ListUnusedByUser(ctx, user.ID); len(unused) != 1 {
+		t.Errorf("%d unused codes, want 1", len(unused))
+	}
+
+	// Replacing drops the previous set, used codes included.
+	if err := codes.Replace(ctx, user.ID, []entity.RecoveryCode{{UserID: user.ID, CipherText: "c"}}); err != nil {
+		t.Fatal(err)
+	}
+	if len(store.recoveryCodes) != 1 {
+		t.Errorf("%d codes stored, want 1", len(store.recoveryCodes))
+	}
+
+	if err := codes.DeleteByUser(ctx, user.ID); err != nil {
+		t.Fatal(err)
+	}
+	if unused, _ := codes.ListUnusedByUser(ctx, user.ID); len(unused) != 0 {
+		t.Errorf("%d codes left after DeleteByUser", len(unused))
+	}
+
+	if err := codes.Replace(ctx, newID(), []entity.RecoveryCode{{CipherText: "d"}}); errors.StatusCode(err) != http.StatusUnprocessableEntity {
+		t.Errorf("codes of an unknown user: err = %v, want 422", err)
+	}
+}
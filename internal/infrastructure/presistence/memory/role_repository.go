@@ -0,0 +1,181 @@
+package memory
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"time"
+
+	"github.com/HasanNugroho/gin-clean/internal/domain/entity"
+	"github.com/HasanNugroho/gin-clean/internal/domain/repository"
+	"github.com/HasanNugroho/gin-clean/pkg/errors"
+)
+
+type roleRepository struct {
+	store *Store
+}
+
+func NewRoleRepository(store *Store) repository.RoleRepository {
+	return &roleRepository{
+		store: store,
+	}
+}
+
+// permissionsOf returns the sorted permissions of a role. The caller holds mu.
+func (r *roleRepository) permissionsOf(role string) []string {
+	permissions := []string{}
+	for key := range r.store.rolePermissions {
+		if key.role == role {
+			permissions = append(permissions, key.permission)
+		}
+	}
+	slices.Sort(permissions)
+	return permissions
+}
+
+func (r *roleRepository) List(ctx context.Context) ([]entity.Role, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	roles := make([]entity.Role, 0, len(r.store.roles))
+	for _, role := range r.store.roles {
+		role.Permissions = r.permissionsOf(role.Name)
+		roles = append(roles, role)
+	}
+	slices.SortFunc(roles, func(a, b entity.Role) int {
+		return cmp.Compare(a.Name, b.Name)
+	})
+
+	return roles, nil
+}
+
+func (r *roleRepository) GetByName(ctx context.Context, name string) (*entity.Role, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	role, ok := r.store.roles[name]
+	if !ok {
+		return nil, errors.ErrNotFound
+	}
+	role.Permissions = r.permissionsOf(name)
+
+	return &role, nil
+}
+
+func (r *roleRepository) Create(ctx context.Context, role *entity.Role) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.roles[role.Name]; ok {
+		return errors.ErrConflict.WithMessage("name already exists (roles_pkey)")
+	}
+	if role.CreatedAt.IsZero() {
+		role.CreatedAt = time.Now()
+	}
+
+	row := *role
+	row.Permissions = nil
+	put(ctx, r.store.roles, row.Name, row)
+	return nil
+}
+
+func (r *roleRepository) Delete(ctx context.Context, name string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.roles[name]; !ok {
+		return errors.ErrNotFound
+	}
+	if r.inUse(name) {
+		return errors.ErrConflict.WithMessage("name is still referenced (fk_users_role)")
+	}
+	for _, invitation := range r.store.invitations {
+		if string(invitation.Role) == name {
+			return errors.ErrConflict.WithMessage("name is still referenced (invitations_role_fkey)")
+		}
+	}
+
+	remove(ctx, r.store.roles, name)
+	for key := range r.store.rolePermissions {
+		if key.role == name {
+			remove(ctx, r.store.rolePermissions, key)
+		}
+	}
+	return nil
+}
+
+// inUse is InUse for a caller that holds mu.
+func (r *roleRepository) inUse(name string) bool {
+	for _, user := range r.store.users {
+		if string(user.Role) == name {
+			return true
+		}
+	}
+	for _, membership := range r.store.memberships {
+		if string(membership.Role) == name {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *roleRepository) InUse(ctx context.Context, name string) (bool, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	return r.inUse(name), nil
+}
+
+func (r *roleRepository) ListPermissions(ctx context.Context) ([]entity.Permission, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	permissions := make([]entity.Permission, 0, len(r.store.permissions))
+	for _, permission := range r.store.permissions {
+		permissions = append(permissions, permission)
+	}
+	slices.SortFunc(permissions, func(a, b entity.Permission) int {
+		return cmp.Compare(a.Name, b.Name)
+	})
+
+	return permissions, nil
+}
+
+func (r *roleRepository) PermissionsOf(ctx context.Context, role string) ([]string, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	return r.permissionsOf(role), nil
+}
+
+func (r *roleRepository) Grant(ctx context.Context, role string, permissions []string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.roles[role]; !ok {
+		return errors.ErrUnprocessable.WithMessage("role_name refers to a record that does not exist (role_permissions_role_name_fkey)")
+	}
+	for _, permission := range permissions {
+		if _, ok := r.store.permissions[permission]; !ok {
+			return errors.ErrUnprocessable.WithMessage("permission_name refers to a record that does not exist (role_permissions_permission_name_fkey)")
+		}
+	}
+
+	for _, permission := range permissions {
+		put(ctx, r.store.rolePermissions, rolePermissionKey{role: role, permission: permission}, struct{}{})
+	}
+	return nil
+}
+
+func (r *roleRepository) Revoke(ctx context.Context, role string, permission string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	key := rolePermissionKey{role: role, permission: permission}
+	if _, ok := r.store.rolePermissions[key]; !ok {
+		return errors.ErrNotFound
+	}
+
+	remove(ctx, r.store.rolePermissions, key)
+	return nil
+}
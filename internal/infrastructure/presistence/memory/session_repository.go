@@ -0,0 +1,115 @@
+package memory
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"github.com/HasanNugroho/gin-clean/internal/domain/entity"
+	"github.com/HasanNugroho/gin-clean/internal/domain/repository"
+	"github.com/HasanNugroho/gin-clean/pkg/errors"
+)
+
+type sessionRepository struct {
+	store *Store
+}
+
+func NewSessionRepository(store *Store) repository.SessionRepository {
+	return &sessionRepository{
+		store: store,
+	}
+}
+
+func (s *sessionRepository) Create(ctx context.Context, session *entity.Session) error {
+	s.store.mu.Lock()
+	defer s.store.mu.Unlock()
+
+	if session.ID == "" {
+		session.ID = newID()
+	} else if _, ok := s.store.sessions[session.ID]; ok {
+		return errors.ErrConflict.WithMessage("id already exists (sessions_pkey)")
+	}
+	if _, ok := s.store.users[session.UserID]; !ok {
+		return errors.ErrUnprocessable.WithMessage("user_id refers to a record that does not exist (sessions_user_id_fkey)")
+	}
+	if session.CreatedAt.IsZero() {
+		session.CreatedAt = time.Now()
+	}
+
+	put(ctx, s.store.sessions, session.ID, *session)
+	return nil
+}
+
+func (s *sessionRepository) GetByID(ctx context.Context, id string) (*entity.Session, error) {
+	s.store.mu.RLock()
+	defer s.store.mu.RUnlock()
+
+	session, ok := s.store.sessions[id]
+	if !ok {
+		return nil, errors.ErrNotFound
+	}
+
+	return &session, nil
+}
+
+func (s *sessionRepository) ListActiveByUser(ctx context.Context, userID string) ([]entity.Session, error) {
+	s.store.mu.RLock()
+	defer s.store.mu.RUnlock()
+
+	now := time.Now()
+	var sessions []entity.Session
+	for _, session := range s.store.sessions {
+		if session.UserID == userID && session.IsActive(now) {
+			sessions = append(sessions, session)
+		}
+	}
+	slices.SortFunc(sessions, func(a, b entity.Session) int {
+		return b.LastSeenAt.Compare(a.LastSeenAt)
+	})
+
+	return sessions, nil
+}
+
+func (s *sessionRepository) Touch(ctx context.Context, session *entity.Session) error {
+	s.store.mu.Lock()
+	defer s.store.mu.Unlock()
+
+	stored, ok := s.store.sessions[session.ID]
+	if !ok {
+		return nil
+	}
+
+	stored.IPAddress = session.IPAddress
+	stored.UserAgent = session.UserAgent
+	stored.LastSeenAt = session.LastSeenAt
+	stored.ExpiresAt = session.ExpiresAt
+	put(ctx, s.store.sessions, session.ID, stored)
+	return nil
+}
+
+func (s *sessionRepository) Revoke(ctx context.Context, id string, revokedAt time.Time) error {
+	s.store.mu.Lock()
+	defer s.store.mu.Unlock()
+
+	session, ok := s.store.sessions[id]
+	if !ok || session.RevokedAt != nil {
+		return nil
+	}
+
+	session.RevokedAt = &revokedAt
+	put(ctx, s.store.sessions, id, session)
+	return nil
+}
+
+func (s *sessionRepository) RevokeAllByUser(ctx context.Context, userID string, revokedAt time.Time) error {
+	s.store.mu.Lock()
+	defer s.store.mu.Unlock()
+
+	for id, session := range s.store.sessions {
+		if session.UserID == userID && session.RevokedAt == nil {
+			session.RevokedAt = &revokedAt
+			put(ctx, s.store.sessions, id, session)
+		}
+	}
+	return nil
+}
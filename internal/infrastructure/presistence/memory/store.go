@@ -0,0 +1,126 @@
+// Package memory implements the repositories in the memory of the process,
+// for tests that exercise handlers and services without PostgreSQL. They
+// mirror the behavior of the postgresql package, constraints included, but
+// keep nothing across restarts.
+package memory
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/HasanNugroho/gin-clean/internal/domain/entity"
+	"github.com/HasanNugroho/gin-clean/pkg/constants"
+)
+
+type membershipKey struct {
+	organizationID string
+	userID         string
+}
+
+type rolePermissionKey struct {
+	role       string
+	permission string
+}
+
+// tables holds the rows of every repository, keyed by primary key.
+type tables struct {
+	users           map[string]entity.User
+	sessions        map[string]entity.Session
+	roles           map[string]entity.Role
+	permissions     map[string]entity.Permission
+	rolePermissions map[rolePermissionKey]struct{}
+	organizations   map[string]entity.Organization
+	memberships     map[membershipKey]entity.Membership
+	recoveryCodes   map[string]entity.RecoveryCode
+	invitations     map[string]entity.Invitation
+}
+
+// Store is the database shared by the repositories of this package, so that
+// one sees the rows written by another as it would in PostgreSQL.
+type Store struct {
+	mu sync.RWMutex
+	// txMu serializes transactions, see txManager.
+	txMu sync.Mutex
+	tables
+}
+
+// NewStore returns a store seeded with the roles and permissions created by
+// the migrations.
+func NewStore() *Store {
+	s := &Store{tables: tables{
+		users:           map[string]entity.User{},
+		sessions:        map[string]entity.Session{},
+		roles:           map[string]entity.Role{},
+		permissions:     map[string]entity.Permission{},
+		rolePermissions: map[rolePermissionKey]struct{}{},
+		organizations:   map[string]entity.Organization{},
+		memberships:     map[membershipKey]entity.Membership{},
+		recoveryCodes:   map[string]entity.RecoveryCode{},
+		invitations:     map[string]entity.Invitation{},
+	}}
+
+	now := time.Now()
+	s.roles[string(constants.ROLE_ADMIN)] = entity.Role{Name: string(constants.ROLE_ADMIN), Description: "Administrator", CreatedAt: now}
+	s.roles[string(constants.ROLE_USER)] = entity.Role{Name: string(constants.ROLE_USER), Description: "Regular user", CreatedAt: now}
+
+	for _, permission := range []entity.Permission{
+		{Name: constants.PERMISSION_USERS_CREATE, Description: "Create users"},
+		{Name: constants.PERMISSION_USERS_READ, Description: "View any user"},
+		{Name: constants.PERMISSION_USERS_UPDATE, Description: "Update any user"},
+		{Name: constants.PERMISSION_USERS_DELETE, Description: "Delete any user"},
+		{Name: constants.PERMISSION_USERS_RESTORE, Description: "List and restore deleted users"},
+		{Name: constants.PERMISSION_USERS_PURGE, Description: "Permanently delete deleted users"},
+		{Name: constants.PERMISSION_ROLES_READ, Description: "View roles and permissions"},
+		{Name: constants.PERMISSION_ROLES_MANAGE, Description: "Create and delete roles and change their permissions"},
+		{Name: constants.PERMISSION_ORGANIZATIONS_CREATE, Description: "Create organizations"},
+		{Name: constants.PERMISSION_ORGANIZATIONS_MANAGE, Description: "Add and remove members of the current organization"},
+		{Name: constants.PERMISSION_INVITATIONS_MANAGE, Description: "Invite users and manage pending invitations"},
+	} {
+		s.permissions[permission.Name] = permission
+		s.rolePermissions[rolePermissionKey{role: string(constants.ROLE_ADMIN), permission: permission.Name}] = struct{}{}
+	}
+
+	return s
+}
+
+// purgeUser deletes the user and the rows that reference it, like the
+// foreign keys of the users table do. The caller holds mu.
+func (s *Store) purgeUser(ctx context.Context, id string) {
+	remove(ctx, s.users, id)
+	for key, session := range s.sessions {
+		if session.UserID == id {
+			remove(ctx, s.sessions, key)
+		}
+	}
+	for key, code := range s.recoveryCodes {
+		if code.UserID == id {
+			remove(ctx, s.recoveryCodes, key)
+		}
+	}
+	for key := range s.memberships {
+		if key.userID == id {
+			remove(ctx, s.memberships, key)
+		}
+	}
+	for key, invitation := range s.invitations {
+		if invitation.InvitedBy != nil && *invitation.InvitedBy == id {
+			invitation.InvitedBy = nil
+			put(ctx, s.invitations, key, invitation)
+		}
+	}
+}
+
+// newID returns a random version 4 UUID, like the uuid_generate_v4() default
+// of the tables.
+func newID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
@@ -0,0 +1,81 @@
+package memory
+
+import (
+	"context"
+
+	"github.com/HasanNugroho/gin-clean/internal/domain/repository"
+)
+
+type txKey struct{}
+
+// journal records how to undo the writes of a transaction, in the order they
+// were made.
+type journal struct {
+	undo []func()
+}
+
+type txManager struct {
+	store *Store
+}
+
+func NewTxManager(store *Store) repository.TxManager {
+	return &txManager{
+		store: store,
+	}
+}
+
+// WithinTransaction rolls back by undoing the writes journaled by the
+// repositories called with the context of the transaction, so writes made
+// outside of it meanwhile are kept. Transactions run one at a time.
+func (t *txManager) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	if _, ok := ctx.Value(txKey{}).(*journal); ok {
+		return fn(ctx)
+	}
+
+	t.store.txMu.Lock()
+	defer t.store.txMu.Unlock()
+
+	tx := &journal{}
+	if err := fn(context.WithValue(ctx, txKey{}, tx)); err != nil {
+		t.store.mu.Lock()
+		for i := len(tx.undo) - 1; i >= 0; i-- {
+			tx.undo[i]()
+		}
+		t.store.mu.Unlock()
+		return err
+	}
+
+	return nil
+}
+
+// put sets the row of key, journaling the previous state of the row within a
+// transaction. The caller holds mu.
+func put[K comparable, V any](ctx context.Context, rows map[K]V, key K, row V) {
+	record(ctx, rows, key)
+	rows[key] = row
+}
+
+// remove deletes the row of key, journaling it within a transaction. The
+// caller holds mu.
+func remove[K comparable, V any](ctx context.Context, rows map[K]V, key K) {
+	record(ctx, rows, key)
+	delete(rows, key)
+}
+
+// record adds the undoing of a write to the row of key to the journal of the
+// transaction of ctx, if any.
+func record[K comparable, V any](ctx context.Context, rows map[K]V, key K) {
+	tx, ok := ctx.Value(txKey{}).(*journal)
+	if !ok {
+		return
+	}
+
+	previous, existed := rows[key]
+	tx.undo = append(tx.undo, func() {
+		if existed {
+			rows[key] = previous
+		} else {
+			delete(rows, key)
+		}
+	})
+}
@@ -0,0 +1,83 @@
+package memory
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/HasanNugroho/gin-clean/pkg/errors"
+)
+
+func TestTransactionCommits(t *testing.T) {
+	store := NewStore()
+	users := NewUserRepository(store)
+
+	err := NewTxManager(store).WithinTransaction(context.Background(), func(ctx context.Context) error {
+		createUser(t, users, ctx, "Jane", "jane@example.com")
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := users.GetByEmail(context.Background(), "jane@example.com"); err != nil {
+		t.Errorf("committed user: %v", err)
+	}
+}
+
+func TestTransactionRollsBack(t *testing.T) {
+	store := NewStore()
+	users := NewUserRepository(store)
+	jane := createUser(t, users, context.Background(), "Jane", "jane@example.com")
+
+	failure := errors.ErrBadRequest.WithMessage("failure")
+	err := NewTxManager(store).WithinTransaction(context.Background(), func(ctx context.Context) error {
+		createUser(t, users, ctx, "John", "john@example.com")
+
+		jane.Name = "Janet"
+		if err := users.Update(ctx, jane); err != nil {
+			t.Fatal(err)
+		}
+
+		// Writes made outside of the transaction are kept.
+		createUser(t, users, context.Background(), "Ann", "ann@example.com")
+		return failure
+	})
+	if err != failure {
+		t.Fatalf("err = %v, want the error of the function", err)
+	}
+
+	if _, err := users.GetByEmail(context.Background(), "john@example.com"); errors.StatusCode(err) != http.StatusNotFound {
+		t.Errorf("user created in the transaction: err = %v, want 404", err)
+	}
+	got, err := users.GetByID(context.Background(), jane.ID)
+	if err != nil || got.Name != "Jane" || got.Version != 1 {
+		t.Errorf("user updated in the transaction = %+v, %v", got, err)
+	}
+	if _, err := users.GetByEmail(context.Background(), "ann@example.com"); err != nil {
+		t.Errorf("user created outside of the transaction: %v", err)
+	}
+}
+
+func TestNestedTransactionJoinsOuter(t *testing.T) {
+	store := NewStore()
+	users := NewUserRepository(store)
+	tx := NewTxManager(store)
+
+	failure := errors.ErrBadRequest.WithMessage("failure")
+	err := tx.WithinTransaction(context.Background(), func(ctx context.Context) error {
+		if err := tx.WithinTransaction(ctx, func(ctx context.Context) error {
+			createUser(t, users, ctx, "Jane", "jane@example.com")
+			return nil
+		}); err != nil {
+			t.Fatal(err)
+		}
+		return failure
+	})
+	if err != failure {
+		t.Fatalf("err = %v, want the error of the function", err)
+	}
+
+	if _, err := users.GetByEmail(context.Background(), "jane@example.com"); errors.StatusCode(err) != http.StatusNotFound {
+		t.Errorf("user of the inner transaction: err = %v, want 404", err)
+	}
+}
@@ -0,0 +1,481 @@
+package memory
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/HasanNugroho/gin-clean/internal/domain/entity"
+	"github.com/HasanNugroho/gin-clean/internal/domain/repository"
+	"github.com/HasanNugroho/gin-clean/pkg/constants"
+	"github.com/HasanNugroho/gin-clean/pkg/errors"
+	"github.com/HasanNugroho/gin-clean/pkg/tenant"
+	"gorm.io/gorm"
+)
+
+type userRepository struct {
+	store *Store
+}
+
+func NewUserRepository(store *Store) repository.UserRepository {
+	return &userRepository{
+		store: store,
+	}
+}
+
+// visible reports whether the user is a live user of the organization of
+// ctx, if any. The caller holds mu.
+func (u *userRepository) visible(ctx context.Context, user entity.User) bool {
+	if user.DeletedAt.Valid {
+		return false
+	}
+	organizationID, ok := tenant.FromContext(ctx)
+	if !ok {
+		return true
+	}
+	_, ok = u.store.memberships[membershipKey{organizationID: organizationID, userID: user.ID}]
+	return ok
+}
+
+// withTenantRole replaces the global role of a user loaded within an
+// organization by their role in that organization. The caller holds mu.
+func (u *userRepository) withTenantRole(ctx context.Context, user entity.User) entity.User {
+	if organizationID, ok := tenant.FromContext(ctx); ok {
+		user.Role = u.store.memberships[membershipKey{organizationID: organizationID, userID: user.ID}].Role
+	}
+	return user
+}
+
+// checkUser enforces the constraints of the users table on a row about to be
+// written. The caller holds mu.
+func (u *userRepository) checkUser(user *entity.User) error {
+	for _, other := range u.store.users {
+		if other.ID != user.ID && !other.DeletedAt.Valid && other.Email == user.Email {
+			return errors.ErrConflict.WithMessage("email already exists (idx_users_email_active)")
+		}
+	}
+	if _, ok := u.store.roles[string(user.Role)]; !ok {
+		return errors.ErrUnprocessable.WithMessage("role refers to a record that does not exist (fk_users_role)")
+	}
+	return nil
+}
+
+// Create stores the user. Within an organization the user also becomes a
+// member of it: the requested role is granted as the membership role while
+// the global role stays the default one.
+func (u *userRepository) Create(ctx context.Context, user *entity.User) error {
+	u.store.mu.Lock()
+	defer u.store.mu.Unlock()
+
+	row := *user
+	if row.ID == "" {
+		row.ID = newID()
+	} else if _, ok := u.store.users[row.ID]; ok {
+		return errors.ErrConflict.WithMessage("id already exists (users_pkey)")
+	}
+	if row.Role == "" {
+		row.Role = constants.ROLE_USER
+	}
+	if row.Version == 0 {
+		row.Version = 1
+	}
+	now := time.Now()
+	if row.CreatedAt.IsZero() {
+		row.CreatedAt = now
+	}
+	if row.UpdatedAt.IsZero() {
+		row.UpdatedAt = now
+	}
+
+	organizationID, scoped := tenant.FromContext(ctx)
+	role := row.Role
+	if scoped {
+		if _, ok := u.store.organizations[organizationID]; !ok {
+			return errors.ErrUnprocessable.WithMessage("organization_id refers to a record that does not exist (memberships_organization_id_fkey)")
+		}
+		row.Role = constants.ROLE_USER
+		if _, ok := u.store.roles[string(role)]; !ok {
+			return errors.ErrUnprocessable.WithMessage("role refers to a record that does not exist (memberships_role_fkey)")
+		}
+	}
+	if err := u.checkUser(&row); err != nil {
+		return err
+	}
+
+	put(ctx, u.store.users, row.ID, row)
+	if scoped {
+		put(ctx, u.store.memberships, membershipKey{organizationID: organizationID, userID: row.ID}, entity.Membership{
+			OrganizationID: organizationID,
+			UserID:         row.ID,
+			Role:           role,
+			CreatedAt:      now,
+		})
+	}
+
+	row.Role = role
+	*user = row
+	return nil
+}
+
+func (u *userRepository) GetByID(ctx context.Context, id string) (*entity.User, error) {
+	u.store.mu.RLock()
+	defer u.store.mu.RUnlock()
+
+	user, ok := u.store.users[id]
+	if !ok || !u.visible(ctx, user) {
+		return nil, errors.ErrNotFound
+	}
+
+	user = u.withTenantRole(ctx, user)
+	return &user, nil
+}
+
+func (u *userRepository) GetByEmail(ctx context.Context, email string) (*entity.User, error) {
+	u.store.mu.RLock()
+	defer u.store.mu.RUnlock()
+
+	for _, user := range u.store.users {
+		if user.Email == email && u.visible(ctx, user) {
+			user = u.withTenantRole(ctx, user)
+			return &user, nil
+		}
+	}
+
+	return nil, errors.ErrNotFound
+}
+
+// filterUsers returns the users matching the conditions of a listing, with
+// their tenant role, in no particular order. The caller holds mu.
+func (u *userRepository) filterUsers(ctx context.Context, filter repository.UserFilter) []entity.User {
+	search := strings.ToLower(filter.Search)
+
+	users := []entity.User{}
+	for _, user := range u.store.users {
+		if !u.visible(ctx, user) {
+			continue
+		}
+		user = u.withTenantRole(ctx, user)
+
+		// Within an organization users are listed by their membership role.
+		if filter.Role != "" && string(user.Role) != filter.Role {
+			continue
+		}
+		if filter.IsActive != nil && user.IsActive != *filter.IsActive {
+			continue
+		}
+		if filter.CreatedFrom != nil && user.CreatedAt.Before(*filter.CreatedFrom) {
+			continue
+		}
+		if filter.CreatedTo != nil && user.CreatedAt.After(*filter.CreatedTo) {
+			continue
+		}
+		if search != "" && !strings.Contains(strings.ToLower(user.Name), search) && !strings.Contains(strings.ToLower(user.Email), search) {
+			continue
+		}
+		users = append(users, user)
+	}
+	return users
+}
+
+// userSortKeys are the fields a user listing can be ordered by.
+var userSortKeys = map[string]func(a, b *entity.User) int{
+	"name":       func(a, b *entity.User) int { return cmp.Compare(a.Name, b.Name) },
+	"email":      func(a, b *entity.User) int { return cmp.Compare(a.Email, b.Email) },
+	"created_at": func(a, b *entity.User) int { return a.CreatedAt.Compare(b.CreatedAt) },
+	"updated_at": func(a, b *entity.User) int { return a.UpdatedAt.Compare(b.UpdatedAt) },
+}
+
+// paginate returns the rows of the page at offset. A negative limit returns
+// every remaining row.
+func paginate[T any](rows []T, offset, limit int) []T {
+	if offset > len(rows) {
+		offset = len(rows)
+	}
+	rows = rows[max(offset, 0):]
+	if limit >= 0 && limit < len(rows) {
+		rows = rows[:limit]
+	}
+	return rows
+}
+
+func (u *userRepository) List(ctx context.Context, filter repository.UserFilter) ([]entity.User, int64, error) {
+	u.store.mu.RLock()
+	users := u.filterUsers(ctx, filter)
+	u.store.mu.RUnlock()
+
+	column, descending := strings.TrimPrefix(filter.Sort, "-"), strings.HasPrefix(filter.Sort, "-")
+	compare, ok := userSortKeys[column]
+	if !ok {
+		compare, descending = userSortKeys["created_at"], true
+	}
+
+	slices.SortFunc(users, func(a, b entity.User) int {
+		c := compare(&a, &b)
+		if descending {
+			c = -c
+		}
+		if c != 0 {
+			return c
+		}
+		return cmp.Compare(a.ID, b.ID)
+	})
+
+	return paginate(users, filter.Offset, filter.Limit), int64(len(users)), nil
+}
+
+// ListByCursor returns the page of users matching the filter at the position
+// of page, in order of creation. Sort and Offset of the filter are ignored.
+func (u *userRepository) ListByCursor(ctx context.Context, filter repository.UserFilter, page repository.CursorPage) ([]entity.User, repository.CursorResult, error) {
+	u.store.mu.RLock()
+	users := u.filterUsers(ctx, filter)
+	u.store.mu.RUnlock()
+
+	users, result := keysetPaginate(users, page, func(user *entity.User) repository.Cursor {
+		return repository.Cursor{CreatedAt: user.CreatedAt, ID: user.ID}
+	})
+	return users, result, nil
+}
+
+// searchWords splits text into lowercase words the way the search of the
+// postgresql package does.
+func searchWords(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// userSearchWeights are the weights of the fields of a user in a search, as
+// given to them by the search_vector column and ranked by ts_rank.
+var userSearchWeights = []struct {
+	field  func(*entity.User) string
+	weight float64
+}{
+	{field: func(u *entity.User) string { return u.Name }, weight: 1.0},
+	{field: func(u *entity.User) string { return u.Email }, weight: 0.4},
+	{field: func(u *entity.User) string { return u.PhoneNumber }, weight: 0.2},
+}
+
+// Search matches the words of text as prefixes of the words of the name,
+// email and phone number of users. Unlike the postgresql implementation it
+// does not tolerate typos, and its ranks only compare users with each other.
+func (u *userRepository) Search(ctx context.Context, text string, limit int) ([]repository.UserMatch, error) {
+	words := searchWords(text)
+	if len(words) == 0 {
+		return []repository.UserMatch{}, nil
+	}
+
+	u.store.mu.RLock()
+	defer u.store.mu.RUnlock()
+
+	matches := []repository.UserMatch{}
+	for _, user := range u.store.users {
+		if !u.visible(ctx, user) {
+			continue
+		}
+
+		rank, matched := 0.0, 0
+		for _, word := range words {
+			best := 0.0
+			for _, field := range userSearchWeights {
+				for _, token := range searchWords(field.field(&user)) {
+					if strings.HasPrefix(token, word) {
+						best = max(best, field.weight)
+					}
+				}
+			}
+			if best > 0 {
+				matched++
+			}
+			rank += best
+		}
+		if matched < len(words) {
+			continue
+		}
+
+		matches = append(matches, repository.UserMatch{User: u.withTenantRole(ctx, user), Rank: rank / float64(len(words))})
+	}
+
+	slices.SortFunc(matches, func(a, b repository.UserMatch) int {
+		if c := cmp.Compare(b.Rank, a.Rank); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.User.ID, b.User.ID)
+	})
+	return paginate(matches, 0, limit), nil
+}
+
+// Update saves the user if it still has the version it was loaded with, and
+// moves it to the next version. Within an organization the role is written to
+// the membership and the global role is left alone.
+func (u *userRepository) Update(ctx context.Context, user *entity.User) error {
+	u.store.mu.Lock()
+	defer u.store.mu.Unlock()
+
+	stored, ok := u.store.users[user.ID]
+	if !ok || !u.visible(ctx, stored) {
+		return errors.ErrNotFound
+	}
+	if stored.Version != user.Version {
+		return errors.ErrPreconditionFailed.WithMessage("user was modified concurrently")
+	}
+
+	row := *user
+	// token_version is only ever changed through IncrementTokenVersion so a
+	// stale copy of the user can never undo a revocation.
+	row.TokenVersion = stored.TokenVersion
+	row.Version++
+	row.UpdatedAt = time.Now()
+
+	organizationID, scoped := tenant.FromContext(ctx)
+	if scoped {
+		row.Role = stored.Role
+		if _, ok := u.store.roles[string(user.Role)]; !ok {
+			return errors.ErrUnprocessable.WithMessage("role refers to a record that does not exist (memberships_role_fkey)")
+		}
+	}
+	if err := u.checkUser(&row); err != nil {
+		return err
+	}
+
+	put(ctx, u.store.users, row.ID, row)
+	if scoped {
+		key := membershipKey{organizationID: organizationID, userID: row.ID}
+		membership := u.store.memberships[key]
+		membership.Role = user.Role
+		put(ctx, u.store.memberships, key, membership)
+	}
+
+	user.Version = row.Version
+	user.UpdatedAt = row.UpdatedAt
+	return nil
+}
+
+// Delete soft-deletes the user. Within an organization only the membership is
+// removed, and the account itself is deleted once it belongs to no
+// organization anymore.
+func (u *userRepository) Delete(ctx context.Context, id string) error {
+	u.store.mu.Lock()
+	defer u.store.mu.Unlock()
+
+	user, ok := u.store.users[id]
+	if !ok || user.DeletedAt.Valid {
+		return errors.ErrNotFound
+	}
+
+	organizationID, scoped := tenant.FromContext(ctx)
+	if scoped {
+		key := membershipKey{organizationID: organizationID, userID: id}
+		if _, ok := u.store.memberships[key]; !ok {
+			return errors.ErrNotFound
+		}
+		remove(ctx, u.store.memberships, key)
+
+		for key := range u.store.memberships {
+			if key.userID == id {
+				return nil
+			}
+		}
+	}
+
+	user.DeletedAt = gorm.DeletedAt{Time: time.Now(), Valid: true}
+	put(ctx, u.store.users, id, user)
+	return nil
+}
+
+func (u *userRepository) ListDeleted(ctx context.Context, offset, limit int) ([]entity.User, int64, error) {
+	u.store.mu.RLock()
+	users := []entity.User{}
+	for _, user := range u.store.users {
+		if user.DeletedAt.Valid {
+			users = append(users, user)
+		}
+	}
+	u.store.mu.RUnlock()
+
+	slices.SortFunc(users, func(a, b entity.User) int {
+		if c := b.DeletedAt.Time.Compare(a.DeletedAt.Time); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.ID, b.ID)
+	})
+
+	return paginate(users, offset, limit), int64(len(users)), nil
+}
+
+func (u *userRepository) GetDeletedByID(ctx context.Context, id string) (*entity.User, error) {
+	u.store.mu.RLock()
+	defer u.store.mu.RUnlock()
+
+	user, ok := u.store.users[id]
+	if !ok || !user.DeletedAt.Valid {
+		return nil, errors.ErrNotFound
+	}
+
+	return &user, nil
+}
+
+// Restore undeletes a soft-deleted user and moves it to the next version.
+func (u *userRepository) Restore(ctx context.Context, id string) error {
+	u.store.mu.Lock()
+	defer u.store.mu.Unlock()
+
+	user, ok := u.store.users[id]
+	if !ok || !user.DeletedAt.Valid {
+		return errors.ErrNotFound
+	}
+
+	user.DeletedAt = gorm.DeletedAt{}
+	user.Version++
+	user.UpdatedAt = time.Now()
+	if err := u.checkUser(&user); err != nil {
+		return err
+	}
+
+	put(ctx, u.store.users, id, user)
+	return nil
+}
+
+func (u *userRepository) Purge(ctx context.Context, id string) error {
+	u.store.mu.Lock()
+	defer u.store.mu.Unlock()
+
+	user, ok := u.store.users[id]
+	if !ok || !user.DeletedAt.Valid {
+		return errors.ErrNotFound
+	}
+
+	u.store.purgeUser(ctx, id)
+	return nil
+}
+
+func (u *userRepository) PurgeDeletedBefore(ctx context.Context, before time.Time) (int64, error) {
+	u.store.mu.Lock()
+	defer u.store.mu.Unlock()
+
+	var purged int64
+	for id, user := range u.store.users {
+		if user.DeletedAt.Valid && user.DeletedAt.Time.Before(before) {
+			u.store.purgeUser(ctx, id)
+			purged++
+		}
+	}
+
+	return purged, nil
+}
+
+func (u *userRepository) IncrementTokenVersion(ctx context.Context, id string) (int, error) {
+	u.store.mu.Lock()
+	defer u.store.mu.Unlock()
+
+	user, ok := u.store.users[id]
+	if !ok || !u.visible(ctx, user) {
+		return 0, errors.ErrNotFound
+	}
+
+	user.TokenVersion++
+	put(ctx, u.store.users, id, user)
+	return user.TokenVersion, nil
+}
@@ -0,0 +1,212 @@
+package memory
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/HasanNugroho/gin-clean/internal/domain/entity"
+	"github.com/HasanNugroho/gin-clean/internal/domain/repository"
+	"github.com/HasanNugroho/gin-clean/pkg/constants"
+	"github.com/HasanNugroho/gin-clean/pkg/errors"
+	"github.com/HasanNugroho/gin-clean/pkg/tenant"
+)
+
+func createUser(t *testing.T, users repository.UserRepository, ctx context.Context, name, email string) *entity.User {
+	t.Helper()
+	user := &entity.User{Name: name, Email: email, IsActive: true}
+	if err := users.Create(ctx, user); err != nil {
+		t.Fatal(err)
+	}
+	return user
+}
+
+func TestUserRepositoryCreate(t *testing.T) {
+	ctx := context.Background()
+	users := NewUserRepository(NewStore())
+
+	user := createUser(t, users, ctx, "Jane", "jane@example.com")
+	if user.ID == "" || user.Version != 1 || user.Role != constants.ROLE_USER || user.CreatedAt.IsZero() {
+		t.Errorf("created user = %+v", user)
+	}
+
+	got, err := users.GetByEmail(ctx, "jane@example.com")
+	if err != nil || got.ID != user.ID {
+		t.Errorf("GetByEmail = %+v, %v", got, err)
+	}
+
+	duplicate := &entity.User{Name: "Jane", Email: "jane@example.com"}
+	if err := users.Create(ctx, duplicate); errors.StatusCode(err) != http.StatusConflict {
+		t.Errorf("duplicate email: err = %v, want 409", err)
+	}
+
+	unknownRole := &entity.User{Name: "John", Email: "john@example.com", Role: constants.Role("nobody")}
+	if err := users.Create(ctx, unknownRole); errors.StatusCode(err) != http.StatusUnprocessableEntity {
+		t.Errorf("unknown role: err = %v, want 422", err)
+	}
+}
+
+func TestUserRepositoryUpdateChecksVersion(t *testing.T) {
+	ctx := context.Background()
+	users := NewUserRepository(NewStore())
+	user := createUser(t, users, ctx, "Jane", "jane@example.com")
+
+	stale := *user
+	user.Name = "Janet"
+	if err := users.Update(ctx, user); err != nil {
+		t.Fatal(err)
+	}
+	if user.Version != 2 {
+		t.Errorf("version = %d, want 2", user.Version)
+	}
+
+	stale.Name = "Jan"
+	if err := users.Update(ctx, &stale); errors.StatusCode(err) != http.StatusPreconditionFailed {
+		t.Errorf("stale update: err = %v, want 412", err)
+	}
+
+	got, err := users.GetByID(ctx, user.ID)
+	if err != nil || got.Name != "Janet" {
+		t.Errorf("GetByID = %+v, %v", got, err)
+	}
+}
+
+func TestUserRepositoryUpdateKeepsTokenVersion(t *testing.T) {
+	ctx := context.Background()
+	users := NewUserRepository(NewStore())
+	user := createUser(t, users, ctx, "Jane", "jane@example.com")
+
+	if version, err := users.IncrementTokenVersion(ctx, user.ID); err != nil || version != 1 {
+		t.Fatalf("IncrementTokenVersion = %d, %v", version, err)
+	}
+
+	// user was loaded before the revocation.
+	user.Name = "Janet"
+	if err := users.Update(ctx, user); err != nil {
+		t.Fatal(err)
+	}
+	got, err := users.GetByID(ctx, user.ID)
+	if err != nil || got.TokenVersion != 1 {
+		t.Errorf("token version = %d, %v, want 1", got.TokenVersion, err)
+	}
+}
+
+func TestUserRepositoryDeleteRestorePurge(t *testing.T) {
+	ctx := context.Background()
+	users := NewUserRepository(NewStore())
+	user := createUser(t, users, ctx, "Jane", "jane@example.com")
+
+	if err := users.Delete(ctx, user.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := users.GetByID(ctx, user.ID); errors.StatusCode(err) != http.StatusNotFound {
+		t.Errorf("GetByID of a deleted user: err = %v, want 404", err)
+	}
+	if deleted, total, err := users.ListDeleted(ctx, 0, 10); err != nil || total != 1 || deleted[0].ID != user.ID {
+		t.Errorf("ListDeleted = %v, %d, %v", deleted, total, err)
+	}
+
+	// The email of a deleted user is free again, so restoring it conflicts.
+	other := createUser(t, users, ctx, "Jane", "jane@example.com")
+	if err := users.Restore(ctx, user.ID); errors.StatusCode(err) != http.StatusConflict {
+		t.Errorf("restore over a live email: err = %v, want 409", err)
+	}
+	if err := users.Delete(ctx, other.ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := users.Purge(ctx, other.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := users.Restore(ctx, user.ID); err != nil {
+		t.Fatal(err)
+	}
+	restored, err := users.GetByID(ctx, user.ID)
+	if err != nil || restored.Version != user.Version+1 {
+		t.Errorf("restored user = %+v, %v", restored, err)
+	}
+
+	if err := users.Purge(ctx, user.ID); errors.StatusCode(err) != http.StatusNotFound {
+		t.Errorf("purging a live user: err = %v, want 404", err)
+	}
+}
+
+func TestUserRepositoryPurgeDeletedBefore(t *testing.T) {
+	ctx := context.Background()
+	store := NewStore()
+	users := NewUserRepository(store)
+	sessions := NewSessionRepository(store)
+
+	old := createUser(t, users, ctx, "Jane", "jane@example.com")
+	recent := createUser(t, users, ctx, "John", "john@example.com")
+	if err := sessions.Create(ctx, &entity.Session{ID: newID(), UserID: old.ID}); err != nil {
+		t.Fatal(err)
+	}
+	for _, id := range []string{old.ID, recent.ID} {
+		if err := users.Delete(ctx, id); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Only old was deleted before the cutoff.
+	cutoff := time.Now()
+	deleted := store.users[old.ID]
+	deleted.DeletedAt.Time = cutoff.Add(-time.Hour)
+	store.users[old.ID] = deleted
+
+	purged, err := users.PurgeDeletedBefore(ctx, cutoff.Add(-time.Minute))
+	if err != nil || purged != 1 {
+		t.Fatalf("PurgeDeletedBefore = %d, %v, want 1", purged, err)
+	}
+	if _, err := users.GetDeletedByID(ctx, old.ID); errors.StatusCode(err) != http.StatusNotFound {
+		t.Errorf("purged user: err = %v, want 404", err)
+	}
+	if _, err := users.GetDeletedByID(ctx, recent.ID); err != nil {
+		t.Errorf("recently deleted user: %v", err)
+	}
+	if len(store.sessions) != 0 {
+		t.Errorf("%d sessions of the purged user left", len(store.sessions))
+	}
+}
+
+func TestUserRepositoryTenantScope(t *testing.T) {
+	store := NewStore()
+	users := NewUserRepository(store)
+	organizations := NewOrganizationRepository(store)
+
+	owner := createUser(t, users, context.Background(), "Jane", "jane@example.com")
+	organization := &entity.Organization{Name: "Acme", Slug: "acme"}
+	if err := organizations.Create(context.Background(), organization, &entity.Membership{UserID: owner.ID, Role: constants.ROLE_ADMIN}); err != nil {
+		t.Fatal(err)
+	}
+	outsider := createUser(t, users, context.Background(), "John", "john@example.com")
+
+	ctx := tenant.WithTenant(context.Background(), organization.ID)
+	got, err := users.GetByID(ctx, owner.ID)
+	if err != nil || got.Role != constants.ROLE_ADMIN {
+		t.Errorf("member = %+v, %v, want the admin membership role", got, err)
+	}
+	if _, err := users.GetByID(ctx, outsider.ID); errors.StatusCode(err) != http.StatusNotFound {
+		t.Errorf("user outside the organization: err = %v, want 404", err)
+	}
+
+	// Created within the organization, the role is the membership's only.
+	member := &entity.User{Name: "Ann", Email: "ann@example.com", Role: constants.ROLE_ADMIN}
+	if err := users.Create(ctx, member); err != nil {
+		t.Fatal(err)
+	}
+	global, err := users.GetByID(context.Background(), member.ID)
+	if err != nil || global.Role != constants.ROLE_USER {
+		t.Errorf("global role = %+v, %v, want user", global, err)
+	}
+
+	// Deleting within the organization only ends the membership while the
+	// user belongs to another one.
+	if err := users.Delete(ctx, member.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := users.GetByID(context.Background(), member.ID); errors.StatusCode(err) != http.StatusNotFound {
+		t.Errorf("user of no organization anymore: err = %v, want 404", err)
+	}
+}
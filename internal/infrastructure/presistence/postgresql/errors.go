@@ -0,0 +1,81 @@
+package postgresql
+
+import (
+	stderrors "errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/HasanNugroho/gin-clean/pkg/errors"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// PostgreSQL error codes translated by translateError, see
+// https://www.postgresql.org/docs/current/errcodes-appendix.html
+const (
+	pgUniqueViolation           = "23505"
+	pgForeignKeyViolation       = "23503"
+	pgNotNullViolation          = "23502"
+	pgCheckViolation            = "23514"
+	pgInvalidTextRepresentation = "22P02"
+	pgStringDataRightTruncation = "22001"
+	pgSerializationFailure      = "40001"
+	pgDeadlockDetected          = "40P01"
+)
+
+// isRetryable reports whether err aborted a transaction only because of
+// concurrent transactions, so that running it again may succeed.
+func isRetryable(err error) bool {
+	var pgErr *pgconn.PgError
+	if !stderrors.As(err, &pgErr) {
+		return false
+	}
+	return pgErr.Code == pgSerializationFailure || pgErr.Code == pgDeadlockDetected
+}
+
+// detailKey extracts the columns from details like
+// `Key (email)=(jane@example.com) already exists.`
+var detailKey = regexp.MustCompile(`^Key \(([^)]+)\)=`)
+
+// translateError turns constraint violations and similar errors caused by the
+// data of a request into the matching AppError, naming the offending field or
+// constraint. Anything else is an internal error.
+func translateError(err error) error {
+	var pgErr *pgconn.PgError
+	if !stderrors.As(err, &pgErr) {
+		return errors.Wrap(errors.ErrInternalServer, err)
+	}
+
+	field := pgErr.ColumnName
+	if match := detailKey.FindStringSubmatch(pgErr.Detail); match != nil {
+		field = match[1]
+	}
+	if field == "" {
+		field = pgErr.ConstraintName
+	}
+
+	switch pgErr.Code {
+	case pgUniqueViolation:
+		return errors.ErrConflict.WithMessage(fmt.Sprintf("%s already exists (%s)", field, pgErr.ConstraintName))
+	case pgForeignKeyViolation:
+		// The same code is raised for deleting a row that is still referenced
+		// and for referring to a row that does not exist.
+		if strings.Contains(pgErr.Detail, "is still referenced") {
+			return errors.ErrConflict.WithMessage(fmt.Sprintf("%s is still referenced (%s)", field, pgErr.ConstraintName))
+		}
+		return errors.ErrUnprocessable.WithMessage(fmt.Sprintf("%s refers to a record that does not exist (%s)", field, pgErr.ConstraintName))
+	case pgNotNullViolation:
+		return errors.ErrUnprocessable.WithMessage(fmt.Sprintf("%s is required", field))
+	case pgCheckViolation:
+		return errors.ErrUnprocessable.WithMessage(fmt.Sprintf("value violates check constraint %s", pgErr.ConstraintName))
+	case pgInvalidTextRepresentation, pgStringDataRightTruncation:
+		// Covers values outside of an enum type, malformed UUIDs and values
+		// too long for their column.
+		return errors.ErrUnprocessable.WithMessage(pgErr.Message)
+	case pgSerializationFailure, pgDeadlockDetected:
+		// The cause is kept so the transaction manager can retry.
+		return errors.ErrConflict.WithMessage("the request conflicted with a concurrent one, retry it").WithError(err)
+	default:
+		return errors.Wrap(errors.ErrInternalServer, err)
+	}
+}
@@ -0,0 +1,141 @@
+package postgresql
+
+import (
+	stderrors "errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/HasanNugroho/gin-clean/pkg/errors"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+func TestTranslateError(t *testing.T) {
+	tests := []struct {
+		name    string
+		err     error
+		status  int
+		message string
+	}{
+		{
+			name: "unique violation names the key",
+			err: &pgconn.PgError{
+				Code:           pgUniqueViolation,
+				ConstraintName: "idx_users_email_active",
+				Detail:         "Key (email)=(jane@example.com) already exists.",
+			},
+			status:  http.StatusConflict,
+			message: "email already exists (idx_users_email_active)",
+		},
+		{
+			name: "foreign key to a missing row",
+			err: &pgconn.PgError{
+				Code:           pgForeignKeyViolation,
+				ConstraintName: "sessions_user_id_fkey",
+				Detail:         `Key (user_id)=(42) is not present in table "users".`,
+			},
+			status:  http.StatusUnprocessableEntity,
+			message: "user_id refers to a record that does not exist (sessions_user_id_fkey)",
+		},
+		{
+			name: "foreign key of a referenced row",
+			err: &pgconn.PgError{
+				Code:           pgForeignKeyViolation,
+				ConstraintName: "users_role_fkey",
+				Detail:         `Key (name)=(editor) is still referenced from table "users".`,
+			},
+			status:  http.StatusConflict,
+			message: "name is still referenced (users_role_fkey)",
+		},
+		{
+			name:    "not null names the column",
+			err:     &pgconn.PgError{Code: pgNotNullViolation, ColumnName: "phone_number"},
+			status:  http.StatusUnprocessableEntity,
+			message: "phone_number is required",
+		},
+		{
+			name:    "check violation names the constraint",
+			err:     &pgconn.PgError{Code: pgCheckViolation, ConstraintName: "users_email_check"},
+			status:  http.StatusUnprocessableEntity,
+			message: "value violates check constraint users_email_check",
+		},
+		{
+			name:    "invalid text representation",
+			err:     &pgconn.PgError{Code: pgInvalidTextRepresentation, Message: `invalid input syntax for type uuid: "x"`},
+			status:  http.StatusUnprocessableEntity,
+			message: `invalid input syntax for type uuid: "x"`,
+		},
+		{
+			name:    "string too long",
+			err:     &pgconn.PgError{Code: pgStringDataRightTruncation, Message: "value too long for type character varying(20)"},
+			status:  http.StatusUnprocessableEntity,
+			message: "value too long for type character varying(20)",
+		},
+		{
+			name:    "serialization failure",
+			err:     &pgconn.PgError{Code: pgSerializationFailure},
+			status:  http.StatusConflict,
+			message: "the request conflicted with a concurrent one, retry it",
+		},
+		{
+			name:    "deadlock",
+			err:     &pgconn.PgError{Code: pgDeadlockDetected},
+			status:  http.StatusConflict,
+			message: "the request conflicted with a concurrent one, retry it",
+		},
+		{
+			name:   "wrapped",
+			err:    fmt.Errorf("insert: %w", &pgconn.PgError{Code: pgNotNullViolation, ColumnName: "name"}),
+			status: http.StatusUnprocessableEntity,
+		},
+		{
+			name:   "other PostgreSQL error",
+			err:    &pgconn.PgError{Code: "53100"},
+			status: http.StatusInternalServerError,
+		},
+		{
+			name:   "not a PostgreSQL error",
+			err:    stderrors.New("connection refused"),
+			status: http.StatusInternalServerError,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := translateError(tt.err)
+			if status := errors.StatusCode(err); status != tt.status {
+				t.Errorf("status = %d, want %d", status, tt.status)
+			}
+			var appErr *errors.AppError
+			if !stderrors.As(err, &appErr) {
+				t.Fatalf("%v is not an AppError", err)
+			}
+			if tt.message != "" && appErr.Message != tt.message {
+				t.Errorf("message = %q, want %q", appErr.Message, tt.message)
+			}
+			if tt.status == http.StatusInternalServerError && !stderrors.Is(err, tt.err) {
+				t.Errorf("internal error %v does not wrap %v", err, tt.err)
+			}
+		})
+	}
+}
+
+func TestIsRetryable(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{name: "serialization failure", err: &pgconn.PgError{Code: pgSerializationFailure}, want: true},
+		{name: "deadlock", err: &pgconn.PgError{Code: pgDeadlockDetected}, want: true},
+		{name: "translated by a repository", err: translateError(&pgconn.PgError{Code: pgSerializationFailure}), want: true},
+		{name: "unique violation", err: &pgconn.PgError{Code: pgUniqueViolation}, want: false},
+		{name: "application error", err: errors.ErrConflict.WithMessage("email is already in use"), want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isRetryable(tt.err); got != tt.want {
+				t.Errorf("isRetryable(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
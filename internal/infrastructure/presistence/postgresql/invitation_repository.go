@@ -0,0 +1,163 @@
+package postgresql
+
+import (
+	"context"
+	"time"
+
+	"github.com/HasanNugroho/gin-clean/internal/domain/entity"
+	"github.com/HasanNugroho/gin-clean/internal/domain/repository"
+	"github.com/HasanNugroho/gin-clean/pkg/errors"
+	"github.com/HasanNugroho/gin-clean/pkg/tenant"
+	"gorm.io/gorm"
+)
+
+type invitationRepository struct {
+	db *gorm.DB
+}
+
+func NewInvitationRepository(db *gorm.DB) repository.InvitationRepository {
+	return &invitationRepository{
+		db: db,
+	}
+}
+
+// invitationScope restricts queries to the invitations of the organization of
+// ctx, if any.
+func invitationScope(ctx context.Context) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		organizationID, ok := tenant.FromContext(ctx)
+		if !ok {
+			return db
+		}
+		return db.Where("organization_id = ?", organizationID)
+	}
+}
+
+// pending matches invitations that can still be accepted.
+func pending(now time.Time) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where("accepted_at IS NULL AND revoked_at IS NULL AND expires_at > ?", now)
+	}
+}
+
+func (i *invitationRepository) Create(ctx context.Context, invitation *entity.Invitation) error {
+	db := conn(ctx, i.db)
+
+	result := db.Create(invitation)
+	if result.Error != nil {
+		return translateError(result.Error)
+	}
+
+	return nil
+}
+
+func (i *invitationRepository) GetByID(ctx context.Context, id string) (*entity.Invitation, error) {
+	return i.first(conn(ctx, i.db).Scopes(invitationScope(ctx)), "id = ?", id)
+}
+
+func (i *invitationRepository) GetByTokenHash(ctx context.Context, tokenHash string) (*entity.Invitation, error) {
+	return i.first(conn(ctx, i.db), "token_hash = ?", tokenHash)
+}
+
+func (i *invitationRepository) first(db *gorm.DB, query string, args ...interface{}) (*entity.Invitation, error) {
+	var invitation entity.Invitation
+	result := db.Where(query, args...).First(&invitation)
+	if result.Error != nil {
+		if result.Error == gorm.ErrRecordNotFound {
+			return nil, errors.ErrNotFound
+		}
+		return nil, translateError(result.Error)
+	}
+
+	return &invitation, nil
+}
+
+func (i *invitationRepository) GetPendingByEmail(ctx context.Context, email string) (*entity.Invitation, error) {
+	db := conn(ctx, i.db)
+
+	var invitation entity.Invitation
+	result := db.Scopes(invitationScope(ctx), pending(time.Now())).
+		Where("LOWER(email) = LOWER(?)", email).
+		First(&invitation)
+	if result.Error != nil {
+		if result.Error == gorm.ErrRecordNotFound {
+			return nil, errors.ErrNotFound
+		}
+		return nil, translateError(result.Error)
+	}
+
+	return &invitation, nil
+}
+
+func (i *invitationRepository) ListPending(ctx context.Context) ([]entity.Invitation, error) {
+	db := conn(ctx, i.db)
+
+	invitations := []entity.Invitation{}
+	result := db.Scopes(invitationScope(ctx), pending(time.Now())).
+		Order("created_at DESC").
+		Find(&invitations)
+	if result.Error != nil {
+		return nil, translateError(result.Error)
+	}
+
+	return invitations, nil
+}
+
+func (i *invitationRepository) Renew(ctx context.Context, id string, tokenHash string, expiresAt time.Time) error {
+	db := conn(ctx, i.db)
+
+	// Expired invitations can be renewed, accepted or revoked ones cannot.
+	result := db.Model(&entity.Invitation{}).
+		Scopes(invitationScope(ctx)).
+		Where("id = ? AND accepted_at IS NULL AND revoked_at IS NULL", id).
+		Updates(map[string]interface{}{
+			"token_hash": tokenHash,
+			"expires_at": expiresAt,
+			"updated_at": time.Now(),
+		})
+	if result.Error != nil {
+		return translateError(result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return errors.ErrNotFound
+	}
+
+	return nil
+}
+
+func (i *invitationRepository) MarkAccepted(ctx context.Context, id string, acceptedAt time.Time) (bool, error) {
+	db := conn(ctx, i.db)
+
+	result := db.Model(&entity.Invitation{}).
+		Scopes(pending(acceptedAt)).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"accepted_at": acceptedAt,
+			"updated_at":  acceptedAt,
+		})
+	if result.Error != nil {
+		return false, translateError(result.Error)
+	}
+
+	return result.RowsAffected == 1, nil
+}
+
+func (i *invitationRepository) Revoke(ctx context.Context, id string, revokedAt time.Time) error {
+	db := conn(ctx, i.db)
+
+	result := db.Model(&entity.Invitation{}).
+		Scopes(invitationScope(ctx), pending(revokedAt)).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"revoked_at": revokedAt,
+			"updated_at": revokedAt,
+		})
+	if result.Error != nil {
+		return translateError(result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return errors.ErrNotFound
+	}
+
+	return nil
+}
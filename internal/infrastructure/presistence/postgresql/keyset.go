@@ -0,0 +1,66 @@
+package postgresql
+
+import (
+	"slices"
+
+	"github.com/HasanNugroho/gin-clean/internal/domain/repository"
+	"gorm.io/gorm"
+)
+
+// keysetPaginate fetches one page of query ordered by (created_at, id) of
+// table. Unlike offsets, the position is looked up through the index, so late
+// pages cost as much as the first one. position reads the key of a row.
+func keysetPaginate[T any](query *gorm.DB, table string, page repository.CursorPage, position func(*T) repository.Cursor) ([]T, repository.CursorResult, error) {
+	var result repository.CursorResult
+
+	createdAt, id := table+".created_at", table+".id"
+	backward := page.Before != nil
+
+	switch {
+	case backward:
+		query = query.Where("("+createdAt+", "+id+") < (?, ?)", page.Before.CreatedAt, page.Before.ID).
+			Order(createdAt + " DESC").
+			Order(id + " DESC")
+	case page.After != nil:
+		query = query.Where("("+createdAt+", "+id+") > (?, ?)", page.After.CreatedAt, page.After.ID).
+			Order(createdAt).
+			Order(id)
+	default:
+		query = query.Order(createdAt).Order(id)
+	}
+
+	// One extra row tells whether there is more in the direction of travel.
+	rows := []T{}
+	if err := query.Limit(page.Limit + 1).Find(&rows).Error; err != nil {
+		return nil, result, err
+	}
+
+	more := len(rows) > page.Limit
+	if more {
+		rows = rows[:page.Limit]
+	}
+	if backward {
+		slices.Reverse(rows)
+	}
+	if len(rows) == 0 {
+		return rows, result, nil
+	}
+
+	first, last := position(&rows[0]), position(&rows[len(rows)-1])
+	switch {
+	case backward:
+		result.Next = &last
+		if more {
+			result.Prev = &first
+		}
+	default:
+		if more {
+			result.Next = &last
+		}
+		if page.After != nil {
+			result.Prev = &first
+		}
+	}
+
+	return rows, result, nil
+}
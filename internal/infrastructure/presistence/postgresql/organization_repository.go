@@ -0,0 +1,131 @@
+package postgresql
+
+import (
+	"context"
+
+	"github.com/HasanNugroho/gin-clean/internal/domain/entity"
+	"github.com/HasanNugroho/gin-clean/internal/domain/repository"
+	"github.com/HasanNugroho/gin-clean/pkg/errors"
+	"gorm.io/gorm"
+)
+
+type organizationRepository struct {
+	db *gorm.DB
+}
+
+func NewOrganizationRepository(db *gorm.DB) repository.OrganizationRepository {
+	return &organizationRepository{
+		db: db,
+	}
+}
+
+func (o *organizationRepository) Create(ctx context.Context, organization *entity.Organization, owner *entity.Membership) error {
+	db := conn(ctx, o.db)
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(organization).Error; err != nil {
+			return err
+		}
+		owner.OrganizationID = organization.ID
+		return tx.Omit("Organization", "User").Create(owner).Error
+	})
+	if err != nil {
+		return translateError(err)
+	}
+
+	return nil
+}
+
+func (o *organizationRepository) GetByID(ctx context.Context, id string) (*entity.Organization, error) {
+	return o.first(ctx, "id = ?", id)
+}
+
+func (o *organizationRepository) GetBySlug(ctx context.Context, slug string) (*entity.Organization, error) {
+	return o.first(ctx, "slug = ?", slug)
+}
+
+func (o *organizationRepository) first(ctx context.Context, query string, args ...interface{}) (*entity.Organization, error) {
+	db := conn(ctx, o.db)
+
+	var organization entity.Organization
+	result := db.Where(query, args...).First(&organization)
+	if result.Error != nil {
+		if result.Error == gorm.ErrRecordNotFound {
+			return nil, errors.ErrNotFound
+		}
+		return nil, translateError(result.Error)
+	}
+
+	return &organization, nil
+}
+
+func (o *organizationRepository) GetMembership(ctx context.Context, organizationID, userID string) (*entity.Membership, error) {
+	db := conn(ctx, o.db)
+
+	var membership entity.Membership
+	result := db.Where("organization_id = ? AND user_id = ?", organizationID, userID).First(&membership)
+	if result.Error != nil {
+		if result.Error == gorm.ErrRecordNotFound {
+			return nil, errors.ErrNotFound
+		}
+		return nil, translateError(result.Error)
+	}
+
+	return &membership, nil
+}
+
+func (o *organizationRepository) ListMemberships(ctx context.Context, userID string) ([]entity.Membership, error) {
+	db := conn(ctx, o.db)
+
+	var memberships []entity.Membership
+	result := db.Preload("Organization").
+		Where("user_id = ?", userID).
+		Order("created_at").
+		Find(&memberships)
+	if result.Error != nil {
+		return nil, translateError(result.Error)
+	}
+
+	return memberships, nil
+}
+
+func (o *organizationRepository) ListMembers(ctx context.Context, organizationID string) ([]entity.Membership, error) {
+	db := conn(ctx, o.db)
+
+	var memberships []entity.Membership
+	result := db.Preload("User").
+		Joins("JOIN users ON users.id = memberships.user_id AND users.deleted_at IS NULL").
+		Where("memberships.organization_id = ?", organizationID).
+		Order("memberships.created_at").
+		Find(&memberships)
+	if result.Error != nil {
+		return nil, translateError(result.Error)
+	}
+
+	return memberships, nil
+}
+
+func (o *organizationRepository) AddMember(ctx context.Context, membership *entity.Membership) error {
+	db := conn(ctx, o.db)
+
+	result := db.Omit("Organization", "User").Create(membership)
+	if result.Error != nil {
+		return translateError(result.Error)
+	}
+
+	return nil
+}
+
+func (o *organizationRepository) RemoveMember(ctx context.Context, organizationID, userID string) error {
+	db := conn(ctx, o.db)
+
+	result := db.Where("organization_id = ? AND user_id = ?", organizationID, userID).Delete(&entity.Membership{})
+	if result.Error != nil {
+		return translateError(result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return errors.ErrNotFound
+	}
+
+	return nil
+}
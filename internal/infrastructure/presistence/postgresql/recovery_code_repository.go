@@ -0,0 +1,75 @@
+package postgresql
+
+import (
+	"context"
+	"time"
+
+	"github.com/HasanNugroho/gin-clean/internal/domain/entity"
+	"github.com/HasanNugroho/gin-clean/internal/domain/repository"
+	"gorm.io/gorm"
+)
+
+type recoveryCodeRepository struct {
+	db *gorm.DB
+}
+
+func NewRecoveryCodeRepository(db *gorm.DB) repository.RecoveryCodeRepository {
+	return &recoveryCodeRepository{
+		db: db,
+	}
+}
+
+func (r *recoveryCodeRepository) ListUnusedByUser(ctx context.Context, userID string) ([]entity.RecoveryCode, error) {
+	db := conn(ctx, r.db)
+
+	var codes []entity.RecoveryCode
+	result := db.Where("user_id = ? AND used_at IS NULL", userID).Find(&codes)
+	if result.Error != nil {
+		return nil, translateError(result.Error)
+	}
+
+	return codes, nil
+}
+
+func (r *recoveryCodeRepository) Replace(ctx context.Context, userID string, codes []entity.RecoveryCode) error {
+	db := conn(ctx, r.db)
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&entity.RecoveryCode{}).Error; err != nil {
+			return err
+		}
+		if len(codes) == 0 {
+			return nil
+		}
+		return tx.Create(&codes).Error
+	})
+	if err != nil {
+		return translateError(err)
+	}
+
+	return nil
+}
+
+func (r *recoveryCodeRepository) MarkUsed(ctx context.Context, id string, usedAt time.Time) (bool, error) {
+	db := conn(ctx, r.db)
+
+	result := db.Model(&entity.RecoveryCode{}).
+		Where("id = ? AND used_at IS NULL", id).
+		Update("used_at", usedAt)
+	if result.Error != nil {
+		return false, translateError(result.Error)
+	}
+
+	return result.RowsAffected == 1, nil
+}
+
+func (r *recoveryCodeRepository) DeleteByUser(ctx context.Context, userID string) error {
+	db := conn(ctx, r.db)
+
+	result := db.Where("user_id = ?", userID).Delete(&entity.RecoveryCode{})
+	if result.Error != nil {
+		return translateError(result.Error)
+	}
+
+	return nil
+}
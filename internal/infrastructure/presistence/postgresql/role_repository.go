@@ -0,0 +1,170 @@
+package postgresql
+
+import (
+	"context"
+
+	"github.com/HasanNugroho/gin-clean/internal/domain/entity"
+	"github.com/HasanNugroho/gin-clean/internal/domain/repository"
+	"github.com/HasanNugroho/gin-clean/pkg/errors"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type roleRepository struct {
+	db *gorm.DB
+}
+
+func NewRoleRepository(db *gorm.DB) repository.RoleRepository {
+	return &roleRepository{
+		db: db,
+	}
+}
+
+func (r *roleRepository) List(ctx context.Context) ([]entity.Role, error) {
+	db := conn(ctx, r.db)
+
+	var roles []entity.Role
+	if err := db.Order("name").Find(&roles).Error; err != nil {
+		return nil, translateError(err)
+	}
+
+	var assignments []entity.RolePermission
+	if err := db.Order("permission_name").Find(&assignments).Error; err != nil {
+		return nil, translateError(err)
+	}
+
+	permissions := make(map[string][]string, len(roles))
+	for _, a := range assignments {
+		permissions[a.RoleName] = append(permissions[a.RoleName], a.PermissionName)
+	}
+	for i := range roles {
+		roles[i].Permissions = permissions[roles[i].Name]
+		if roles[i].Permissions == nil {
+			roles[i].Permissions = []string{}
+		}
+	}
+
+	return roles, nil
+}
+
+func (r *roleRepository) GetByName(ctx context.Context, name string) (*entity.Role, error) {
+	db := conn(ctx, r.db)
+
+	var role entity.Role
+	result := db.Where("name = ?", name).First(&role)
+	if result.Error != nil {
+		if result.Error == gorm.ErrRecordNotFound {
+			return nil, errors.ErrNotFound
+		}
+		return nil, translateError(result.Error)
+	}
+
+	permissions, err := r.PermissionsOf(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	role.Permissions = permissions
+
+	return &role, nil
+}
+
+func (r *roleRepository) Create(ctx context.Context, role *entity.Role) error {
+	db := conn(ctx, r.db)
+
+	result := db.Create(role)
+	if result.Error != nil {
+		return translateError(result.Error)
+	}
+
+	return nil
+}
+
+func (r *roleRepository) Delete(ctx context.Context, name string) error {
+	db := conn(ctx, r.db)
+
+	result := db.Where("name = ?", name).Delete(&entity.Role{})
+	if result.Error != nil {
+		return translateError(result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return errors.ErrNotFound
+	}
+
+	return nil
+}
+
+func (r *roleRepository) InUse(ctx context.Context, name string) (bool, error) {
+	db := conn(ctx, r.db)
+
+	var count int64
+	result := db.Model(&entity.User{}).Unscoped().Where("role = ?", name).Limit(1).Count(&count)
+	if result.Error != nil {
+		return false, translateError(result.Error)
+	}
+	if count > 0 {
+		return true, nil
+	}
+
+	result = db.Model(&entity.Membership{}).Where("role = ?", name).Limit(1).Count(&count)
+	if result.Error != nil {
+		return false, translateError(result.Error)
+	}
+
+	return count > 0, nil
+}
+
+func (r *roleRepository) ListPermissions(ctx context.Context) ([]entity.Permission, error) {
+	db := conn(ctx, r.db)
+
+	var permissions []entity.Permission
+	if err := db.Order("name").Find(&permissions).Error; err != nil {
+		return nil, translateError(err)
+	}
+
+	return permissions, nil
+}
+
+func (r *roleRepository) PermissionsOf(ctx context.Context, role string) ([]string, error) {
+	db := conn(ctx, r.db)
+
+	permissions := []string{}
+	result := db.Model(&entity.RolePermission{}).
+		Where("role_name = ?", role).
+		Order("permission_name").
+		Pluck("permission_name", &permissions)
+	if result.Error != nil {
+		return nil, translateError(result.Error)
+	}
+
+	return permissions, nil
+}
+
+func (r *roleRepository) Grant(ctx context.Context, role string, permissions []string) error {
+	db := conn(ctx, r.db)
+
+	assignments := make([]entity.RolePermission, 0, len(permissions))
+	for _, permission := range permissions {
+		assignments = append(assignments, entity.RolePermission{RoleName: role, PermissionName: permission})
+	}
+
+	result := db.Clauses(clause.OnConflict{DoNothing: true}).Create(&assignments)
+	if result.Error != nil {
+		return translateError(result.Error)
+	}
+
+	return nil
+}
+
+func (r *roleRepository) Revoke(ctx context.Context, role string, permission string) error {
+	db := conn(ctx, r.db)
+
+	result := db.Where("role_name = ? AND permission_name = ?", role, permission).Delete(&entity.RolePermission{})
+	if result.Error != nil {
+		return translateError(result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return errors.ErrNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,107 @@
+package postgresql
+
+import (
+	"context"
+	"time"
+
+	"github.com/HasanNugroho/gin-clean/internal/domain/entity"
+	"github.com/HasanNugroho/gin-clean/internal/domain/repository"
+	"github.com/HasanNugroho/gin-clean/pkg/errors"
+	"gorm.io/gorm"
+)
+
+type sessionRepository struct {
+	db *gorm.DB
+}
+
+func NewSessionRepository(db *gorm.DB) repository.SessionRepository {
+	return &sessionRepository{
+		db: db,
+	}
+}
+
+func (s *sessionRepository) Create(ctx context.Context, session *entity.Session) error {
+	db := conn(ctx, s.db)
+
+	result := db.Create(session)
+	if result.Error != nil {
+		return translateError(result.Error)
+	}
+
+	return nil
+}
+
+func (s *sessionRepository) GetByID(ctx context.Context, id string) (*entity.Session, error) {
+	db := conn(ctx, s.db)
+
+	var session entity.Session
+	result := db.Where("id = ?", id).First(&session)
+
+	if result.Error != nil {
+		if result.Error == gorm.ErrRecordNotFound {
+			return nil, errors.ErrNotFound
+		}
+		return nil, translateError(result.Error)
+	}
+
+	return &session, nil
+}
+
+func (s *sessionRepository) ListActiveByUser(ctx context.Context, userID string) ([]entity.Session, error) {
+	db := conn(ctx, s.db)
+
+	var sessions []entity.Session
+	result := db.Where("user_id = ? AND revoked_at IS NULL AND expires_at > ?", userID, time.Now()).
+		Order("last_seen_at DESC").
+		Find(&sessions)
+
+	if result.Error != nil {
+		return nil, translateError(result.Error)
+	}
+
+	return sessions, nil
+}
+
+func (s *sessionRepository) Touch(ctx context.Context, session *entity.Session) error {
+	db := conn(ctx, s.db)
+
+	result := db.Model(&entity.Session{}).
+		Where("id = ?", session.ID).
+		Updates(map[string]interface{}{
+			"ip_address":   session.IPAddress,
+			"user_agent":   session.UserAgent,
+			"last_seen_at": session.LastSeenAt,
+			"expires_at":   session.ExpiresAt,
+		})
+	if result.Error != nil {
+		return translateError(result.Error)
+	}
+
+	return nil
+}
+
+func (s *sessionRepository) Revoke(ctx context.Context, id string, revokedAt time.Time) error {
+	db := conn(ctx, s.db)
+
+	result := db.Model(&entity.Session{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Update("revoked_at", revokedAt)
+	if result.Error != nil {
+		return translateError(result.Error)
+	}
+
+	return nil
+}
+
+func (s *sessionRepository) RevokeAllByUser(ctx context.Context, userID string, revokedAt time.Time) error {
+	db := conn(ctx, s.db)
+
+	result := db.Model(&entity.Session{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Update("revoked_at", revokedAt)
+	if result.Error != nil {
+		return translateError(result.Error)
+	}
+
+	return nil
+}
@@ -0,0 +1,66 @@
+package postgresql
+
+import (
+	"context"
+	"database/sql"
+	stderrors "errors"
+
+	"github.com/HasanNugroho/gin-clean/internal/domain/repository"
+	"github.com/HasanNugroho/gin-clean/pkg/errors"
+	"gorm.io/gorm"
+)
+
+type txKey struct{}
+
+// maxTxAttempts bounds how often a transaction runs when it keeps failing
+// because of concurrent ones.
+const maxTxAttempts = 3
+
+type txManager struct {
+	db *gorm.DB
+}
+
+func NewTxManager(db *gorm.DB) repository.TxManager {
+	return &txManager{
+		db: db,
+	}
+}
+
+// WithinTransaction runs transactions at the SERIALIZABLE isolation level, so
+// what fn reads, such as an email not being taken yet, still holds when it
+// commits. A transaction aborted by a serialization failure or a deadlock is
+// run again, up to maxTxAttempts times, before failing with ErrConflict.
+func (t *txManager) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	if _, ok := ctx.Value(txKey{}).(*gorm.DB); ok {
+		return fn(ctx)
+	}
+
+	var err error
+	for attempt := 1; attempt <= maxTxAttempts; attempt++ {
+		err = conn(ctx, t.db).Transaction(func(tx *gorm.DB) error {
+			return fn(context.WithValue(ctx, txKey{}, tx))
+		}, &sql.TxOptions{Isolation: sql.LevelSerializable})
+		if err == nil || !isRetryable(err) || ctx.Err() != nil {
+			break
+		}
+	}
+	if err != nil {
+		var appErr *errors.AppError
+		if stderrors.As(err, &appErr) {
+			return err
+		}
+		return translateError(err)
+	}
+
+	return nil
+}
+
+// conn returns the transaction carried by ctx, or db outside of one, bound to
+// ctx. Repositories get their connection through it so they join the unit of
+// work of the caller.
+func conn(ctx context.Context, db *gorm.DB) *gorm.DB {
+	if tx, ok := ctx.Value(txKey{}).(*gorm.DB); ok {
+		return tx.WithContext(ctx)
+	}
+	return db.WithContext(ctx)
+}
@@ -2,11 +2,17 @@ package postgresql
 
 import (
 	"context"
+	"strings"
+	"time"
+	"unicode"
 
 	"github.com/HasanNugroho/gin-clean/internal/domain/entity"
 	"github.com/HasanNugroho/gin-clean/internal/domain/repository"
+	"github.com/HasanNugroho/gin-clean/pkg/constants"
 	"github.com/HasanNugroho/gin-clean/pkg/errors"
+	"github.com/HasanNugroho/gin-clean/pkg/tenant"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type userRepository struct {
@@ -19,66 +25,457 @@ func NewUserRepository(db *gorm.DB) repository.UserRepository {
 	}
 }
 
-func (u *userRepository) Create(ctx context.Context, user *entity.User) error {
-	db := u.db.WithContext(ctx)
+// tenantScope restricts queries to the users of the organization of ctx, if
+// any, so one tenant can never see the users of another.
+func tenantScope(ctx context.Context) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		organizationID, ok := tenant.FromContext(ctx)
+		if !ok {
+			return db
+		}
+		return db.Where("EXISTS (SELECT 1 FROM memberships WHERE memberships.user_id = users.id AND memberships.organization_id = ?)", organizationID)
+	}
+}
+
+// withTenantRole replaces the global role of a user loaded within an
+// organization by their role in that organization.
+func withTenantRole(ctx context.Context, db *gorm.DB, user *entity.User) error {
+	organizationID, ok := tenant.FromContext(ctx)
+	if !ok {
+		return nil
+	}
+
+	var membership entity.Membership
+	result := db.Where("organization_id = ? AND user_id = ?", organizationID, user.ID).First(&membership)
+	if result.Error != nil {
+		return result.Error
+	}
+	user.Role = membership.Role
+	return nil
+}
+
+// withTenantRoles is withTenantRole for a list of users.
+func withTenantRoles(ctx context.Context, db *gorm.DB, users []entity.User) error {
+	organizationID, ok := tenant.FromContext(ctx)
+	if !ok || len(users) == 0 {
+		return nil
+	}
+
+	ids := make([]string, len(users))
+	for i := range users {
+		ids[i] = users[i].ID
+	}
 
-	result := db.Create(user)
+	var memberships []entity.Membership
+	result := db.Where("organization_id = ? AND user_id IN ?", organizationID, ids).Find(&memberships)
 	if result.Error != nil {
-		return errors.Wrap(errors.ErrInternalServer, result.Error)
+		return result.Error
+	}
+
+	roles := make(map[string]constants.Role, len(memberships))
+	for _, membership := range memberships {
+		roles[membership.UserID] = membership.Role
+	}
+	for i := range users {
+		users[i].Role = roles[users[i].ID]
+	}
+	return nil
+}
+
+// Create stores the user. Within an organization the user also becomes a
+// member of it: the requested role is granted as the membership role while
+// the global role stays the default one.
+func (u *userRepository) Create(ctx context.Context, user *entity.User) error {
+	db := conn(ctx, u.db)
+
+	organizationID, scoped := tenant.FromContext(ctx)
+	role := user.Role
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if !scoped {
+			return tx.Create(user).Error
+		}
+
+		user.Role = constants.ROLE_USER
+		defer func() { user.Role = role }()
+		if err := tx.Create(user).Error; err != nil {
+			return err
+		}
+
+		return tx.Omit("Organization", "User").Create(&entity.Membership{
+			OrganizationID: organizationID,
+			UserID:         user.ID,
+			Role:           role,
+		}).Error
+	})
+	if err != nil {
+		return translateError(err)
 	}
 
 	return nil
 }
 
 func (u *userRepository) GetByID(ctx context.Context, id string) (*entity.User, error) {
-	db := u.db.WithContext(ctx)
+	db := conn(ctx, u.db)
 
 	var user entity.User
-	result := db.Where("id = ?", id).First(&user)
+	result := db.Scopes(tenantScope(ctx)).Where("id = ?", id).First(&user)
 
 	if result.Error != nil {
 		if result.Error == gorm.ErrRecordNotFound {
 			return nil, errors.ErrNotFound
 		}
-		return nil, errors.Wrap(errors.ErrInternalServer, result.Error)
+		return nil, translateError(result.Error)
+	}
+
+	if err := withTenantRole(ctx, db, &user); err != nil {
+		return nil, translateError(err)
 	}
 
 	return &user, nil
 }
 
 func (u *userRepository) GetByEmail(ctx context.Context, email string) (*entity.User, error) {
-	db := u.db.WithContext(ctx)
+	db := conn(ctx, u.db)
 
 	var user entity.User
-	result := db.Where("email = ?", email).First(&user)
+	result := db.Scopes(tenantScope(ctx)).Where("email = ?", email).First(&user)
 
 	if result.Error != nil {
 		if result.Error == gorm.ErrRecordNotFound {
 			return nil, errors.ErrNotFound
 		}
-		return nil, errors.Wrap(errors.ErrInternalServer, result.Error)
+		return nil, translateError(result.Error)
+	}
+
+	if err := withTenantRole(ctx, db, &user); err != nil {
+		return nil, translateError(err)
 	}
 
 	return &user, nil
 }
 
-func (u *userRepository) Update(ctx context.Context, user *entity.User) error {
-	db := u.db.WithContext(ctx)
+// userSortColumns are the columns a user listing can be ordered by.
+var userSortColumns = map[string]string{
+	"name":       "users.name",
+	"email":      "users.email",
+	"created_at": "users.created_at",
+	"updated_at": "users.updated_at",
+}
+
+// escapeLike escapes the wildcards of a LIKE pattern.
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
+}
+
+// filterUsers applies the conditions of a user listing to query.
+func filterUsers(ctx context.Context, query *gorm.DB, filter repository.UserFilter) *gorm.DB {
+	query = query.Scopes(tenantScope(ctx))
+	if filter.Role != "" {
+		// Within an organization users are listed by their membership role.
+		if organizationID, ok := tenant.FromContext(ctx); ok {
+			query = query.Where("EXISTS (SELECT 1 FROM memberships WHERE memberships.user_id = users.id AND memberships.organization_id = ? AND memberships.role = ?)", organizationID, filter.Role)
+		} else {
+			query = query.Where("users.role = ?", filter.Role)
+		}
+	}
+	if filter.IsActive != nil {
+		query = query.Where("users.is_active = ?", *filter.IsActive)
+	}
+	if filter.CreatedFrom != nil {
+		query = query.Where("users.created_at >= ?", *filter.CreatedFrom)
+	}
+	if filter.CreatedTo != nil {
+		query = query.Where("users.created_at <= ?", *filter.CreatedTo)
+	}
+	if filter.Search != "" {
+		pattern := "%" + escapeLike(filter.Search) + "%"
+		query = query.Where("users.name ILIKE ? OR users.email ILIKE ?", pattern, pattern)
+	}
+	return query
+}
+
+func (u *userRepository) List(ctx context.Context, filter repository.UserFilter) ([]entity.User, int64, error) {
+	db := conn(ctx, u.db)
+
+	query := filterUsers(ctx, db.Model(&entity.User{}), filter)
+
+	// The filtered query is shared by the count and the page.
+	query = query.Session(&gorm.Session{})
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, translateError(err)
+	}
 
-	result := db.Save(user)
+	column, direction := strings.TrimPrefix(filter.Sort, "-"), "ASC"
+	if strings.HasPrefix(filter.Sort, "-") {
+		direction = "DESC"
+	}
+	sortColumn, ok := userSortColumns[column]
+	if !ok {
+		sortColumn, direction = "users.created_at", "DESC"
+	}
+
+	users := []entity.User{}
+	result := query.
+		Order(sortColumn + " " + direction).
+		Order("users.id").
+		Offset(filter.Offset).
+		Limit(filter.Limit).
+		Find(&users)
 	if result.Error != nil {
-		return errors.Wrap(errors.ErrInternalServer, result.Error)
+		return nil, 0, translateError(result.Error)
+	}
+
+	if err := withTenantRoles(ctx, db, users); err != nil {
+		return nil, 0, translateError(err)
+	}
+
+	return users, total, nil
+}
+
+// ListByCursor returns the page of users matching the filter at the position
+// of page, in order of creation. Sort and Offset of the filter are ignored.
+func (u *userRepository) ListByCursor(ctx context.Context, filter repository.UserFilter, page repository.CursorPage) ([]entity.User, repository.CursorResult, error) {
+	db := conn(ctx, u.db)
+
+	query := filterUsers(ctx, db.Model(&entity.User{}), filter)
+	users, result, err := keysetPaginate(query, "users", page, func(user *entity.User) repository.Cursor {
+		return repository.Cursor{CreatedAt: user.CreatedAt, ID: user.ID}
+	})
+	if err != nil {
+		return nil, result, translateError(err)
+	}
+
+	if err := withTenantRoles(ctx, db, users); err != nil {
+		return nil, result, translateError(err)
+	}
+
+	return users, result, nil
+}
+
+// userSearchThreshold is the word similarity from which a field is a fuzzy
+// match of a search. It is lower than the default of pg_trgm so a typo in a
+// short name still matches.
+const userSearchThreshold = "0.3"
+
+// userSearchRank scores a user against a search by the full-text rank plus
+// the best similarity of a field. It takes the tsquery and then the text of
+// the search three times.
+const userSearchRank = "ts_rank(users.search_vector, to_tsquery('simple', ?)) + " +
+	"greatest(word_similarity(?, users.name), word_similarity(?, users.email), word_similarity(?, users.phone_number))"
+
+// prefixQuery turns free text into a tsquery matching all of its words as
+// prefixes.
+func prefixQuery(text string) string {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	for i, word := range words {
+		words[i] = word + ":*"
+	}
+	return strings.Join(words, " & ")
+}
+
+func (u *userRepository) Search(ctx context.Context, text string, limit int) ([]repository.UserMatch, error) {
+	db := conn(ctx, u.db)
+
+	tsquery := prefixQuery(text)
+
+	var rows []struct {
+		entity.User
+		Rank float64
+	}
+	err := db.Transaction(func(tx *gorm.DB) error {
+		// The threshold is set for the transaction only, and lets the <%
+		// operator use the trigram indexes.
+		if err := tx.Exec("SELECT set_config('pg_trgm.word_similarity_threshold', ?, true)", userSearchThreshold).Error; err != nil {
+			return err
+		}
+
+		return tx.Model(&entity.User{}).
+			Scopes(tenantScope(ctx)).
+			Select("users.*, "+userSearchRank+" AS rank", tsquery, text, text, text).
+			Where("users.search_vector @@ to_tsquery('simple', ?) OR ? <% users.name OR ? <% users.email OR ? <% users.phone_number", tsquery, text, text, text).
+			Order("rank DESC").
+			Order("users.id").
+			Limit(limit).
+			Find(&rows).Error
+	})
+	if err != nil {
+		return nil, translateError(err)
+	}
+
+	users := make([]entity.User, len(rows))
+	for i := range rows {
+		users[i] = rows[i].User
+	}
+	if err := withTenantRoles(ctx, db, users); err != nil {
+		return nil, translateError(err)
+	}
+
+	matches := make([]repository.UserMatch, len(rows))
+	for i := range rows {
+		matches[i] = repository.UserMatch{User: users[i], Rank: rows[i].Rank}
+	}
+	return matches, nil
+}
+
+// Update saves the user if it still has the version it was loaded with, and
+// moves it to the next version. Within an organization the role is written to
+// the membership and the global role is left alone.
+func (u *userRepository) Update(ctx context.Context, user *entity.User) error {
+	db := conn(ctx, u.db)
+
+	organizationID, scoped := tenant.FromContext(ctx)
+
+	// token_version is only ever changed through IncrementTokenVersion so a
+	// stale copy of the user can never undo a revocation. The explicit Select
+	// keeps Save from falling back to an insert when no row matches.
+	omit := []string{"token_version"}
+	if scoped {
+		omit = append(omit, "role")
+	}
+
+	version := user.Version
+	user.Version++
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Scopes(tenantScope(ctx)).
+			Where("version = ?", version).
+			Select("*").
+			Omit(omit...).
+			Save(user)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			// Either the user is gone or someone else saved it first.
+			var count int64
+			if err := tx.Model(&entity.User{}).Scopes(tenantScope(ctx)).Where("id = ?", user.ID).Count(&count).Error; err != nil {
+				return err
+			}
+			if count == 0 {
+				return errors.ErrNotFound
+			}
+			return errors.ErrPreconditionFailed.WithMessage("user was modified concurrently")
+		}
+
+		if !scoped {
+			return nil
+		}
+		return tx.Model(&entity.Membership{}).
+			Where("organization_id = ? AND user_id = ?", organizationID, user.ID).
+			Update("role", user.Role).Error
+	})
+	if err != nil {
+		user.Version = version
+		if errors.Is(err, errors.ErrNotFound.Code) || errors.Is(err, errors.ErrPreconditionFailed.Code) {
+			return err
+		}
+		return translateError(err)
 	}
 
 	return nil
 }
 
+// Delete soft-deletes the user. Within an organization only the membership is
+// removed, and the account itself is deleted once it belongs to no
+// organization anymore.
 func (u *userRepository) Delete(ctx context.Context, id string) error {
-	db := u.db.WithContext(ctx)
+	db := conn(ctx, u.db)
+
+	organizationID, scoped := tenant.FromContext(ctx)
+	if !scoped {
+		result := db.Where("id = ?", id).Delete(&entity.User{})
+		if result.Error != nil {
+			return translateError(result.Error)
+		}
+
+		if result.RowsAffected == 0 {
+			return errors.ErrNotFound
+		}
+
+		return nil
+	}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("organization_id = ? AND user_id = ?", organizationID, id).Delete(&entity.Membership{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errors.ErrNotFound
+		}
+
+		return tx.Where("id = ? AND NOT EXISTS (SELECT 1 FROM memberships WHERE memberships.user_id = users.id)", id).
+			Delete(&entity.User{}).Error
+	})
+	if err != nil {
+		if errors.Is(err, errors.ErrNotFound.Code) {
+			return errors.ErrNotFound
+		}
+		return translateError(err)
+	}
+
+	return nil
+}
 
-	result := db.Delete(&entity.User{}, id)
+func (u *userRepository) ListDeleted(ctx context.Context, offset, limit int) ([]entity.User, int64, error) {
+	db := conn(ctx, u.db)
+
+	query := db.Unscoped().Model(&entity.User{}).Where("users.deleted_at IS NOT NULL").Session(&gorm.Session{})
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, translateError(err)
+	}
+
+	users := []entity.User{}
+	result := query.
+		Order("users.deleted_at DESC").
+		Order("users.id").
+		Offset(offset).
+		Limit(limit).
+		Find(&users)
 	if result.Error != nil {
-		return errors.Wrap(errors.ErrInternalServer, result.Error)
+		return nil, 0, translateError(result.Error)
+	}
+
+	return users, total, nil
+}
+
+func (u *userRepository) GetDeletedByID(ctx context.Context, id string) (*entity.User, error) {
+	db := conn(ctx, u.db)
+
+	var user entity.User
+	result := db.Unscoped().Where("id = ? AND deleted_at IS NOT NULL", id).First(&user)
+
+	if result.Error != nil {
+		if result.Error == gorm.ErrRecordNotFound {
+			return nil, errors.ErrNotFound
+		}
+		return nil, translateError(result.Error)
+	}
+
+	return &user, nil
+}
+
+// Restore undeletes a soft-deleted user and moves it to the next version.
+func (u *userRepository) Restore(ctx context.Context, id string) error {
+	db := conn(ctx, u.db)
+
+	result := db.Unscoped().
+		Model(&entity.User{}).
+		Where("id = ? AND deleted_at IS NOT NULL", id).
+		Updates(map[string]interface{}{
+			"deleted_at": nil,
+			"version":    gorm.Expr("version + 1"),
+			"updated_at": time.Now(),
+		})
+	if result.Error != nil {
+		return translateError(result.Error)
 	}
 
 	if result.RowsAffected == 0 {
@@ -87,3 +484,51 @@ func (u *userRepository) Delete(ctx context.Context, id string) error {
 
 	return nil
 }
+
+func (u *userRepository) Purge(ctx context.Context, id string) error {
+	db := conn(ctx, u.db)
+
+	// Sessions, memberships and recovery codes go along through their
+	// foreign keys.
+	result := db.Unscoped().Where("id = ? AND deleted_at IS NOT NULL", id).Delete(&entity.User{})
+	if result.Error != nil {
+		return translateError(result.Error)
+	}
+
+	if result.RowsAffected == 0 {
+		return errors.ErrNotFound
+	}
+
+	return nil
+}
+
+func (u *userRepository) PurgeDeletedBefore(ctx context.Context, before time.Time) (int64, error) {
+	db := conn(ctx, u.db)
+
+	result := db.Unscoped().Where("deleted_at < ?", before).Delete(&entity.User{})
+	if result.Error != nil {
+		return 0, translateError(result.Error)
+	}
+
+	return result.RowsAffected, nil
+}
+
+func (u *userRepository) IncrementTokenVersion(ctx context.Context, id string) (int, error) {
+	db := conn(ctx, u.db)
+
+	var user entity.User
+	result := db.Model(&user).
+		Clauses(clause.Returning{Columns: []clause.Column{{Name: "token_version"}}}).
+		Scopes(tenantScope(ctx)).
+		Where("id = ?", id).
+		UpdateColumn("token_version", gorm.Expr("token_version + 1"))
+	if result.Error != nil {
+		return 0, translateError(result.Error)
+	}
+
+	if result.RowsAffected == 0 {
+		return 0, errors.ErrNotFound
+	}
+
+	return user.TokenVersion, nil
+}
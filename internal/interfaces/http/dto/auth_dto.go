@@ -1,18 +1,60 @@
 package dto
 
+import "time"
+
 type (
 	LoginRequest struct {
 		Email    string `json:"email" validate:"required,email"`
 		Password string `json:"password" validate:"required,min=6"`
+		Device   string `json:"device,omitempty" validate:"omitempty,max=100"`
+		// OrganizationID selects the organization the session acts in. Without
+		// it the session is not bound to any organization.
+		OrganizationID string `json:"organization_id,omitempty" validate:"omitempty,uuid"`
 	}
 
+	// AuthResponse carries the issued tokens. When the account uses MFA the
+	// first login step only returns MFARequired and a short-lived MFAToken to
+	// exchange at /v1/auth/mfa/verify.
 	AuthResponse struct {
-		Token        string      `json:"token"`
-		RefreshToken string      `json:"refresh_token"`
+		Token        string      `json:"token,omitempty"`
+		RefreshToken string      `json:"refresh_token,omitempty"`
+		MFARequired  bool        `json:"mfa_required,omitempty"`
+		MFAToken     string      `json:"mfa_token,omitempty"`
 		Data         interface{} `json:"data"`
 	}
 
 	RenewalTokenRequest struct {
 		RefreshToken string `json:"refresh_token" validate:"required"`
 	}
+
+	ForgotPasswordRequest struct {
+		Email string `json:"email" validate:"required,email"`
+	}
+
+	ResetPasswordRequest struct {
+		Token    string `json:"token" validate:"required"`
+		Password string `json:"password" validate:"required,min=6"`
+	}
+
+	ResendVerificationRequest struct {
+		Email string `json:"email" validate:"required,email"`
+	}
+
+	// ClientInfo describes the client a request originates from.
+	ClientInfo struct {
+		IPAddress string
+		UserAgent string
+	}
+
+	SessionResponse struct {
+		ID             string    `json:"id"`
+		OrganizationID *string   `json:"organization_id,omitempty"`
+		Device         string    `json:"device"`
+		IPAddress      string    `json:"ip_address"`
+		UserAgent      string    `json:"user_agent"`
+		CreatedAt      time.Time `json:"created_at"`
+		LastSeenAt     time.Time `json:"last_seen_at"`
+		ExpiresAt      time.Time `json:"expires_at"`
+		Current        bool      `json:"current"`
+	}
 )
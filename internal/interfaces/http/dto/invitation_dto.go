@@ -0,0 +1,20 @@
+package dto
+
+import "github.com/HasanNugroho/gin-clean/pkg/constants"
+
+type (
+	CreateInvitationRequest struct {
+		Email string         `json:"email" validate:"required,email"`
+		Role  constants.Role `json:"role" validate:"omitempty,max=50"`
+	}
+
+	// AcceptInvitationRequest completes the account of an invitee. The email
+	// and role come from the invitation. Invitees who already have an account
+	// only send the token.
+	AcceptInvitationRequest struct {
+		Token       string `json:"token" validate:"required"`
+		Name        string `json:"name"`
+		PhoneNumber string `json:"phone_number"`
+		Password    string `json:"password" validate:"omitempty,min=6"`
+	}
+)
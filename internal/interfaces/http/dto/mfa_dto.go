@@ -0,0 +1,23 @@
+package dto
+
+type (
+	MFAEnrollResponse struct {
+		Secret     string `json:"secret"`
+		OTPAuthURI string `json:"otpauth_uri"`
+	}
+
+	MFACodeRequest struct {
+		Code string `json:"code" validate:"required,len=6,numeric"`
+	}
+
+	MFARecoveryCodesResponse struct {
+		RecoveryCodes []string `json:"recovery_codes"`
+	}
+
+	// MFAVerifyRequest takes either a TOTP code or one of the recovery codes.
+	MFAVerifyRequest struct {
+		MFAToken     string `json:"mfa_token" validate:"required"`
+		Code         string `json:"code" validate:"required_without=RecoveryCode,omitempty,len=6,numeric"`
+		RecoveryCode string `json:"recovery_code" validate:"required_without=Code,omitempty,max=32"`
+	}
+)
@@ -0,0 +1,8 @@
+package dto
+
+type (
+	CreateOrganizationRequest struct {
+		Name string `json:"name" validate:"required,max=100"`
+		Slug string `json:"slug" validate:"required,max=63,lowercase,hostname_rfc1123"`
+	}
+)
@@ -0,0 +1,14 @@
+package dto
+
+type (
+	// PolicyExplainRequest describes a hypothetical request to evaluate against
+	// the policy file. Without a role the current user is evaluated.
+	PolicyExplainRequest struct {
+		Role       string                 `json:"role"`
+		Action     string                 `json:"action" validate:"required"`
+		Resource   string                 `json:"resource" validate:"required"`
+		Subject    map[string]interface{} `json:"subject"`
+		Attributes map[string]interface{} `json:"attributes"`
+		Request    map[string]interface{} `json:"request"`
+	}
+)
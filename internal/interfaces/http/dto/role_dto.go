@@ -0,0 +1,12 @@
+package dto
+
+type (
+	CreateRoleRequest struct {
+		Name        string `json:"name" validate:"required,max=50,lowercase,alphanum"`
+		Description string `json:"description" validate:"max=255"`
+	}
+
+	GrantPermissionsRequest struct {
+		Permissions []string `json:"permissions" validate:"required,min=1,dive,required"`
+	}
+)
@@ -1,6 +1,12 @@
 package dto
 
-import "github.com/HasanNugroho/gin-clean/pkg/constants"
+import (
+	"time"
+
+	"github.com/HasanNugroho/gin-clean/internal/domain/entity"
+	"github.com/HasanNugroho/gin-clean/pkg/constants"
+	"github.com/HasanNugroho/gin-clean/pkg/patch"
+)
 
 type (
 	CreateUserRequest struct {
@@ -8,16 +14,36 @@ type (
 		Email       string         `json:"email" validate:"required,email"`
 		PhoneNumber string         `json:"phone_number" validate:"required"`
 		Password    string         `json:"password" validate:"required,min=6"`
-		Role        constants.Role `json:"role" validate:"omitempty,oneof=user admin owner customer"`
+		Role        constants.Role `json:"role" validate:"omitempty,max=50"`
+		// EmailVerified is set by callers that already proved the address,
+		// such as accepted invitations. It cannot be sent by clients.
+		EmailVerified bool `json:"-" swaggerignore:"true"`
 	}
 
+	// UpdateUserRequest replaces the user as a whole. A password is only set
+	// when given. Version is the version of the user the change is based on,
+	// taken from the If-Match header.
 	UpdateUserRequest struct {
-		Name        string         `json:"name,omitempty"`
-		Email       string         `json:"email,omitempty" validate:"omitempty,email"`
-		PhoneNumber string         `json:"phone_number,omitempty"`
+		Name        string         `json:"name" validate:"required"`
+		Email       string         `json:"email" validate:"required,email"`
+		PhoneNumber string         `json:"phone_number" validate:"required"`
 		Password    string         `json:"password,omitempty" validate:"omitempty,min=6"`
-		Role        constants.Role `json:"role,omitempty" validate:"omitempty,oneof=user admin owner customer"`
-		IsActive    bool           `json:"is_active,omitempty"`
+		Role        constants.Role `json:"role" validate:"required,max=50"`
+		IsActive    *bool          `json:"is_active" validate:"required"`
+		Version     int            `json:"-" swaggerignore:"true"`
+	}
+
+	// PatchUserRequest is a JSON merge patch of a user. Omitted members are
+	// left unchanged and none of them may be null. Version is as in
+	// UpdateUserRequest.
+	PatchUserRequest struct {
+		Name        patch.Field[string]         `json:"name" validate:"required,min=1" swaggertype:"string"`
+		Email       patch.Field[string]         `json:"email" validate:"required,email" swaggertype:"string"`
+		PhoneNumber patch.Field[string]         `json:"phone_number" validate:"required,min=1" swaggertype:"string"`
+		Password    patch.Field[string]         `json:"password" validate:"required,min=6" swaggertype:"string"`
+		Role        patch.Field[constants.Role] `json:"role" validate:"required,min=1,max=50" swaggertype:"string"`
+		IsActive    patch.Field[bool]           `json:"is_active" validate:"required" swaggertype:"boolean"`
+		Version     int                         `json:"-" swaggerignore:"true"`
 	}
 
 	RegisterUserRequest struct {
@@ -26,4 +52,64 @@ type (
 		PhoneNumber string `json:"phone_number" validate:"required"`
 		Password    string `json:"password" validate:"required,min=6"`
 	}
+
+	// UpdateProfileRequest is a partial update of the caller's own profile;
+	// omitted fields are left unchanged.
+	UpdateProfileRequest struct {
+		Name        *string `json:"name,omitempty" validate:"omitempty,min=1"`
+		Email       *string `json:"email,omitempty" validate:"omitempty,email"`
+		PhoneNumber *string `json:"phone_number,omitempty" validate:"omitempty,min=1"`
+		// CurrentPassword confirms a change of the email, which controls
+		// password resets.
+		CurrentPassword string `json:"current_password,omitempty" validate:"required_with=Email"`
+	}
+
+	ChangePasswordRequest struct {
+		CurrentPassword string `json:"current_password" validate:"required"`
+		NewPassword     string `json:"new_password" validate:"required,min=6,nefield=CurrentPassword"`
+	}
+
+	// ListUsersQuery are the query parameters of a user listing. Sort takes a
+	// field name, prefixed with "-" for descending order. Cursor pagination,
+	// chosen with pagination=cursor or by passing a cursor, always walks the
+	// users in order of creation and ignores page.
+	ListUsersQuery struct {
+		Page        int        `form:"page" validate:"omitempty,min=1"`
+		Limit       int        `form:"limit" validate:"omitempty,min=1,max=100"`
+		Role        string     `form:"role" validate:"omitempty,max=50"`
+		IsActive    *bool      `form:"is_active"`
+		CreatedFrom *time.Time `form:"created_from" time_format:"2006-01-02T15:04:05Z07:00"`
+		CreatedTo   *time.Time `form:"created_to" time_format:"2006-01-02T15:04:05Z07:00"`
+		Search      string     `form:"search" validate:"omitempty,max=100"`
+		Sort        string     `form:"sort" validate:"omitempty,oneof=name -name email -email created_at -created_at updated_at -updated_at"`
+		Pagination  string     `form:"pagination" validate:"omitempty,oneof=offset cursor"`
+		Cursor      string     `form:"cursor" validate:"omitempty,max=512"`
+	}
+
+	SearchUsersQuery struct {
+		Query string `form:"q" validate:"required,min=2,max=100"`
+		Limit int    `form:"limit" validate:"omitempty,min=1,max=50"`
+	}
+
+	// UserSearchResult is a user found by a search. Highlights holds the
+	// fields where the search occurs, HTML escaped and with the occurrences
+	// wrapped in <mark> tags. Fields matched only by similarity have none.
+	UserSearchResult struct {
+		User       entity.User       `json:"user"`
+		Rank       float64           `json:"rank"`
+		Highlights map[string]string `json:"highlights"`
+	}
+
+	ListDeletedUsersQuery struct {
+		Page  int `form:"page" validate:"omitempty,min=1"`
+		Limit int `form:"limit" validate:"omitempty,min=1,max=100"`
+	}
+
+	// DeletedUserResponse is a soft-deleted user. PurgeAt is when the user is
+	// purged for good, if ever.
+	DeletedUserResponse struct {
+		entity.User
+		DeletedAt time.Time  `json:"deleted_at"`
+		PurgeAt   *time.Time `json:"purge_at,omitempty"`
+	}
 )
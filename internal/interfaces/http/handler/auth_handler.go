@@ -4,6 +4,7 @@ import (
 	"net/http"
 	"strings"
 
+	"github.com/HasanNugroho/gin-clean/internal/domain/entity"
 	"github.com/HasanNugroho/gin-clean/internal/domain/service"
 	"github.com/HasanNugroho/gin-clean/internal/interfaces/http/dto"
 	"github.com/HasanNugroho/gin-clean/internal/interfaces/http/middleware"
@@ -17,41 +18,96 @@ import (
 )
 
 type AuthHandler struct {
-	service  service.AuthService
-	log      *logger.Logger
-	validate *validator.Validate
+	service      service.AuthService
+	verification service.EmailVerificationService
+	mfa          service.MFAService
+	log          *logger.Logger
+	validate     *validator.Validate
 }
 
 func RegisterAuthRoutes(ctn *di.Container) {
 	var (
 		router         = ctn.Get("base-router").(*gin.RouterGroup)
+		verification   = ctn.Get("email-verification-service").(service.EmailVerificationService)
+		mfa            = ctn.Get("mfa-service").(service.MFAService)
 		service        = ctn.Get("auth-service").(service.AuthService)
 		log            = ctn.Get("logger").(*logger.Logger)
 		validate       = ctn.Get("validate").(*validator.Validate)
 		authMiddleware = ctn.Get("auth-middleware").(*middleware.AuthMiddleware)
+		policy         = ctn.Get("policy-middleware").(*middleware.PolicyMiddleware)
 	)
 
-	handler := NewAuthHandler(service, log, validate)
-	authGroup := router.Group("v1/auth")
+	handler := NewAuthHandler(service, verification, mfa, log, validate)
+	authGroup := router.Group("v1/auth", policy.Enforce("auth"))
 	{
+		authGroup.POST("/register", handler.Register)
 		authGroup.POST("/login", handler.Login)
 		authGroup.POST("/refresh", handler.RefreshToken)
-		authGroup.POST("/logout", authMiddleware.AuthRequired(), handler.Logout)
+		authGroup.POST("/logout", authMiddleware.AuthRequiredForMFAEnrollment(), handler.Logout)
+		authGroup.POST("/logout-all", authMiddleware.AuthRequired(), handler.LogoutAll)
+		authGroup.POST("/password/forgot", handler.ForgotPassword)
+		authGroup.POST("/password/reset", handler.ResetPassword)
+		authGroup.GET("/verify-email", handler.VerifyEmail)
+		authGroup.POST("/verify-email/resend", handler.ResendVerification)
+		authGroup.GET("/sessions", authMiddleware.AuthRequired(), handler.ListSessions)
+		authGroup.DELETE("/sessions/:id", authMiddleware.AuthRequired(), handler.RevokeSession)
+		authGroup.POST("/mfa/verify", handler.VerifyMFA)
+		authGroup.POST("/mfa/enroll", authMiddleware.AuthRequiredForMFAEnrollment(), handler.EnrollMFA)
+		authGroup.POST("/mfa/confirm", authMiddleware.AuthRequiredForMFAEnrollment(), handler.ConfirmMFA)
+		authGroup.POST("/mfa/disable", authMiddleware.AuthRequired(), handler.DisableMFA)
+		authGroup.POST("/mfa/recovery-codes", authMiddleware.AuthRequired(), handler.RegenerateRecoveryCodes)
 	}
 	log.Info("Auth routes registered.")
 }
 
-func NewAuthHandler(service service.AuthService, log *logger.Logger, validate *validator.Validate) *AuthHandler {
+func NewAuthHandler(service service.AuthService, verification service.EmailVerificationService, mfa service.MFAService, log *logger.Logger, validate *validator.Validate) *AuthHandler {
 	return &AuthHandler{
-		service:  service,
-		log:      log,
-		validate: validate,
+		service:      service,
+		verification: verification,
+		mfa:          mfa,
+		log:          log,
+		validate:     validate,
 	}
 }
 
+// Register godoc
+// @Summary      Register an account
+// @Description  Self-service sign-up. The account always gets the user role. Depending on REGISTRATION_MODE sign-up may be disabled or invitation only; with REGISTRATION_AUTO_LOGIN the response carries tokens for the new account like a login does, otherwise only the id of the account.
+// @Tags         auth
+// @Accept       json
+// @Produce      json
+// @Param        body  body  dto.RegisterUserRequest  true  "Account details"
+// @Success      201  {object}  response.Response{data=dto.AuthResponse}
+// @Failure      400  {object}  response.Response
+// @Failure      403  {object}  response.Response
+// @Failure      409  {object}  response.Response
+// @Failure      500  {object}  response.Response
+// @Router       /v1/auth/register [post]
+func (h *AuthHandler) Register(ctx *gin.Context) {
+	req, ok := validation.ValidateBody[dto.RegisterUserRequest](ctx, h.validate, h.log)
+	if !ok {
+		return
+	}
+
+	resp, err := h.service.Register(ctx.Request.Context(), *req, clientInfo(ctx))
+	if err != nil {
+		h.log.Error("Registration failed", err)
+		response.SendError(ctx, errors.StatusCode(err), "Registration failed", err.Error())
+		return
+	}
+
+	// Without a session there is nothing to return but the account.
+	if resp.Token == "" {
+		response.SendSuccess(ctx, http.StatusCreated, "Registration successful", resp.Data)
+		return
+	}
+
+	response.SendSuccess(ctx, http.StatusCreated, "Registration successful", resp)
+}
+
 // Login godoc
 // @Summary      User login
-// @Description  Authenticate user and return access token
+// @Description  Authenticate user and return access token. Users with MFA enabled receive an mfa_token instead, to be exchanged at /v1/auth/mfa/verify.
 // @Tags         auth
 // @Accept       json
 // @Produce      json
@@ -59,6 +115,7 @@ func NewAuthHandler(service service.AuthService, log *logger.Logger, validate *v
 // @Success      200  {object}  response.Response{data=dto.AuthResponse}
 // @Failure      400  {object}  response.Response
 // @Failure      401  {object}  response.Response
+// @Failure      403  {object}  response.Response
 // @Failure      500  {object}  response.Response
 // @Router       /v1/auth/login [post]
 func (h *AuthHandler) Login(ctx *gin.Context) {
@@ -67,7 +124,7 @@ func (h *AuthHandler) Login(ctx *gin.Context) {
 		return
 	}
 
-	resp, err := h.service.Login(ctx.Request.Context(), *req)
+	resp, err := h.service.Login(ctx.Request.Context(), *req, clientInfo(ctx))
 	if err != nil {
 		h.log.Error("Login failed", err)
 		response.SendError(ctx, errors.StatusCode(err), "Login failed", err.Error())
@@ -79,7 +136,7 @@ func (h *AuthHandler) Login(ctx *gin.Context) {
 
 // RefreshToken godoc
 // @Summary      Refresh access token
-// @Description  Use refresh token to obtain new access token
+// @Description  Use refresh token to obtain new access token. Refresh tokens are single-use: the presented token is rotated, and replaying it revokes the whole token family.
 // @Tags         auth
 // @Accept       json
 // @Produce      json
@@ -95,7 +152,7 @@ func (h *AuthHandler) RefreshToken(ctx *gin.Context) {
 		return
 	}
 
-	resp, err := h.service.RefreshToken(ctx.Request.Context(), *req)
+	resp, err := h.service.RefreshToken(ctx.Request.Context(), *req, clientInfo(ctx))
 	if err != nil {
 		h.log.Error("Refresh token failed", err)
 		response.SendError(ctx, errors.StatusCode(err), "Refresh token failed", err.Error())
@@ -139,3 +196,338 @@ func (h *AuthHandler) Logout(ctx *gin.Context) {
 
 	response.SendSuccess(ctx, http.StatusOK, "logout successful", nil)
 }
+
+// LogoutAll godoc
+// @Summary      Logout everywhere
+// @Description  End every session of the current user and revoke all issued access & refresh tokens
+// @Tags         auth
+// @Produce      json
+// @Success      200  {object}  response.Response{data=string}
+// @Failure      401  {object}  response.Response
+// @Failure      500  {object}  response.Response
+// @Router       /v1/auth/logout-all [post]
+// @Security     BearerAuth
+func (h *AuthHandler) LogoutAll(ctx *gin.Context) {
+	user := ctx.MustGet("user").(*entity.User)
+
+	if err := h.service.LogoutAll(ctx.Request.Context(), user.ID); err != nil {
+		h.log.Error("Logout all failed", err, "user_id", user.ID)
+		response.SendError(ctx, errors.StatusCode(err), "logout failed", err.Error())
+		return
+	}
+
+	response.SendSuccess(ctx, http.StatusOK, "logged out from all sessions", nil)
+}
+
+// ForgotPassword godoc
+// @Summary      Request a password reset
+// @Description  Send a single-use password reset link to the email if it belongs to an account. The response is the same whether or not the account exists.
+// @Tags         auth
+// @Accept       json
+// @Produce      json
+// @Param        body  body  dto.ForgotPasswordRequest  true  "Account email"
+// @Success      200  {object}  response.Response{data=string}
+// @Failure      400  {object}  response.Response
+// @Failure      500  {object}  response.Response
+// @Router       /v1/auth/password/forgot [post]
+func (h *AuthHandler) ForgotPassword(ctx *gin.Context) {
+	req, ok := validation.ValidateBody[dto.ForgotPasswordRequest](ctx, h.validate, h.log)
+	if !ok {
+		return
+	}
+
+	if err := h.service.ForgotPassword(ctx.Request.Context(), *req); err != nil {
+		h.log.Error("Forgot password failed", err)
+		response.SendError(ctx, errors.StatusCode(err), "Failed to request password reset", err.Error())
+		return
+	}
+
+	response.SendSuccess(ctx, http.StatusOK, "If the email is registered, a password reset link has been sent", nil)
+}
+
+// ResetPassword godoc
+// @Summary      Reset password
+// @Description  Set a new password using a reset token. All existing sessions of the user are ended. Deactivated users cannot reset their password.
+// @Tags         auth
+// @Accept       json
+// @Produce      json
+// @Param        body  body  dto.ResetPasswordRequest  true  "Reset token and new password"
+// @Success      200  {object}  response.Response{data=string}
+// @Failure      400  {object}  response.Response
+// @Failure      403  {object}  response.Response
+// @Failure      500  {object}  response.Response
+// @Router       /v1/auth/password/reset [post]
+func (h *AuthHandler) ResetPassword(ctx *gin.Context) {
+	req, ok := validation.ValidateBody[dto.ResetPasswordRequest](ctx, h.validate, h.log)
+	if !ok {
+		return
+	}
+
+	if err := h.service.ResetPassword(ctx.Request.Context(), *req); err != nil {
+		h.log.Error("Reset password failed", err)
+		response.SendError(ctx, errors.StatusCode(err), "Failed to reset password", err.Error())
+		return
+	}
+
+	response.SendSuccess(ctx, http.StatusOK, "Password reset successfully", nil)
+}
+
+// VerifyEmail godoc
+// @Summary      Verify email address
+// @Description  Confirm ownership of an email address using the signed link sent by mail
+// @Tags         auth
+// @Produce      json
+// @Param        token  query  string  true  "Verification token"
+// @Success      200  {object}  response.Response{data=string}
+// @Failure      400  {object}  response.Response
+// @Failure      500  {object}  response.Response
+// @Router       /v1/auth/verify-email [get]
+func (h *AuthHandler) VerifyEmail(ctx *gin.Context) {
+	token := ctx.Query("token")
+	if token == "" {
+		response.SendError(ctx, http.StatusBadRequest, "missing verification token", nil)
+		return
+	}
+
+	if err := h.verification.VerifyEmail(ctx.Request.Context(), token); err != nil {
+		h.log.Error("Verify email failed", err)
+		response.SendError(ctx, errors.StatusCode(err), "Failed to verify email", err.Error())
+		return
+	}
+
+	response.SendSuccess(ctx, http.StatusOK, "Email verified successfully", nil)
+}
+
+// ResendVerification godoc
+// @Summary      Resend verification email
+// @Description  Send a new verification link to an unverified account. Throttled per email address; the response is the same whether or not the account exists.
+// @Tags         auth
+// @Accept       json
+// @Produce      json
+// @Param        body  body  dto.ResendVerificationRequest  true  "Account email"
+// @Success      200  {object}  response.Response{data=string}
+// @Failure      400  {object}  response.Response
+// @Failure      429  {object}  response.Response
+// @Failure      500  {object}  response.Response
+// @Router       /v1/auth/verify-email/resend [post]
+func (h *AuthHandler) ResendVerification(ctx *gin.Context) {
+	req, ok := validation.ValidateBody[dto.ResendVerificationRequest](ctx, h.validate, h.log)
+	if !ok {
+		return
+	}
+
+	if err := h.verification.ResendVerification(ctx.Request.Context(), *req); err != nil {
+		h.log.Error("Resend verification failed", err)
+		response.SendError(ctx, errors.StatusCode(err), "Failed to resend verification email", err.Error())
+		return
+	}
+
+	response.SendSuccess(ctx, http.StatusOK, "If the email is registered and unverified, a verification link has been sent", nil)
+}
+
+// ListSessions godoc
+// @Summary      List active sessions
+// @Description  List the devices the current user is logged in on
+// @Tags         auth
+// @Produce      json
+// @Success      200  {object}  response.Response{data=[]dto.SessionResponse}
+// @Failure      401  {object}  response.Response
+// @Failure      500  {object}  response.Response
+// @Router       /v1/auth/sessions [get]
+// @Security     BearerAuth
+func (h *AuthHandler) ListSessions(ctx *gin.Context) {
+	user := ctx.MustGet("user").(*entity.User)
+
+	sessions, err := h.service.ListSessions(ctx.Request.Context(), user.ID, ctx.GetString("session_id"))
+	if err != nil {
+		h.log.Error("List sessions failed", err, "user_id", user.ID)
+		response.SendError(ctx, errors.StatusCode(err), "Failed to list sessions", err.Error())
+		return
+	}
+
+	response.SendSuccess(ctx, http.StatusOK, "Sessions fetched successfully", sessions)
+}
+
+// RevokeSession godoc
+// @Summary      Revoke a session
+// @Description  Log the current user out of a single device
+// @Tags         auth
+// @Produce      json
+// @Param        id   path      string  true  "Session ID"
+// @Success      200  {object}  response.Response{data=map[string]string}
+// @Failure      400  {object}  response.Response
+// @Failure      401  {object}  response.Response
+// @Failure      404  {object}  response.Response
+// @Failure      500  {object}  response.Response
+// @Router       /v1/auth/sessions/{id} [delete]
+// @Security     BearerAuth
+func (h *AuthHandler) RevokeSession(ctx *gin.Context) {
+	id := ctx.Param("id")
+	if err := h.validate.Var(id, "required,uuid"); err != nil {
+		response.SendError(ctx, http.StatusBadRequest, "Invalid UUID", err.Error())
+		return
+	}
+
+	user := ctx.MustGet("user").(*entity.User)
+	if err := h.service.RevokeSession(ctx.Request.Context(), user.ID, id); err != nil {
+		h.log.Error("Revoke session failed", err, "session_id", id)
+		response.SendError(ctx, errors.StatusCode(err), "Failed to revoke session", err.Error())
+		return
+	}
+
+	response.SendSuccess(ctx, http.StatusOK, "Session revoked successfully", map[string]string{"id": id})
+}
+
+// VerifyMFA godoc
+// @Summary      Complete MFA login
+// @Description  Exchange the MFA token returned by login and a TOTP code or a recovery code for access & refresh tokens. Each MFA token allows a single attempt.
+// @Tags         auth
+// @Accept       json
+// @Produce      json
+// @Param        body  body  dto.MFAVerifyRequest  true  "MFA token and code"
+// @Success      200  {object}  response.Response{data=dto.AuthResponse}
+// @Failure      400  {object}  response.Response
+// @Failure      401  {object}  response.Response
+// @Failure      429  {object}  response.Response
+// @Failure      500  {object}  response.Response
+// @Router       /v1/auth/mfa/verify [post]
+func (h *AuthHandler) VerifyMFA(ctx *gin.Context) {
+	req, ok := validation.ValidateBody[dto.MFAVerifyRequest](ctx, h.validate, h.log)
+	if !ok {
+		return
+	}
+
+	resp, err := h.service.VerifyMFA(ctx.Request.Context(), *req, clientInfo(ctx))
+	if err != nil {
+		h.log.Error("MFA verification failed", err)
+		response.SendError(ctx, errors.StatusCode(err), "MFA verification failed", err.Error())
+		return
+	}
+
+	response.SendSuccess(ctx, http.StatusOK, "Login successful", resp)
+}
+
+// EnrollMFA godoc
+// @Summary      Start MFA enrollment
+// @Description  Generate a TOTP secret for the current user. MFA is enabled once the secret is confirmed with a code.
+// @Tags         auth
+// @Produce      json
+// @Success      200  {object}  response.Response{data=dto.MFAEnrollResponse}
+// @Failure      401  {object}  response.Response
+// @Failure      409  {object}  response.Response
+// @Failure      500  {object}  response.Response
+// @Router       /v1/auth/mfa/enroll [post]
+// @Security     BearerAuth
+func (h *AuthHandler) EnrollMFA(ctx *gin.Context) {
+	user := ctx.MustGet("user").(*entity.User)
+
+	resp, err := h.mfa.Enroll(ctx.Request.Context(), user.ID)
+	if err != nil {
+		h.log.Error("MFA enrollment failed", err, "user_id", user.ID)
+		response.SendError(ctx, errors.StatusCode(err), "Failed to start MFA enrollment", err.Error())
+		return
+	}
+
+	response.SendSuccess(ctx, http.StatusOK, "Scan the secret with an authenticator app and confirm it with a code", resp)
+}
+
+// ConfirmMFA godoc
+// @Summary      Confirm MFA enrollment
+// @Description  Enable MFA for the current user with a code generated from the enrolled secret. The response contains one-time recovery codes that are not shown again.
+// @Tags         auth
+// @Accept       json
+// @Produce      json
+// @Param        body  body  dto.MFACodeRequest  true  "TOTP code"
+// @Success      200  {object}  response.Response{data=dto.MFARecoveryCodesResponse}
+// @Failure      400  {object}  response.Response
+// @Failure      401  {object}  response.Response
+// @Failure      429  {object}  response.Response
+// @Failure      500  {object}  response.Response
+// @Router       /v1/auth/mfa/confirm [post]
+// @Security     BearerAuth
+func (h *AuthHandler) ConfirmMFA(ctx *gin.Context) {
+	req, ok := validation.ValidateBody[dto.MFACodeRequest](ctx, h.validate, h.log)
+	if !ok {
+		return
+	}
+
+	user := ctx.MustGet("user").(*entity.User)
+	resp, err := h.mfa.Confirm(ctx.Request.Context(), user.ID, *req)
+	if err != nil {
+		h.log.Error("MFA confirmation failed", err, "user_id", user.ID)
+		response.SendError(ctx, errors.StatusCode(err), "Failed to confirm MFA", err.Error())
+		return
+	}
+
+	response.SendSuccess(ctx, http.StatusOK, "MFA enabled successfully", resp)
+}
+
+// RegenerateRecoveryCodes godoc
+// @Summary      Regenerate MFA recovery codes
+// @Description  Replace the current user's recovery codes with a new set. Codes of the previous set stop working.
+// @Tags         auth
+// @Accept       json
+// @Produce      json
+// @Param        body  body  dto.MFACodeRequest  true  "TOTP code"
+// @Success      200  {object}  response.Response{data=dto.MFARecoveryCodesResponse}
+// @Failure      400  {object}  response.Response
+// @Failure      401  {object}  response.Response
+// @Failure      429  {object}  response.Response
+// @Failure      500  {object}  response.Response
+// @Router       /v1/auth/mfa/recovery-codes [post]
+// @Security     BearerAuth
+func (h *AuthHandler) RegenerateRecoveryCodes(ctx *gin.Context) {
+	req, ok := validation.ValidateBody[dto.MFACodeRequest](ctx, h.validate, h.log)
+	if !ok {
+		return
+	}
+
+	user := ctx.MustGet("user").(*entity.User)
+	resp, err := h.mfa.RegenerateRecoveryCodes(ctx.Request.Context(), user.ID, *req)
+	if err != nil {
+		h.log.Error("Regenerate recovery codes failed", err, "user_id", user.ID)
+		response.SendError(ctx, errors.StatusCode(err), "Failed to regenerate recovery codes", err.Error())
+		return
+	}
+
+	response.SendSuccess(ctx, http.StatusOK, "Recovery codes regenerated successfully", resp)
+}
+
+// DisableMFA godoc
+// @Summary      Disable MFA
+// @Description  Turn MFA off for the current user. Not allowed for roles that require MFA.
+// @Tags         auth
+// @Accept       json
+// @Produce      json
+// @Param        body  body  dto.MFACodeRequest  true  "TOTP code"
+// @Success      200  {object}  response.Response{data=string}
+// @Failure      400  {object}  response.Response
+// @Failure      401  {object}  response.Response
+// @Failure      403  {object}  response.Response
+// @Failure      429  {object}  response.Response
+// @Failure      500  {object}  response.Response
+// @Router       /v1/auth/mfa/disable [post]
+// @Security     BearerAuth
+func (h *AuthHandler) DisableMFA(ctx *gin.Context) {
+	req, ok := validation.ValidateBody[dto.MFACodeRequest](ctx, h.validate, h.log)
+	if !ok {
+		return
+	}
+
+	user := ctx.MustGet("user").(*entity.User)
+	if err := h.mfa.Disable(ctx.Request.Context(), user.ID, *req); err != nil {
+		h.log.Error("MFA disable failed", err, "user_id", user.ID)
+		response.SendError(ctx, errors.StatusCode(err), "Failed to disable MFA", err.Error())
+		return
+	}
+
+	response.SendSuccess(ctx, http.StatusOK, "MFA disabled successfully", nil)
+}
+
+// clientInfo extracts the caller's network details recorded on sessions.
+func clientInfo(ctx *gin.Context) dto.ClientInfo {
+	return dto.ClientInfo{
+		IPAddress: ctx.ClientIP(),
+		UserAgent: ctx.Request.UserAgent(),
+	}
+}
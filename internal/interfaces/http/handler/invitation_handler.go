@@ -0,0 +1,205 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/HasanNugroho/gin-clean/internal/domain/entity"
+	"github.com/HasanNugroho/gin-clean/internal/domain/service"
+	"github.com/HasanNugroho/gin-clean/internal/interfaces/http/dto"
+	"github.com/HasanNugroho/gin-clean/internal/interfaces/http/middleware"
+	"github.com/HasanNugroho/gin-clean/pkg/constants"
+	"github.com/HasanNugroho/gin-clean/pkg/errors"
+	"github.com/HasanNugroho/gin-clean/pkg/logger"
+	"github.com/HasanNugroho/gin-clean/pkg/response"
+	"github.com/HasanNugroho/gin-clean/pkg/validation"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/sarulabs/di/v2"
+)
+
+type InvitationHandler struct {
+	service  service.InvitationService
+	log      *logger.Logger
+	validate *validator.Validate
+}
+
+func RegisterInvitationRoutes(ctn *di.Container) {
+	var (
+		router         = ctn.Get("base-router").(*gin.RouterGroup)
+		service        = ctn.Get("invitation-service").(service.InvitationService)
+		log            = ctn.Get("logger").(*logger.Logger)
+		validate       = ctn.Get("validate").(*validator.Validate)
+		authMiddleware = ctn.Get("auth-middleware").(*middleware.AuthMiddleware)
+		permission     = ctn.Get("permission-middleware").(*middleware.PermissionMiddleware)
+		policy         = ctn.Get("policy-middleware").(*middleware.PolicyMiddleware)
+	)
+
+	handler := NewInvitationHandler(service, log, validate)
+	invitationGroup := router.Group("v1/invitations", policy.Enforce("invitations"), authMiddleware.AuthRequired(), permission.RequirePermission(constants.PERMISSION_INVITATIONS_MANAGE))
+	{
+		invitationGroup.POST("", handler.Create)
+		invitationGroup.GET("", handler.ListPending)
+		invitationGroup.POST("/:id/resend", handler.Resend)
+		invitationGroup.DELETE("/:id", handler.Revoke)
+	}
+	router.POST("v1/auth/invitations/accept", policy.Enforce("auth"), handler.Accept)
+	log.Info("Invitation routes registered.")
+}
+
+func NewInvitationHandler(service service.InvitationService, log *logger.Logger, validate *validator.Validate) *InvitationHandler {
+	return &InvitationHandler{service: service, log: log, validate: validate}
+}
+
+func (h *InvitationHandler) validateUUID(c *gin.Context, paramName string) (string, bool) {
+	id := c.Param(paramName)
+	if err := h.validate.Var(id, "required,uuid"); err != nil {
+		response.SendError(c, http.StatusBadRequest, "Invalid UUID", err.Error())
+		return "", false
+	}
+	return id, true
+}
+
+// Create godoc
+// @Summary      Invite a user
+// @Description  Mail a single-use invitation link to an email without an account. The invitee gets the given role, user by default, which takes the roles:manage permission or every permission of the role. Invitations sent with a token acting in an organization make the invitee a member of it, and may also go to existing accounts, which join the organization once they accept.
+// @Tags         Invitations
+// @Accept       json
+// @Produce      json
+// @Param        body  body      dto.CreateInvitationRequest  true  "Invitation"
+// @Success      201   {object}  response.Response{data=entity.Invitation}
+// @Failure      400   {object}  response.Response
+// @Failure      401   {object}  response.Response
+// @Failure      403   {object}  response.Response
+// @Failure      409   {object}  response.Response
+// @Failure      500   {object}  response.Response
+// @Router       /v1/invitations [post]
+// @Security     BearerAuth
+func (h *InvitationHandler) Create(c *gin.Context) {
+	req, ok := validation.ValidateBody[dto.CreateInvitationRequest](c, h.validate, h.log)
+	if !ok {
+		return
+	}
+
+	inviter := c.MustGet("user").(*entity.User)
+	invitation, err := h.service.Invite(c.Request.Context(), inviter, req)
+	if err != nil {
+		h.log.Error("Failed to create invitation", err)
+		response.SendError(c, errors.StatusCode(err), "Failed to create invitation", err.Error())
+		return
+	}
+
+	h.log.Info("Invitation created", "invitation_id", invitation.ID, "invited_by", inviter.ID)
+	response.SendSuccess(c, http.StatusCreated, "Invitation sent successfully", invitation)
+}
+
+// ListPending godoc
+// @Summary      List pending invitations
+// @Description  List the invitations that were neither accepted, revoked nor expired
+// @Tags         Invitations
+// @Produce      json
+// @Success      200  {object}  response.Response{data=[]entity.Invitation}
+// @Failure      401  {object}  response.Response
+// @Failure      403  {object}  response.Response
+// @Failure      500  {object}  response.Response
+// @Router       /v1/invitations [get]
+// @Security     BearerAuth
+func (h *InvitationHandler) ListPending(c *gin.Context) {
+	invitations, err := h.service.ListPending(c.Request.Context())
+	if err != nil {
+		h.log.Error("Failed to list invitations", err)
+		response.SendError(c, errors.StatusCode(err), "Failed to list invitations", err.Error())
+		return
+	}
+
+	response.SendSuccess(c, http.StatusOK, "Invitations fetched successfully", invitations)
+}
+
+// Resend godoc
+// @Summary      Resend invitation
+// @Description  Mail a fresh link and extend the invitation, expired ones included. Previously sent links stop working.
+// @Tags         Invitations
+// @Produce      json
+// @Param        id   path      string  true  "Invitation ID"
+// @Success      200  {object}  response.Response{data=map[string]string}
+// @Failure      400  {object}  response.Response
+// @Failure      401  {object}  response.Response
+// @Failure      403  {object}  response.Response
+// @Failure      404  {object}  response.Response
+// @Failure      409  {object}  response.Response
+// @Failure      500  {object}  response.Response
+// @Router       /v1/invitations/{id}/resend [post]
+// @Security     BearerAuth
+func (h *InvitationHandler) Resend(c *gin.Context) {
+	id, ok := h.validateUUID(c, "id")
+	if !ok {
+		return
+	}
+
+	if err := h.service.Resend(c.Request.Context(), id); err != nil {
+		h.log.Error("Failed to resend invitation", err, "invitation_id", id)
+		response.SendError(c, errors.StatusCode(err), "Failed to resend invitation", err.Error())
+		return
+	}
+
+	h.log.Info("Invitation resent", "invitation_id", id)
+	response.SendSuccess(c, http.StatusOK, "Invitation resent successfully", map[string]string{"id": id})
+}
+
+// Revoke godoc
+// @Summary      Revoke invitation
+// @Description  Invalidate a pending invitation
+// @Tags         Invitations
+// @Produce      json
+// @Param        id   path      string  true  "Invitation ID"
+// @Success      200  {object}  response.Response{data=map[string]string}
+// @Failure      400  {object}  response.Response
+// @Failure      401  {object}  response.Response
+// @Failure      403  {object}  response.Response
+// @Failure      404  {object}  response.Response
+// @Failure      500  {object}  response.Response
+// @Router       /v1/invitations/{id} [delete]
+// @Security     BearerAuth
+func (h *InvitationHandler) Revoke(c *gin.Context) {
+	id, ok := h.validateUUID(c, "id")
+	if !ok {
+		return
+	}
+
+	if err := h.service.Revoke(c.Request.Context(), id); err != nil {
+		h.log.Error("Failed to revoke invitation", err, "invitation_id", id)
+		response.SendError(c, errors.StatusCode(err), "Failed to revoke invitation", err.Error())
+		return
+	}
+
+	h.log.Info("Invitation revoked", "invitation_id", id)
+	response.SendSuccess(c, http.StatusOK, "Invitation revoked successfully", map[string]string{"id": id})
+}
+
+// Accept godoc
+// @Summary      Accept invitation
+// @Description  Create the account of an invitee from the token of their invitation link. The email and role come from the invitation, and the email counts as verified. Invitees who already have an account only send the token and join the organization of the invitation.
+// @Tags         Auth
+// @Accept       json
+// @Produce      json
+// @Param        body  body      dto.AcceptInvitationRequest  true  "Account details"
+// @Success      201   {object}  response.Response{data=map[string]string}
+// @Failure      400   {object}  response.Response
+// @Failure      409   {object}  response.Response
+// @Failure      500   {object}  response.Response
+// @Router       /v1/auth/invitations/accept [post]
+func (h *InvitationHandler) Accept(c *gin.Context) {
+	req, ok := validation.ValidateBody[dto.AcceptInvitationRequest](c, h.validate, h.log)
+	if !ok {
+		return
+	}
+
+	email, err := h.service.Accept(c.Request.Context(), req)
+	if err != nil {
+		h.log.Error("Failed to accept invitation", err)
+		response.SendError(c, errors.StatusCode(err), "Failed to accept invitation", err.Error())
+		return
+	}
+
+	h.log.Info("Invitation accepted", "email", email)
+	response.SendSuccess(c, http.StatusCreated, "Invitation accepted successfully", map[string]string{"email": email})
+}
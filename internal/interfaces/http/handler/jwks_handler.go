@@ -0,0 +1,39 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/HasanNugroho/gin-clean/pkg/jwt"
+	"github.com/HasanNugroho/gin-clean/pkg/logger"
+	"github.com/gin-gonic/gin"
+	"github.com/sarulabs/di/v2"
+)
+
+type JWKSHandler struct {
+	jwt *jwt.TokenGenerator
+}
+
+// RegisterJWKSRoutes publishes the token verification keys at the server root
+// so other services can verify access tokens without sharing a secret.
+func RegisterJWKSRoutes(ctn *di.Container) {
+	var (
+		engine = ctn.Get("engine").(*gin.Engine)
+		jwt    = ctn.Get("jwt").(*jwt.TokenGenerator)
+		log    = ctn.Get("logger").(*logger.Logger)
+	)
+
+	handler := NewJWKSHandler(jwt)
+	engine.GET("/.well-known/jwks.json", handler.GetJWKS)
+	log.Info("JWKS routes registered.")
+}
+
+func NewJWKSHandler(jwt *jwt.TokenGenerator) *JWKSHandler {
+	return &JWKSHandler{jwt: jwt}
+}
+
+// GetJWKS serves the public signing keys as a JSON Web Key Set (RFC 7517).
+// The set is empty when tokens are signed with a shared HS256 secret.
+func (h *JWKSHandler) GetJWKS(c *gin.Context) {
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, h.jwt.JWKS())
+}
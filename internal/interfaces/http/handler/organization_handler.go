@@ -0,0 +1,168 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/HasanNugroho/gin-clean/internal/domain/entity"
+	"github.com/HasanNugroho/gin-clean/internal/domain/service"
+	"github.com/HasanNugroho/gin-clean/internal/interfaces/http/dto"
+	"github.com/HasanNugroho/gin-clean/internal/interfaces/http/middleware"
+	"github.com/HasanNugroho/gin-clean/pkg/constants"
+	"github.com/HasanNugroho/gin-clean/pkg/errors"
+	"github.com/HasanNugroho/gin-clean/pkg/logger"
+	"github.com/HasanNugroho/gin-clean/pkg/response"
+	"github.com/HasanNugroho/gin-clean/pkg/validation"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/sarulabs/di/v2"
+)
+
+type OrganizationHandler struct {
+	service  service.OrganizationService
+	log      *logger.Logger
+	validate *validator.Validate
+}
+
+func RegisterOrganizationRoutes(ctn *di.Container) {
+	var (
+		router         = ctn.Get("base-router").(*gin.RouterGroup)
+		service        = ctn.Get("organization-service").(service.OrganizationService)
+		log            = ctn.Get("logger").(*logger.Logger)
+		validate       = ctn.Get("validate").(*validator.Validate)
+		authMiddleware = ctn.Get("auth-middleware").(*middleware.AuthMiddleware)
+		permission     = ctn.Get("permission-middleware").(*middleware.PermissionMiddleware)
+		policy         = ctn.Get("policy-middleware").(*middleware.PolicyMiddleware)
+	)
+
+	handler := NewOrganizationHandler(service, log, validate)
+	organizationGroup := router.Group("v1/organizations", policy.Enforce("organizations"), authMiddleware.AuthRequired())
+	{
+		organizationGroup.POST("", permission.RequirePermission(constants.PERMISSION_ORGANIZATIONS_CREATE), handler.Create)
+		organizationGroup.GET("", handler.ListMine)
+
+		// Members are only managed with a token acting in the organization,
+		// and join it by accepting an invitation.
+		memberGroup := organizationGroup.Group("/:id/members", authMiddleware.RequireTenant("id"))
+		memberGroup.GET("", handler.ListMembers)
+		memberGroup.DELETE("/:user_id", permission.RequirePermission(constants.PERMISSION_ORGANIZATIONS_MANAGE), handler.RemoveMember)
+	}
+	log.Info("Organization routes registered.")
+}
+
+func NewOrganizationHandler(service service.OrganizationService, log *logger.Logger, validate *validator.Validate) *OrganizationHandler {
+	return &OrganizationHandler{service: service, log: log, validate: validate}
+}
+
+// Create godoc
+// @Summary      Create organization
+// @Description  Create an organization. The caller becomes its first member with the admin role.
+// @Tags         Organizations
+// @Accept       json
+// @Produce      json
+// @Param        body  body      dto.CreateOrganizationRequest  true  "Organization"
+// @Success      201   {object}  response.Response{data=entity.Organization}
+// @Failure      400   {object}  response.Response
+// @Failure      401   {object}  response.Response
+// @Failure      403   {object}  response.Response
+// @Failure      409   {object}  response.Response
+// @Failure      500   {object}  response.Response
+// @Router       /v1/organizations [post]
+// @Security     BearerAuth
+func (h *OrganizationHandler) Create(c *gin.Context) {
+	req, ok := validation.ValidateBody[dto.CreateOrganizationRequest](c, h.validate, h.log)
+	if !ok {
+		return
+	}
+
+	user := c.MustGet("user").(*entity.User)
+	organization, err := h.service.Create(c.Request.Context(), user, req)
+	if err != nil {
+		h.log.Error("Failed to create organization", err)
+		response.SendError(c, errors.StatusCode(err), "Failed to create organization", err.Error())
+		return
+	}
+
+	h.log.Info("Organization created", "organization_id", organization.ID, "user_id", user.ID)
+	response.SendSuccess(c, http.StatusCreated, "Organization created successfully", organization)
+}
+
+// ListMine godoc
+// @Summary      List my organizations
+// @Description  List the organizations the authenticated user belongs to, with their role in each
+// @Tags         Organizations
+// @Produce      json
+// @Success      200  {object}  response.Response{data=[]entity.Membership}
+// @Failure      401  {object}  response.Response
+// @Failure      500  {object}  response.Response
+// @Router       /v1/organizations [get]
+// @Security     BearerAuth
+func (h *OrganizationHandler) ListMine(c *gin.Context) {
+	user := c.MustGet("user").(*entity.User)
+
+	memberships, err := h.service.ListMine(c.Request.Context(), user.ID)
+	if err != nil {
+		h.log.Error("Failed to list organizations", err, "user_id", user.ID)
+		response.SendError(c, errors.StatusCode(err), "Failed to list organizations", err.Error())
+		return
+	}
+
+	response.SendSuccess(c, http.StatusOK, "Organizations fetched successfully", memberships)
+}
+
+// ListMembers godoc
+// @Summary      List members
+// @Description  List the members of the organization the token acts in
+// @Tags         Organizations
+// @Produce      json
+// @Param        id   path      string  true  "Organization ID"
+// @Success      200  {object}  response.Response{data=[]entity.Membership}
+// @Failure      401  {object}  response.Response
+// @Failure      403  {object}  response.Response
+// @Failure      500  {object}  response.Response
+// @Router       /v1/organizations/{id}/members [get]
+// @Security     BearerAuth
+func (h *OrganizationHandler) ListMembers(c *gin.Context) {
+	id := c.Param("id")
+
+	memberships, err := h.service.ListMembers(c.Request.Context(), id)
+	if err != nil {
+		h.log.Error("Failed to list members", err, "organization_id", id)
+		response.SendError(c, errors.StatusCode(err), "Failed to list members", err.Error())
+		return
+	}
+
+	response.SendSuccess(c, http.StatusOK, "Members fetched successfully", memberships)
+}
+
+// RemoveMember godoc
+// @Summary      Remove member
+// @Description  Take away the access of a user to the organization. The account itself is kept.
+// @Tags         Organizations
+// @Produce      json
+// @Param        id       path      string  true  "Organization ID"
+// @Param        user_id  path      string  true  "User ID"
+// @Success      200      {object}  response.Response{data=map[string]string}
+// @Failure      400      {object}  response.Response
+// @Failure      401      {object}  response.Response
+// @Failure      403      {object}  response.Response
+// @Failure      404      {object}  response.Response
+// @Failure      500      {object}  response.Response
+// @Router       /v1/organizations/{id}/members/{user_id} [delete]
+// @Security     BearerAuth
+func (h *OrganizationHandler) RemoveMember(c *gin.Context) {
+	id := c.Param("id")
+	userID := c.Param("user_id")
+	if err := h.validate.Var(userID, "required,uuid"); err != nil {
+		response.SendError(c, http.StatusBadRequest, "Invalid UUID", err.Error())
+		return
+	}
+
+	if err := h.service.RemoveMember(c.Request.Context(), id, userID); err != nil {
+		h.log.Error("Failed to remove member", err, "organization_id", id, "user_id", userID)
+		response.SendError(c, errors.StatusCode(err), "Failed to remove member", err.Error())
+		return
+	}
+
+	h.log.Info("Member removed", "organization_id", id, "user_id", userID)
+	response.SendSuccess(c, http.StatusOK, "Member removed successfully", map[string]string{"organization_id": id, "user_id": userID})
+}
@@ -0,0 +1,148 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/HasanNugroho/gin-clean/config"
+	"github.com/HasanNugroho/gin-clean/internal/domain/entity"
+	"github.com/HasanNugroho/gin-clean/internal/interfaces/http/dto"
+	"github.com/HasanNugroho/gin-clean/internal/interfaces/http/middleware"
+	"github.com/HasanNugroho/gin-clean/pkg/constants"
+	"github.com/HasanNugroho/gin-clean/pkg/logger"
+	"github.com/HasanNugroho/gin-clean/pkg/policy"
+	"github.com/HasanNugroho/gin-clean/pkg/response"
+	"github.com/HasanNugroho/gin-clean/pkg/validation"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/sarulabs/di/v2"
+)
+
+type PolicyHandler struct {
+	engine   *policy.Engine
+	config   *config.Config
+	log      *logger.Logger
+	validate *validator.Validate
+}
+
+// RegisterPolicyRoutes exposes the loaded policy file to administrators for
+// inspection, reloading and explaining decisions.
+func RegisterPolicyRoutes(ctn *di.Container) {
+	var (
+		router         = ctn.Get("base-router").(*gin.RouterGroup)
+		engine         = ctn.Get("policy-engine").(*policy.Engine)
+		cfg            = ctn.Get("config").(*config.Config)
+		log            = ctn.Get("logger").(*logger.Logger)
+		validate       = ctn.Get("validate").(*validator.Validate)
+		authMiddleware = ctn.Get("auth-middleware").(*middleware.AuthMiddleware)
+		permission     = ctn.Get("permission-middleware").(*middleware.PermissionMiddleware)
+	)
+
+	handler := NewPolicyHandler(engine, cfg, log, validate)
+	policyGroup := router.Group("v1/policies", authMiddleware.AuthRequired(), authMiddleware.RequireGlobal())
+	{
+		policyGroup.GET("", permission.RequirePermission(constants.PERMISSION_ROLES_READ), handler.Get)
+		policyGroup.POST("/reload", permission.RequirePermission(constants.PERMISSION_ROLES_MANAGE), handler.Reload)
+		policyGroup.POST("/explain", permission.RequirePermission(constants.PERMISSION_ROLES_READ), handler.Explain)
+	}
+	log.Info("Policy routes registered.")
+}
+
+func NewPolicyHandler(engine *policy.Engine, config *config.Config, log *logger.Logger, validate *validator.Validate) *PolicyHandler {
+	return &PolicyHandler{engine: engine, config: config, log: log, validate: validate}
+}
+
+// Get godoc
+// @Summary      Get the policy file
+// @Description  Show the rules currently loaded from POLICY_FILE and the enforcement mode
+// @Tags         Policies
+// @Produce      json
+// @Success      200  {object}  response.Response{data=object}
+// @Failure      401  {object}  response.Response
+// @Failure      403  {object}  response.Response
+// @Router       /v1/policies [get]
+// @Security     BearerAuth
+func (h *PolicyHandler) Get(c *gin.Context) {
+	if h.engine == nil {
+		response.SendSuccess(c, http.StatusOK, "No policy file configured", map[string]interface{}{"enabled": false})
+		return
+	}
+
+	response.SendSuccess(c, http.StatusOK, "Policy fetched successfully", map[string]interface{}{
+		"enabled": true,
+		"path":    h.engine.Path(),
+		"mode":    h.config.Policy.Mode,
+		"policy":  h.engine.File(),
+	})
+}
+
+// Reload godoc
+// @Summary      Reload the policy file
+// @Description  Read POLICY_FILE again. The previous rules stay in effect if the file is invalid.
+// @Tags         Policies
+// @Produce      json
+// @Success      200  {object}  response.Response{data=string}
+// @Failure      400  {object}  response.Response
+// @Failure      401  {object}  response.Response
+// @Failure      403  {object}  response.Response
+// @Router       /v1/policies/reload [post]
+// @Security     BearerAuth
+func (h *PolicyHandler) Reload(c *gin.Context) {
+	if h.engine == nil {
+		response.SendError(c, http.StatusBadRequest, "No policy file configured", nil)
+		return
+	}
+
+	if err := h.engine.Reload(); err != nil {
+		h.log.Error("Failed to reload policy file", err, "path", h.engine.Path())
+		response.SendError(c, http.StatusBadRequest, "Failed to reload policy file", err.Error())
+		return
+	}
+
+	h.log.Info("Policy file reloaded", "path", h.engine.Path())
+	response.SendSuccess(c, http.StatusOK, "Policy file reloaded successfully", nil)
+}
+
+// Explain godoc
+// @Summary      Explain a policy decision
+// @Description  Evaluate a hypothetical request against the loaded rules and report how every rule was judged. Without a role the current user is evaluated.
+// @Tags         Policies
+// @Accept       json
+// @Produce      json
+// @Param        body  body      dto.PolicyExplainRequest  true  "Request to evaluate"
+// @Success      200   {object}  response.Response{data=policy.Decision}
+// @Failure      400   {object}  response.Response
+// @Failure      401   {object}  response.Response
+// @Failure      403   {object}  response.Response
+// @Router       /v1/policies/explain [post]
+// @Security     BearerAuth
+func (h *PolicyHandler) Explain(c *gin.Context) {
+	if h.engine == nil {
+		response.SendError(c, http.StatusBadRequest, "No policy file configured", nil)
+		return
+	}
+
+	req, ok := validation.ValidateBody[dto.PolicyExplainRequest](c, h.validate, h.log)
+	if !ok {
+		return
+	}
+
+	input := policy.Input{
+		Role:       req.Role,
+		Action:     req.Action,
+		Resource:   req.Resource,
+		Subject:    req.Subject,
+		Attributes: req.Attributes,
+		Request:    req.Request,
+	}
+	if input.Role == "" {
+		current := middleware.PolicyInput(c, c.MustGet("user").(*entity.User), req.Resource)
+		input.Role = current.Role
+		input.Subject = current.Subject
+	}
+	if input.Attributes == nil {
+		input.Attributes = map[string]interface{}{}
+	}
+	input.Attributes["type"] = req.Resource
+
+	response.SendSuccess(c, http.StatusOK, "Policy evaluated", h.engine.Evaluate(input))
+}
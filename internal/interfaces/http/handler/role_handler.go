@@ -0,0 +1,241 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/HasanNugroho/gin-clean/internal/domain/service"
+	"github.com/HasanNugroho/gin-clean/internal/interfaces/http/dto"
+	"github.com/HasanNugroho/gin-clean/internal/interfaces/http/middleware"
+	"github.com/HasanNugroho/gin-clean/pkg/constants"
+	"github.com/HasanNugroho/gin-clean/pkg/errors"
+	"github.com/HasanNugroho/gin-clean/pkg/logger"
+	"github.com/HasanNugroho/gin-clean/pkg/response"
+	"github.com/HasanNugroho/gin-clean/pkg/validation"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/sarulabs/di/v2"
+)
+
+type RoleHandler struct {
+	service  service.RBACService
+	log      *logger.Logger
+	validate *validator.Validate
+}
+
+func RegisterRoleRoutes(ctn *di.Container) {
+	var (
+		router         = ctn.Get("base-router").(*gin.RouterGroup)
+		service        = ctn.Get("rbac-service").(service.RBACService)
+		log            = ctn.Get("logger").(*logger.Logger)
+		validate       = ctn.Get("validate").(*validator.Validate)
+		authMiddleware = ctn.Get("auth-middleware").(*middleware.AuthMiddleware)
+		permission     = ctn.Get("permission-middleware").(*middleware.PermissionMiddleware)
+	)
+
+	handler := NewRoleHandler(service, log, validate)
+	roleGroup := router.Group("v1/roles", authMiddleware.AuthRequired(), authMiddleware.RequireGlobal())
+	{
+		roleGroup.GET("", permission.RequirePermission(constants.PERMISSION_ROLES_READ), handler.List)
+		roleGroup.POST("", permission.RequirePermission(constants.PERMISSION_ROLES_MANAGE), handler.Create)
+		roleGroup.GET("/:name", permission.RequirePermission(constants.PERMISSION_ROLES_READ), handler.Get)
+		roleGroup.DELETE("/:name", permission.RequirePermission(constants.PERMISSION_ROLES_MANAGE), handler.Delete)
+		roleGroup.POST("/:name/permissions", permission.RequirePermission(constants.PERMISSION_ROLES_MANAGE), handler.GrantPermissions)
+		roleGroup.DELETE("/:name/permissions/:permission", permission.RequirePermission(constants.PERMISSION_ROLES_MANAGE), handler.RevokePermission)
+	}
+	router.GET("v1/permissions", authMiddleware.AuthRequired(), authMiddleware.RequireGlobal(), permission.RequirePermission(constants.PERMISSION_ROLES_READ), handler.ListPermissions)
+	log.Info("Role routes registered.")
+}
+
+func NewRoleHandler(service service.RBACService, log *logger.Logger, validate *validator.Validate) *RoleHandler {
+	return &RoleHandler{service: service, log: log, validate: validate}
+}
+
+// List godoc
+// @Summary      List roles
+// @Description  List every role with the permissions it grants
+// @Tags         Roles
+// @Produce      json
+// @Success      200  {object}  response.Response{data=[]entity.Role}
+// @Failure      401  {object}  response.Response
+// @Failure      403  {object}  response.Response
+// @Failure      500  {object}  response.Response
+// @Router       /v1/roles [get]
+// @Security     BearerAuth
+func (h *RoleHandler) List(c *gin.Context) {
+	roles, err := h.service.ListRoles(c.Request.Context())
+	if err != nil {
+		h.log.Error("Failed to list roles", err)
+		response.SendError(c, errors.StatusCode(err), "Failed to list roles", err.Error())
+		return
+	}
+
+	response.SendSuccess(c, http.StatusOK, "Roles fetched successfully", roles)
+}
+
+// Get godoc
+// @Summary      Get role
+// @Description  Retrieve a role with the permissions it grants
+// @Tags         Roles
+// @Produce      json
+// @Param        name  path      string  true  "Role name"
+// @Success      200   {object}  response.Response{data=entity.Role}
+// @Failure      401   {object}  response.Response
+// @Failure      403   {object}  response.Response
+// @Failure      404   {object}  response.Response
+// @Failure      500   {object}  response.Response
+// @Router       /v1/roles/{name} [get]
+// @Security     BearerAuth
+func (h *RoleHandler) Get(c *gin.Context) {
+	name := c.Param("name")
+
+	role, err := h.service.GetRole(c.Request.Context(), name)
+	if err != nil {
+		h.log.Error("Failed to get role", err, "role", name)
+		response.SendError(c, errors.StatusCode(err), "Role not found", err.Error())
+		return
+	}
+
+	response.SendSuccess(c, http.StatusOK, "Role fetched successfully", role)
+}
+
+// Create godoc
+// @Summary      Create role
+// @Description  Create a role without permissions
+// @Tags         Roles
+// @Accept       json
+// @Produce      json
+// @Param        body  body      dto.CreateRoleRequest  true  "Role"
+// @Success      201   {object}  response.Response{data=map[string]string}
+// @Failure      400   {object}  response.Response
+// @Failure      401   {object}  response.Response
+// @Failure      403   {object}  response.Response
+// @Failure      409   {object}  response.Response
+// @Failure      500   {object}  response.Response
+// @Router       /v1/roles [post]
+// @Security     BearerAuth
+func (h *RoleHandler) Create(c *gin.Context) {
+	req, ok := validation.ValidateBody[dto.CreateRoleRequest](c, h.validate, h.log)
+	if !ok {
+		return
+	}
+
+	if err := h.service.CreateRole(c.Request.Context(), req); err != nil {
+		h.log.Error("Failed to create role", err)
+		response.SendError(c, errors.StatusCode(err), "Failed to create role", err.Error())
+		return
+	}
+
+	h.log.Info("Role created", "role", req.Name)
+	response.SendSuccess(c, http.StatusCreated, "Role created successfully", map[string]string{"name": req.Name})
+}
+
+// Delete godoc
+// @Summary      Delete role
+// @Description  Delete a custom role that is not assigned to any user
+// @Tags         Roles
+// @Produce      json
+// @Param        name  path      string  true  "Role name"
+// @Success      200   {object}  response.Response{data=map[string]string}
+// @Failure      401   {object}  response.Response
+// @Failure      403   {object}  response.Response
+// @Failure      404   {object}  response.Response
+// @Failure      409   {object}  response.Response
+// @Failure      500   {object}  response.Response
+// @Router       /v1/roles/{name} [delete]
+// @Security     BearerAuth
+func (h *RoleHandler) Delete(c *gin.Context) {
+	name := c.Param("name")
+
+	if err := h.service.DeleteRole(c.Request.Context(), name); err != nil {
+		h.log.Error("Failed to delete role", err, "role", name)
+		response.SendError(c, errors.StatusCode(err), "Failed to delete role", err.Error())
+		return
+	}
+
+	h.log.Info("Role deleted", "role", name)
+	response.SendSuccess(c, http.StatusOK, "Role deleted successfully", map[string]string{"name": name})
+}
+
+// GrantPermissions godoc
+// @Summary      Grant permissions to a role
+// @Description  Add permissions to a role. Permissions the role already has are ignored.
+// @Tags         Roles
+// @Accept       json
+// @Produce      json
+// @Param        name  path      string                       true  "Role name"
+// @Param        body  body      dto.GrantPermissionsRequest  true  "Permissions"
+// @Success      200   {object}  response.Response{data=map[string]string}
+// @Failure      400   {object}  response.Response
+// @Failure      401   {object}  response.Response
+// @Failure      403   {object}  response.Response
+// @Failure      404   {object}  response.Response
+// @Failure      500   {object}  response.Response
+// @Router       /v1/roles/{name}/permissions [post]
+// @Security     BearerAuth
+func (h *RoleHandler) GrantPermissions(c *gin.Context) {
+	name := c.Param("name")
+
+	req, ok := validation.ValidateBody[dto.GrantPermissionsRequest](c, h.validate, h.log)
+	if !ok {
+		return
+	}
+
+	if err := h.service.GrantPermissions(c.Request.Context(), name, req); err != nil {
+		h.log.Error("Failed to grant permissions", err, "role", name)
+		response.SendError(c, errors.StatusCode(err), "Failed to grant permissions", err.Error())
+		return
+	}
+
+	h.log.Info("Permissions granted", "role", name, "permissions", req.Permissions)
+	response.SendSuccess(c, http.StatusOK, "Permissions granted successfully", map[string]string{"name": name})
+}
+
+// RevokePermission godoc
+// @Summary      Revoke a permission from a role
+// @Description  Remove a single permission from a role
+// @Tags         Roles
+// @Produce      json
+// @Param        name        path      string  true  "Role name"
+// @Param        permission  path      string  true  "Permission name"
+// @Success      200         {object}  response.Response{data=map[string]string}
+// @Failure      401         {object}  response.Response
+// @Failure      403         {object}  response.Response
+// @Failure      404         {object}  response.Response
+// @Failure      500         {object}  response.Response
+// @Router       /v1/roles/{name}/permissions/{permission} [delete]
+// @Security     BearerAuth
+func (h *RoleHandler) RevokePermission(c *gin.Context) {
+	name := c.Param("name")
+	permission := c.Param("permission")
+
+	if err := h.service.RevokePermission(c.Request.Context(), name, permission); err != nil {
+		h.log.Error("Failed to revoke permission", err, "role", name, "permission", permission)
+		response.SendError(c, errors.StatusCode(err), "Failed to revoke permission", err.Error())
+		return
+	}
+
+	h.log.Info("Permission revoked", "role", name, "permission", permission)
+	response.SendSuccess(c, http.StatusOK, "Permission revoked successfully", map[string]string{"name": name, "permission": permission})
+}
+
+// ListPermissions godoc
+// @Summary      List permissions
+// @Description  List every permission that can be granted to roles
+// @Tags         Roles
+// @Produce      json
+// @Success      200  {object}  response.Response{data=[]entity.Permission}
+// @Failure      401  {object}  response.Response
+// @Failure      403  {object}  response.Response
+// @Failure      500  {object}  response.Response
+// @Router       /v1/permissions [get]
+// @Security     BearerAuth
+func (h *RoleHandler) ListPermissions(c *gin.Context) {
+	permissions, err := h.service.ListPermissions(c.Request.Context())
+	if err != nil {
+		h.log.Error("Failed to list permissions", err)
+		response.SendError(c, errors.StatusCode(err), "Failed to list permissions", err.Error())
+		return
+	}
+
+	response.SendSuccess(c, http.StatusOK, "Permissions fetched successfully", permissions)
+}
@@ -2,10 +2,15 @@ package handler
 
 import (
 	"net/http"
+	"strconv"
+	"strings"
 
+	"github.com/HasanNugroho/gin-clean/config"
+	"github.com/HasanNugroho/gin-clean/internal/domain/entity"
 	"github.com/HasanNugroho/gin-clean/internal/domain/service"
 	"github.com/HasanNugroho/gin-clean/internal/interfaces/http/dto"
 	"github.com/HasanNugroho/gin-clean/internal/interfaces/http/middleware"
+	"github.com/HasanNugroho/gin-clean/pkg/constants"
 	"github.com/HasanNugroho/gin-clean/pkg/errors"
 	"github.com/HasanNugroho/gin-clean/pkg/logger"
 	"github.com/HasanNugroho/gin-clean/pkg/response"
@@ -16,33 +21,48 @@ import (
 )
 
 type UserHandler struct {
-	service  service.UserService
-	log      *logger.Logger
-	validate *validator.Validate
+	service    service.UserService
+	authorizer service.Authorizer
+	config     *config.Config
+	log        *logger.Logger
+	validate   *validator.Validate
 }
 
 func RegisterUserRoutes(ctn *di.Container) {
 	var (
 		router         = ctn.Get("base-router").(*gin.RouterGroup)
+		authorizer     = ctn.Get("policy-service").(service.Authorizer)
 		service        = ctn.Get("user-service").(service.UserService)
+		cfg            = ctn.Get("config").(*config.Config)
 		log            = ctn.Get("logger").(*logger.Logger)
 		validate       = ctn.Get("validate").(*validator.Validate)
 		authMiddleware = ctn.Get("auth-middleware").(*middleware.AuthMiddleware)
+		permission     = ctn.Get("permission-middleware").(*middleware.PermissionMiddleware)
+		policy         = ctn.Get("policy-middleware").(*middleware.PolicyMiddleware)
 	)
 
-	handler := NewUserHandler(service, log, validate)
-	userGroup := router.Group("v1/users")
+	handler := NewUserHandler(service, authorizer, cfg, log, validate)
+	userGroup := router.Group("v1/users", policy.Enforce("users"))
 	{
-		userGroup.POST("", handler.Create)
+		userGroup.POST("", authMiddleware.AuthRequired(), permission.RequirePermission(constants.PERMISSION_USERS_CREATE), handler.Create)
+		userGroup.GET("", authMiddleware.AuthRequired(), permission.RequirePermission(constants.PERMISSION_USERS_READ), handler.List)
+		userGroup.GET("/search", authMiddleware.AuthRequired(), permission.RequirePermission(constants.PERMISSION_USERS_READ), handler.Search)
+		userGroup.GET("/deleted", authMiddleware.AuthRequired(), permission.RequirePermission(constants.PERMISSION_USERS_RESTORE), handler.ListDeleted)
+		userGroup.GET("/me", authMiddleware.AuthRequired(), handler.GetMe)
+		userGroup.PATCH("/me", authMiddleware.AuthRequired(), handler.UpdateMe)
+		userGroup.POST("/me/password", authMiddleware.AuthRequired(), handler.ChangeMyPassword)
 		userGroup.GET("/:id", authMiddleware.AuthRequired(), handler.GetById)
 		userGroup.PUT("/:id", authMiddleware.AuthRequired(), handler.Update)
+		userGroup.PATCH("/:id", authMiddleware.AuthRequired(), handler.Patch)
 		userGroup.DELETE("/:id", authMiddleware.AuthRequired(), handler.Delete)
+		userGroup.POST("/:id/restore", authMiddleware.AuthRequired(), permission.RequirePermission(constants.PERMISSION_USERS_RESTORE), handler.Restore)
+		userGroup.DELETE("/:id/purge", authMiddleware.AuthRequired(), permission.RequirePermission(constants.PERMISSION_USERS_PURGE), handler.Purge)
 	}
 	log.Info("User routes registered.")
 }
 
-func NewUserHandler(service service.UserService, log *logger.Logger, validate *validator.Validate) *UserHandler {
-	return &UserHandler{service: service, log: log, validate: validate}
+func NewUserHandler(service service.UserService, authorizer service.Authorizer, config *config.Config, log *logger.Logger, validate *validator.Validate) *UserHandler {
+	return &UserHandler{service: service, authorizer: authorizer, config: config, log: log, validate: validate}
 }
 
 func (h *UserHandler) validateUUID(c *gin.Context, paramName string) (string, bool) {
@@ -54,47 +74,301 @@ func (h *UserHandler) validateUUID(c *gin.Context, paramName string) (string, bo
 	return id, true
 }
 
+// authorize checks that the current user may perform action on the user
+// record with the given id and sends the error response if not.
+func (h *UserHandler) authorize(c *gin.Context, action, id string) bool {
+	actor := c.MustGet("user").(*entity.User)
+	if err := h.authorizer.Authorize(c.Request.Context(), actor, action, service.UserResource(id)); err != nil {
+		response.SendError(c, errors.StatusCode(err), "Forbidden", err.Error())
+		return false
+	}
+	return true
+}
+
+// etag is the entity tag of a version of a user.
+func etag(version int) string {
+	return `"` + strconv.Itoa(version) + `"`
+}
+
+// ifMatch returns the version of user id a change is conditioned on by the
+// If-Match header, and sends the error response if the header is missing or
+// can never match. "*" does not name a version and counts as missing. When
+// If-Match is not required, a change without it is based on the version the
+// user has when the request arrives.
+func (h *UserHandler) ifMatch(c *gin.Context, id string) (int, bool) {
+	header := strings.TrimSpace(c.GetHeader("If-Match"))
+	if header == "" || header == "*" {
+		if h.config.Concurrency.IfMatchRequired() {
+			err := errors.ErrPreconditionRequired.WithMessage("If-Match header with the ETag of the user is required")
+			response.SendError(c, errors.StatusCode(err), "Precondition required", err.Error())
+			return 0, false
+		}
+
+		user, err := h.service.GetById(c.Request.Context(), id)
+		if err != nil {
+			response.SendError(c, errors.StatusCode(err), "User not found", err.Error())
+			return 0, false
+		}
+		return user.Version, true
+	}
+
+	// Only a single strong tag as sent in ETag can match.
+	tag, quoted := strings.CutPrefix(header, `"`)
+	tag, closed := strings.CutSuffix(tag, `"`)
+	version, err := strconv.Atoi(tag)
+	if !quoted || !closed || err != nil || version < 1 {
+		err := errors.ErrPreconditionFailed.WithMessage("If-Match does not match the current version of the user")
+		response.SendError(c, errors.StatusCode(err), "Precondition failed", err.Error())
+		return 0, false
+	}
+	return version, true
+}
+
+// authorizeChanges checks the changes a user makes to their own record, given
+// the email, role, status and whether a password is set after the update, and
+// sends the error response if they are not allowed.
+func (h *UserHandler) authorizeChanges(c *gin.Context, id, email string, role constants.Role, isActive, password bool) bool {
+	actor := c.MustGet("user").(*entity.User)
+	if id != actor.ID {
+		return true
+	}
+
+	// Their own password is changed through ChangeMyPassword, which checks
+	// the current one.
+	if password {
+		response.SendError(c, http.StatusBadRequest, "Validation error", "use /v1/users/me/password to change your own password")
+		return false
+	}
+
+	// So is their own email, through UpdateMe.
+	if email != actor.Email {
+		response.SendError(c, http.StatusBadRequest, "Validation error", "use /v1/users/me to change your own email")
+		return false
+	}
+
+	// Users may edit their own record but not their role or status.
+	if role != actor.Role || isActive != actor.IsActive {
+		return h.authorize(c, service.ActionUpdatePrivileges, id)
+	}
+	return true
+}
+
 // Create godoc
 // @Summary      Create a user (admin)
-// @Description  Admin endpoint to create a new user
+// @Description  Admin endpoint to create a new user. A role can only be given with the roles:manage permission or with every permission of the role.
 // @Tags         Users
 // @Accept       json
 // @Produce      json
 // @Param        body  body      dto.CreateUserRequest  true  "Create Request"
 // @Success      201   {object}  response.Response{data=map[string]string}
+// @Header       201   {string}  ETag  "Version of the user, for If-Match"
 // @Failure      400   {object}  response.Response
+// @Failure      401   {object}  response.Response
+// @Failure      403   {object}  response.Response
+// @Failure      409   {object}  response.Response
+// @Failure      422   {object}  response.Response
 // @Failure      500   {object}  response.Response
 // @Router       /v1/users [post]
+// @Security     BearerAuth
 func (h *UserHandler) Create(c *gin.Context) {
 	req, ok := validation.ValidateBody[dto.CreateUserRequest](c, h.validate, h.log)
 	if !ok {
 		return
 	}
 
-	if err := h.service.Create(c.Request.Context(), req); err != nil {
+	actor := c.MustGet("user").(*entity.User)
+	user, err := h.service.Create(c.Request.Context(), actor, req)
+	if err != nil {
 		h.log.Error("Failed to create user", err)
 		response.SendError(c, errors.StatusCode(err), "Failed to create user", err.Error())
 		return
 	}
 
 	h.log.Info("User created", "email", req.Email)
+	c.Header("ETag", etag(user.Version))
 	response.SendSuccess(c, http.StatusCreated, "User created successfully", map[string]string{"email": req.Email})
 }
 
+// List godoc
+// @Summary      List users
+// @Description  List users page by page, optionally filtered and sorted. Within an organization only its members are listed, with their role in it. With cursor pagination the users are walked in order of creation, following the next_cursor and prev_cursor of the response meta, which stays fast on large tables.
+// @Tags         Users
+// @Produce      json
+// @Param        page          query     int     false  "Page number, starting at 1"  default(1)
+// @Param        limit         query     int     false  "Page size, at most 100"      default(20)
+// @Param        role          query     string  false  "Role"
+// @Param        is_active     query     bool    false  "Active state"
+// @Param        created_from  query     string  false  "Created at or after (RFC 3339)"
+// @Param        created_to    query     string  false  "Created at or before (RFC 3339)"
+// @Param        search        query     string  false  "Case-insensitive search in name and email"
+// @Param        sort          query     string  false  "Sort field, prefix with - for descending"  Enums(name, -name, email, -email, created_at, -created_at, updated_at, -updated_at)
+// @Param        pagination    query     string  false  "Pagination mode"  Enums(offset, cursor)  default(offset)
+// @Param        cursor        query     string  false  "Cursor from the meta of a previous page, implies cursor pagination"
+// @Success      200           {object}  response.Response{data=[]entity.User}
+// @Failure      400           {object}  response.Response
+// @Failure      401           {object}  response.Response
+// @Failure      403           {object}  response.Response
+// @Failure      500           {object}  response.Response
+// @Router       /v1/users [get]
+// @Security     BearerAuth
+func (h *UserHandler) List(c *gin.Context) {
+	query, ok := validation.ValidateQuery[dto.ListUsersQuery](c, h.validate, h.log)
+	if !ok {
+		return
+	}
+
+	if query.Pagination == "cursor" || query.Cursor != "" {
+		users, next, prev, err := h.service.ListByCursor(c.Request.Context(), query)
+		if err != nil {
+			h.log.Error("Failed to list users", err)
+			response.SendError(c, errors.StatusCode(err), "Failed to list users", err.Error())
+			return
+		}
+
+		response.SendCursorPagination(c, http.StatusOK, "Users fetched successfully", users, query.Limit, next, prev)
+		return
+	}
+
+	users, total, err := h.service.List(c.Request.Context(), query)
+	if err != nil {
+		h.log.Error("Failed to list users", err)
+		response.SendError(c, errors.StatusCode(err), "Failed to list users", err.Error())
+		return
+	}
+
+	totalPages := (int(total) + query.Limit - 1) / query.Limit
+	response.SendPagination(c, http.StatusOK, "Users fetched successfully", users, query.Page, query.Limit, int(total), totalPages)
+}
+
+// Search godoc
+// @Summary      Search users
+// @Description  Find users by name, email or phone number, best matches first. Words match as prefixes and small typos are tolerated. Within an organization only its members are searched.
+// @Tags         Users
+// @Produce      json
+// @Param        q      query     string  true   "Search text"
+// @Param        limit  query     int     false  "Number of results, at most 50"  default(20)
+// @Success      200    {object}  response.Response{data=[]dto.UserSearchResult}
+// @Failure      400    {object}  response.Response
+// @Failure      401    {object}  response.Response
+// @Failure      403    {object}  response.Response
+// @Failure      500    {object}  response.Response
+// @Router       /v1/users/search [get]
+// @Security     BearerAuth
+func (h *UserHandler) Search(c *gin.Context) {
+	query, ok := validation.ValidateQuery[dto.SearchUsersQuery](c, h.validate, h.log)
+	if !ok {
+		return
+	}
+
+	results, err := h.service.Search(c.Request.Context(), query)
+	if err != nil {
+		h.log.Error("Failed to search users", err)
+		response.SendError(c, errors.StatusCode(err), "Failed to search users", err.Error())
+		return
+	}
+
+	response.SendSuccess(c, http.StatusOK, "Users fetched successfully", results)
+}
+
+// GetMe godoc
+// @Summary      Get current user
+// @Description  Retrieve the profile of the authenticated user
+// @Tags         Users
+// @Produce      json
+// @Success      200  {object}  response.Response{data=object}
+// @Failure      401  {object}  response.Response
+// @Failure      500  {object}  response.Response
+// @Router       /v1/users/me [get]
+// @Security     BearerAuth
+func (h *UserHandler) GetMe(c *gin.Context) {
+	current := c.MustGet("user").(*entity.User)
+
+	user, err := h.service.GetById(c.Request.Context(), current.ID)
+	if err != nil {
+		h.log.Error("Failed to get current user", err, "id", current.ID)
+		response.SendError(c, errors.StatusCode(err), "User not found", err.Error())
+		return
+	}
+	response.SendSuccess(c, http.StatusOK, "User fetched successfully", user)
+}
+
+// UpdateMe godoc
+// @Summary      Update current user
+// @Description  Partially update the profile of the authenticated user. Changing the email takes the current password, requires verifying the new address and ends every session.
+// @Tags         Users
+// @Accept       json
+// @Produce      json
+// @Param        user  body      dto.UpdateProfileRequest  true  "Fields to update"
+// @Success      200   {object}  response.Response{data=map[string]string}
+// @Failure      400   {object}  response.Response
+// @Failure      401   {object}  response.Response
+// @Failure      409   {object}  response.Response
+// @Failure      500   {object}  response.Response
+// @Router       /v1/users/me [patch]
+// @Security     BearerAuth
+func (h *UserHandler) UpdateMe(c *gin.Context) {
+	req, ok := validation.ValidateBody[dto.UpdateProfileRequest](c, h.validate, h.log)
+	if !ok {
+		return
+	}
+
+	user := c.MustGet("user").(*entity.User)
+	if err := h.service.UpdateProfile(c.Request.Context(), user.ID, req); err != nil {
+		h.log.Error("Failed to update profile", err, "user_id", user.ID)
+		response.SendError(c, errors.StatusCode(err), "Failed to update profile", err.Error())
+		return
+	}
+
+	h.log.Info("Profile updated successfully", "user_id", user.ID)
+	response.SendSuccess(c, http.StatusOK, "Profile updated successfully", map[string]string{"id": user.ID})
+}
+
+// ChangeMyPassword godoc
+// @Summary      Change password
+// @Description  Change the password of the authenticated user. Every session is ended, so all devices have to log in again.
+// @Tags         Users
+// @Accept       json
+// @Produce      json
+// @Param        body  body      dto.ChangePasswordRequest  true  "Current and new password"
+// @Success      200   {object}  response.Response{data=string}
+// @Failure      400   {object}  response.Response
+// @Failure      401   {object}  response.Response
+// @Failure      500   {object}  response.Response
+// @Router       /v1/users/me/password [post]
+// @Security     BearerAuth
+func (h *UserHandler) ChangeMyPassword(c *gin.Context) {
+	req, ok := validation.ValidateBody[dto.ChangePasswordRequest](c, h.validate, h.log)
+	if !ok {
+		return
+	}
+
+	user := c.MustGet("user").(*entity.User)
+	if err := h.service.ChangePassword(c.Request.Context(), user.ID, req); err != nil {
+		h.log.Error("Failed to change password", err, "user_id", user.ID)
+		response.SendError(c, errors.StatusCode(err), "Failed to change password", err.Error())
+		return
+	}
+
+	h.log.Info("Password changed", "user_id", user.ID)
+	response.SendSuccess(c, http.StatusOK, "Password changed successfully", nil)
+}
+
 // GetById godoc
 // @Summary      Get user by ID
-// @Description  Retrieve a user by their ID
+// @Description  Retrieve a user by their ID. Allowed for the user itself or with the users:read permission.
 // @Tags         Users
 // @Produce      json
 // @Param        id   path      string  true  "User ID"
 // @Success      200  {object}  response.Response{data=object}
+// @Header       200  {string}  ETag  "Version of the user, for If-Match"
+// @Failure      403  {object}  response.Response
 // @Failure      404  {object}  response.Response
 // @Failure      500  {object}  response.Response
 // @Router       /v1/users/{id} [get]
 // @Security     BearerAuth
 func (h *UserHandler) GetById(c *gin.Context) {
 	id, ok := h.validateUUID(c, "id")
-	if !ok {
+	if !ok || !h.authorize(c, service.ActionRead, id) {
 		return
 	}
 
@@ -104,21 +378,30 @@ func (h *UserHandler) GetById(c *gin.Context) {
 		response.SendError(c, errors.StatusCode(err), "User not found", err.Error())
 		return
 	}
+
+	c.Header("ETag", etag(user.Version))
 	response.SendSuccess(c, http.StatusOK, "User fetched successfully", user)
 }
 
 // Update godoc
-// @Summary      Update user by ID
-// @Description  Update user data by user ID
+// @Summary      Replace user by ID
+// @Description  Replace the user data by user ID; every field but the password is required. Allowed for the user itself or with the users:update permission; only the latter may change the role or status, and only to a role whose permissions the caller holds, unless they have roles:manage. Users change their own password through /v1/users/me/password and their own email through /v1/users/me.
 // @Tags         Users
 // @Accept       json
 // @Produce      json
-// @Param        id    path      string                 true  "User ID"
-// @Param        user  body      dto.UpdateUserRequest  true  "User data to update"
-// @Success      200   {object}  response.Response{data=map[string]string}
-// @Failure      400   {object}  response.Response
-// @Failure      404   {object}  response.Response
-// @Failure      500   {object}  response.Response
+// @Param        id        path      string                 true  "User ID"
+// @Param        If-Match  header    string                 true  "ETag of the user as read; optional only with USERS_REQUIRE_IF_MATCH=false"
+// @Param        user      body      dto.UpdateUserRequest  true  "User data to update"
+// @Success      200       {object}  response.Response{data=map[string]string}
+// @Header       200       {string}  ETag  "New version of the user, for If-Match"
+// @Failure      400       {object}  response.Response
+// @Failure      403       {object}  response.Response
+// @Failure      404       {object}  response.Response
+// @Failure      409       {object}  response.Response
+// @Failure      412       {object}  response.Response
+// @Failure      422       {object}  response.Response
+// @Failure      428       {object}  response.Response
+// @Failure      500       {object}  response.Response
 // @Router       /v1/users/{id} [put]
 // @Security     BearerAuth
 func (h *UserHandler) Update(c *gin.Context) {
@@ -128,11 +411,19 @@ func (h *UserHandler) Update(c *gin.Context) {
 	}
 
 	req, ok := validation.ValidateBody[dto.UpdateUserRequest](c, h.validate, h.log)
-	if !ok {
+	if !ok || !h.authorize(c, service.ActionUpdate, id) {
+		return
+	}
+	if req.Version, ok = h.ifMatch(c, id); !ok {
+		return
+	}
+
+	if !h.authorizeChanges(c, id, req.Email, req.Role, *req.IsActive, req.Password != "") {
 		return
 	}
 
-	err := h.service.Update(c.Request.Context(), id, req)
+	actor := c.MustGet("user").(*entity.User)
+	user, err := h.service.Update(c.Request.Context(), actor, id, req)
 	if err != nil {
 		h.log.Error("Failed to update user", err, "user_id", id)
 		response.SendError(c, errors.StatusCode(err), "Failed to update user", err.Error())
@@ -140,27 +431,99 @@ func (h *UserHandler) Update(c *gin.Context) {
 	}
 
 	h.log.Info("User updated successfully", "user_id", id)
+	c.Header("ETag", etag(user.Version))
+	response.SendSuccess(c, http.StatusOK, "User updated successfully", map[string]string{"id": id})
+}
+
+// Patch godoc
+// @Summary      Patch user by ID
+// @Description  Apply a JSON merge patch (RFC 7396) to the user: omitted fields keep their value and none may be null. Allowed for the user itself or with the users:update permission; only the latter may change the role or status, and only to a role whose permissions the caller holds, unless they have roles:manage. Users change their own password through /v1/users/me/password and their own email through /v1/users/me.
+// @Tags         Users
+// @Accept       json
+// @Accept       application/merge-patch+json
+// @Produce      json
+// @Param        id        path      string                true  "User ID"
+// @Param        If-Match  header    string                true  "ETag of the user as read; optional only with USERS_REQUIRE_IF_MATCH=false"
+// @Param        user      body      dto.PatchUserRequest  true  "Fields to change"
+// @Success      200       {object}  response.Response{data=map[string]string}
+// @Header       200       {string}  ETag  "New version of the user, for If-Match"
+// @Failure      400       {object}  response.Response
+// @Failure      403       {object}  response.Response
+// @Failure      404       {object}  response.Response
+// @Failure      409       {object}  response.Response
+// @Failure      412       {object}  response.Response
+// @Failure      422       {object}  response.Response
+// @Failure      428       {object}  response.Response
+// @Failure      500       {object}  response.Response
+// @Router       /v1/users/{id} [patch]
+// @Security     BearerAuth
+func (h *UserHandler) Patch(c *gin.Context) {
+	id, ok := h.validateUUID(c, "id")
+	if !ok {
+		return
+	}
+
+	req, ok := validation.ValidatePatch[dto.PatchUserRequest](c, h.validate, h.log)
+	if !ok || !h.authorize(c, service.ActionUpdate, id) {
+		return
+	}
+	if req.Version, ok = h.ifMatch(c, id); !ok {
+		return
+	}
+
+	actor := c.MustGet("user").(*entity.User)
+	email, role, isActive := actor.Email, actor.Role, actor.IsActive
+	if req.Email.Present() {
+		email = req.Email.Value
+	}
+	if req.Role.Present() {
+		role = req.Role.Value
+	}
+	if req.IsActive.Present() {
+		isActive = req.IsActive.Value
+	}
+	if !h.authorizeChanges(c, id, email, role, isActive, req.Password.Set) {
+		return
+	}
+
+	user, err := h.service.Patch(c.Request.Context(), actor, id, req)
+	if err != nil {
+		h.log.Error("Failed to patch user", err, "user_id", id)
+		response.SendError(c, errors.StatusCode(err), "Failed to update user", err.Error())
+		return
+	}
+
+	h.log.Info("User updated successfully", "user_id", id)
+	c.Header("ETag", etag(user.Version))
 	response.SendSuccess(c, http.StatusOK, "User updated successfully", map[string]string{"id": id})
 }
 
 // Delete godoc
 // @Summary      Delete user
-// @Description  Delete user by ID
+// @Description  Delete user by ID. Allowed for the user itself or with the users:delete permission.
 // @Tags         Users
 // @Produce      json
-// @Param        id   path      string  true  "User ID"
-// @Success      200  {object}  response.Response{data=map[string]string}
-// @Failure      404  {object}  response.Response
-// @Failure      500  {object}  response.Response
+// @Param        id        path      string  true  "User ID"
+// @Param        If-Match  header    string  true  "ETag of the user as read; optional only with USERS_REQUIRE_IF_MATCH=false"
+// @Success      200       {object}  response.Response{data=map[string]string}
+// @Failure      403       {object}  response.Response
+// @Failure      404       {object}  response.Response
+// @Failure      412       {object}  response.Response
+// @Failure      428       {object}  response.Response
+// @Failure      500       {object}  response.Response
 // @Router       /v1/users/{id} [delete]
 // @Security     BearerAuth
 func (h *UserHandler) Delete(c *gin.Context) {
 	id, ok := h.validateUUID(c, "id")
+	if !ok || !h.authorize(c, service.ActionDelete, id) {
+		return
+	}
+	version, ok := h.ifMatch(c, id)
 	if !ok {
 		return
 	}
 
-	if err := h.service.Delete(c.Request.Context(), id); err != nil {
+	if err := h.service.Delete(c.Request.Context(), id, version); err != nil {
 		h.log.Error("Failed to delete user", err, "id", id)
 		response.SendError(c, errors.StatusCode(err), "Failed to delete user", err.Error())
 		return
@@ -169,3 +532,96 @@ func (h *UserHandler) Delete(c *gin.Context) {
 	h.log.Info("User deleted", "id", id)
 	response.SendSuccess(c, http.StatusOK, "User deleted successfully", map[string]string{"id": id})
 }
+
+// ListDeleted godoc
+// @Summary      List deleted users
+// @Description  List soft-deleted users page by page, most recently deleted first. They can be restored until purge_at, after which they are purged for good.
+// @Tags         Users
+// @Produce      json
+// @Param        page   query     int  false  "Page number, starting at 1"  default(1)
+// @Param        limit  query     int  false  "Page size, at most 100"      default(20)
+// @Success      200    {object}  response.Response{data=[]dto.DeletedUserResponse}
+// @Failure      400    {object}  response.Response
+// @Failure      401    {object}  response.Response
+// @Failure      403    {object}  response.Response
+// @Failure      500    {object}  response.Response
+// @Router       /v1/users/deleted [get]
+// @Security     BearerAuth
+func (h *UserHandler) ListDeleted(c *gin.Context) {
+	query, ok := validation.ValidateQuery[dto.ListDeletedUsersQuery](c, h.validate, h.log)
+	if !ok {
+		return
+	}
+
+	users, total, err := h.service.ListDeleted(c.Request.Context(), query)
+	if err != nil {
+		h.log.Error("Failed to list deleted users", err)
+		response.SendError(c, errors.StatusCode(err), "Failed to list deleted users", err.Error())
+		return
+	}
+
+	totalPages := (int(total) + query.Limit - 1) / query.Limit
+	response.SendPagination(c, http.StatusOK, "Deleted users fetched successfully", users, query.Page, query.Limit, int(total), totalPages)
+}
+
+// Restore godoc
+// @Summary      Restore deleted user
+// @Description  Undelete a soft-deleted user, unless another user has taken their email since. The user has to log in again.
+// @Tags         Users
+// @Produce      json
+// @Param        id   path      string  true  "User ID"
+// @Success      200  {object}  response.Response{data=map[string]string}
+// @Header       200  {string}  ETag  "New version of the user, for If-Match"
+// @Failure      400  {object}  response.Response
+// @Failure      403  {object}  response.Response
+// @Failure      404  {object}  response.Response
+// @Failure      409  {object}  response.Response
+// @Failure      500  {object}  response.Response
+// @Router       /v1/users/{id}/restore [post]
+// @Security     BearerAuth
+func (h *UserHandler) Restore(c *gin.Context) {
+	id, ok := h.validateUUID(c, "id")
+	if !ok {
+		return
+	}
+
+	user, err := h.service.Restore(c.Request.Context(), id)
+	if err != nil {
+		h.log.Error("Failed to restore user", err, "id", id)
+		response.SendError(c, errors.StatusCode(err), "Failed to restore user", err.Error())
+		return
+	}
+
+	h.log.Info("User restored", "id", id)
+	c.Header("ETag", etag(user.Version))
+	response.SendSuccess(c, http.StatusOK, "User restored successfully", map[string]string{"id": id})
+}
+
+// Purge godoc
+// @Summary      Purge deleted user
+// @Description  Permanently delete a soft-deleted user along with their sessions and memberships, without waiting for the grace period.
+// @Tags         Users
+// @Produce      json
+// @Param        id   path      string  true  "User ID"
+// @Success      200  {object}  response.Response{data=map[string]string}
+// @Failure      400  {object}  response.Response
+// @Failure      403  {object}  response.Response
+// @Failure      404  {object}  response.Response
+// @Failure      500  {object}  response.Response
+// @Router       /v1/users/{id}/purge [delete]
+// @Security     BearerAuth
+func (h *UserHandler) Purge(c *gin.Context) {
+	id, ok := h.validateUUID(c, "id")
+	if !ok {
+		return
+	}
+
+	if err := h.service.Purge(c.Request.Context(), id); err != nil {
+		h.log.Error("Failed to purge user", err, "id", id)
+		response.SendError(c, errors.StatusCode(err), "Failed to purge user", err.Error())
+		return
+	}
+
+	h.log.Info("User purged", "id", id)
+	response.SendSuccess(c, http.StatusOK, "User purged successfully", map[string]string{"id": id})
+}
@@ -2,82 +2,160 @@ package middleware
 
 import (
 	"strings"
-	"time"
 
+	"github.com/HasanNugroho/gin-clean/config"
 	"github.com/HasanNugroho/gin-clean/internal/domain/entity"
 	"github.com/HasanNugroho/gin-clean/internal/domain/service"
-	"github.com/HasanNugroho/gin-clean/internal/infrastructure/presistence/cache"
 	"github.com/HasanNugroho/gin-clean/pkg/errors"
 	"github.com/HasanNugroho/gin-clean/pkg/jwt"
 	"github.com/HasanNugroho/gin-clean/pkg/logger"
+	"github.com/HasanNugroho/gin-clean/pkg/tenant"
 	"github.com/gin-gonic/gin"
 )
 
 type AuthMiddleware struct {
-	userService service.UserService
-	logger      *logger.Logger
-	jwt         *jwt.TokenGenerator
-	cache       *cache.RedisCache
+	userService         service.UserService
+	organizationService service.OrganizationService
+	logger              *logger.Logger
+	jwt                 *jwt.TokenGenerator
+	config              *config.Config
 }
 
-func NewAuthMiddleware(logger *logger.Logger, userService service.UserService, jwt *jwt.TokenGenerator, cache *cache.RedisCache) *AuthMiddleware {
+func NewAuthMiddleware(logger *logger.Logger, userService service.UserService, organizationService service.OrganizationService, jwt *jwt.TokenGenerator, config *config.Config) *AuthMiddleware {
 	return &AuthMiddleware{
-		userService: userService,
-		logger:      logger,
-		jwt:         jwt,
-		cache:       cache,
+		userService:         userService,
+		organizationService: organizationService,
+		logger:              logger,
+		jwt:                 jwt,
+		config:              config,
 	}
 }
 
 func (m *AuthMiddleware) AuthRequired() gin.HandlerFunc {
+	return m.authenticate(false)
+}
+
+// AuthRequiredForMFAEnrollment authenticates like AuthRequired but also lets
+// through users whose role requires MFA and who have not enrolled yet, so the
+// enrollment endpoints stay reachable for them.
+func (m *AuthMiddleware) AuthRequiredForMFAEnrollment() gin.HandlerFunc {
+	return m.authenticate(true)
+}
+
+func (m *AuthMiddleware) authenticate(allowMFAEnrollment bool) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		authHeader := c.GetHeader("Authorization")
-		if authHeader == "" {
-			c.Error(errors.ErrUnauthorized.WithMessage("missing authorization header"))
+		user, err := m.Identify(c)
+		if err != nil {
+			c.Error(err)
 			c.Abort()
 			return
 		}
 
-		token := strings.TrimPrefix(authHeader, "Bearer ")
-		if token == authHeader {
-			c.Error(errors.ErrUnauthorized.WithMessage("invalid authorization scheme"))
+		if !allowMFAEnrollment && !user.MFAEnabled && m.config.MFA.IsRequiredFor(string(user.Role)) {
+			c.Error(errors.ErrForbidden.WithMessage("MFA enrollment required").WithCode("MFA_ENROLLMENT_REQUIRED"))
 			c.Abort()
 			return
 		}
 
-		claims, err := m.jwt.ParseToken(token)
+		c.Next()
+	}
+}
+
+// Identify authenticates the bearer token of the request and stores the user
+// and session in the context. Tokens issued for an organization also scope the
+// request context to it and give the user their role in that organization.
+// Unlike AuthRequired it does not abort, so it can
+// be used to learn who is calling an endpoint that is also open to anonymous
+// users. Repeated calls reuse the first result.
+func (m *AuthMiddleware) Identify(c *gin.Context) (*entity.User, error) {
+	if user, ok := c.Get("user"); ok {
+		return user.(*entity.User), nil
+	}
+
+	authHeader := c.GetHeader("Authorization")
+	if authHeader == "" {
+		return nil, errors.ErrUnauthorized.WithMessage("missing authorization header")
+	}
+
+	token := strings.TrimPrefix(authHeader, "Bearer ")
+	if token == authHeader {
+		return nil, errors.ErrUnauthorized.WithMessage("invalid authorization scheme")
+	}
+
+	claims, err := m.jwt.ParseToken(token)
+	if err != nil {
+		return nil, errors.ErrUnauthorized.WithMessage("invalid or expired token")
+	}
+
+	id, ok := claims["payload"].(string)
+	if !ok {
+		return nil, errors.ErrUnauthorized.WithMessage("invalid token payload")
+	}
+
+	sessionID, ok := claims["sid"].(string)
+	if !ok || !m.jwt.IsSessionActive(sessionID) {
+		return nil, errors.ErrUnauthorized.WithMessage("session revoked or expired")
+	}
+
+	// The user is read on every request rather than cached, so changes to
+	// their role, status or MFA apply right away. Revoked tokens are already
+	// turned away by ParseToken from the cached token versions.
+	user, err := m.userService.GetById(c.Request.Context(), id)
+	if err != nil {
+		return nil, errors.ErrUnauthorized.WithMessage("user not found").WithError(err)
+	}
+
+	if !user.IsActive {
+		return nil, errors.ErrForbidden.WithMessage("user not active")
+	}
+
+	if jwt.TokenVersion(claims) < user.TokenVersion {
+		return nil, errors.ErrUnauthorized.WithMessage("token has been revoked")
+	}
+
+	if organizationID := jwt.TenantID(claims); organizationID != "" {
+		ctx := tenant.WithTenant(c.Request.Context(), organizationID)
+		membership, err := m.organizationService.Membership(ctx, organizationID, user.ID)
 		if err != nil {
-			c.Error(errors.ErrUnauthorized.WithMessage("invalid or expired token"))
-			c.Abort()
-			return
+			return nil, errors.ErrUnauthorized.WithMessage("organization access has been revoked").WithError(err)
 		}
 
-		id, ok := claims["payload"].(string)
-		if !ok {
-			c.Error(errors.ErrUnauthorized.WithMessage("invalid token payload"))
+		user.Role = membership.Role
+
+		c.Request = c.Request.WithContext(ctx)
+		c.Set("tenant_id", organizationID)
+	}
+
+	c.Set("user", user)
+	c.Set("session_id", sessionID)
+	return user, nil
+}
+
+// RequireTenant only lets through tokens that act in the organization named
+// by the path parameter. It must run after AuthRequired.
+func (m *AuthMiddleware) RequireTenant(param string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.GetString("tenant_id") != c.Param(param) {
+			c.Error(errors.ErrForbidden.WithMessage("token does not act in this organization"))
 			c.Abort()
 			return
 		}
 
-		user := new(entity.User)
-		err = m.cache.Get(c, "user:"+id, user)
-		if err != nil {
-			user, err = m.userService.GetById(c.Request.Context(), id)
-			if err != nil {
-				c.Error(errors.ErrUnauthorized.WithMessage("user not found").WithError(err))
-				c.Abort()
-				return
-			}
-			_ = m.cache.Set(c, "user:"+id, user, 2*time.Hour)
-		}
+		c.Next()
+	}
+}
 
-		if !user.IsActive {
-			c.Error(errors.ErrForbidden.WithMessage("user not active"))
+// RequireGlobal only lets through tokens that act in no organization, for
+// endpoints that manage the whole application and are authorized by the
+// global role alone. It must run after AuthRequired.
+func (m *AuthMiddleware) RequireGlobal() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.GetString("tenant_id") != "" {
+			c.Error(errors.ErrForbidden.WithMessage("token acts in an organization"))
 			c.Abort()
 			return
 		}
 
-		c.Set("user", user)
 		c.Next()
 	}
 }
@@ -15,8 +15,8 @@ func SetCORS(config *config.Config) gin.HandlerFunc {
 	corsConfig := cors.Config{
 		AllowOrigins:     allowOrigins,
 		AllowMethods:     []string{"GET", "POST", "PUT", "DELETE", "OPTIONS", "PATCH"},
-		AllowHeaders:     []string{"Accept", "Authorization", "Content-Type", "Origin", "X-Requested-With"},
-		ExposeHeaders:    []string{"Content-Length"},
+		AllowHeaders:     []string{"Accept", "Authorization", "Content-Type", "If-Match", "Origin", "X-Requested-With"},
+		ExposeHeaders:    []string{"Content-Length", "ETag"},
 		AllowCredentials: true,
 	}
 
@@ -0,0 +1,46 @@
+package middleware
+
+import (
+	"github.com/HasanNugroho/gin-clean/internal/domain/entity"
+	"github.com/HasanNugroho/gin-clean/internal/domain/service"
+	"github.com/HasanNugroho/gin-clean/pkg/errors"
+	"github.com/HasanNugroho/gin-clean/pkg/logger"
+	"github.com/gin-gonic/gin"
+)
+
+type PermissionMiddleware struct {
+	rbac   service.RBACService
+	logger *logger.Logger
+}
+
+func NewPermissionMiddleware(logger *logger.Logger, rbac service.RBACService) *PermissionMiddleware {
+	return &PermissionMiddleware{
+		rbac:   rbac,
+		logger: logger,
+	}
+}
+
+// RequirePermission only lets through users whose role grants every given
+// permission. It must run after AuthMiddleware.AuthRequired.
+func (m *PermissionMiddleware) RequirePermission(permissions ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		user := c.MustGet("user").(*entity.User)
+
+		for _, permission := range permissions {
+			ok, err := m.rbac.HasPermission(c.Request.Context(), user, permission)
+			if err != nil {
+				m.logger.Error("Failed to resolve permissions", err, "user_id", user.ID)
+				c.Error(err)
+				c.Abort()
+				return
+			}
+			if !ok {
+				c.Error(errors.ErrForbidden.WithMessage("missing permission " + permission))
+				c.Abort()
+				return
+			}
+		}
+
+		c.Next()
+	}
+}
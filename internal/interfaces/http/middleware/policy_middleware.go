@@ -0,0 +1,132 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/HasanNugroho/gin-clean/config"
+	"github.com/HasanNugroho/gin-clean/internal/domain/entity"
+	"github.com/HasanNugroho/gin-clean/pkg/errors"
+	"github.com/HasanNugroho/gin-clean/pkg/logger"
+	"github.com/HasanNugroho/gin-clean/pkg/policy"
+	"github.com/gin-gonic/gin"
+)
+
+// PolicyMiddleware enforces the rules of the policy file configured by
+// POLICY_FILE. Without a policy file it lets every request through.
+type PolicyMiddleware struct {
+	engine *policy.Engine
+	auth   *AuthMiddleware
+	logger *logger.Logger
+	config *config.Config
+}
+
+func NewPolicyMiddleware(logger *logger.Logger, auth *AuthMiddleware, engine *policy.Engine, config *config.Config) *PolicyMiddleware {
+	return &PolicyMiddleware{
+		engine: engine,
+		auth:   auth,
+		logger: logger,
+		config: config,
+	}
+}
+
+// Enforce authorizes requests to a route group as actions on the given
+// resource type. It runs before the route handlers, so it identifies the
+// caller itself; callers without a valid token are the anonymous role.
+//
+// In dry_run mode denials are only logged together with the evaluation trace.
+func (m *PolicyMiddleware) Enforce(resource string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if m.engine == nil {
+			c.Next()
+			return
+		}
+
+		user, _ := m.auth.Identify(c)
+		input := PolicyInput(c, user, resource)
+		decision := m.engine.Evaluate(input)
+		if decision.Allowed {
+			c.Next()
+			return
+		}
+
+		if m.config.Policy.Mode == config.PolicyDryRun {
+			m.logger.Warn("Policy would deny request",
+				"role", input.Role,
+				"resource", input.Resource,
+				"action", input.Action,
+				"path", c.FullPath(),
+				"reason", decision.Reason,
+				"trace", decision.Trace,
+			)
+			c.Next()
+			return
+		}
+
+		m.logger.Info("Policy denied request",
+			"role", input.Role,
+			"resource", input.Resource,
+			"action", input.Action,
+			"path", c.FullPath(),
+			"reason", decision.Reason,
+		)
+		if user == nil {
+			c.Error(errors.ErrUnauthorized.WithMessage("authentication required"))
+		} else {
+			c.Error(errors.ErrForbidden.WithMessage("denied by policy"))
+		}
+		c.Abort()
+	}
+}
+
+// PolicyInput describes a request for the policy engine. The action is
+// derived from the HTTP method and the path parameters become the resource
+// attributes.
+func PolicyInput(c *gin.Context, user *entity.User, resource string) policy.Input {
+	input := policy.Input{
+		Role:       policy.RoleAnonymous,
+		Action:     policyAction(c.Request.Method),
+		Resource:   resource,
+		Subject:    map[string]interface{}{},
+		Attributes: map[string]interface{}{"type": resource},
+		Request: map[string]interface{}{
+			"method": c.Request.Method,
+			"path":   c.FullPath(),
+			"ip":     c.ClientIP(),
+		},
+	}
+
+	for _, param := range c.Params {
+		input.Attributes[param.Key] = param.Value
+	}
+
+	if user != nil {
+		input.Role = string(user.Role)
+		input.Subject = map[string]interface{}{
+			"id":             user.ID,
+			"email":          user.Email,
+			"role":           string(user.Role),
+			"is_active":      user.IsActive,
+			"mfa_enabled":    user.MFAEnabled,
+			"email_verified": user.IsEmailVerified(),
+		}
+		if organizationID := c.GetString("tenant_id"); organizationID != "" {
+			input.Subject["organization_id"] = organizationID
+		}
+	}
+
+	return input
+}
+
+func policyAction(method string) string {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return "read"
+	case http.MethodPost:
+		return "create"
+	case http.MethodPut, http.MethodPatch:
+		return "update"
+	case http.MethodDelete:
+		return "delete"
+	}
+	return method
+}
@@ -9,6 +9,7 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/ulule/limiter/v3"
 	mgin "github.com/ulule/limiter/v3/drivers/middleware/gin"
+	"github.com/ulule/limiter/v3/drivers/store/memory"
 	"github.com/ulule/limiter/v3/drivers/store/redis"
 )
 
@@ -28,7 +29,9 @@ func (l *RateLimit) RateLimit() gin.HandlerFunc {
 	return mgin.NewMiddleware(l.limiter)
 }
 
-func NewRateLimiter(config *config.Config, redisClient *cache.RedisCache) (*RateLimit, error) {
+// NewRateLimiter counts requests in Redis when given a RedisCache, so that
+// instances share their limits, and in the memory of the process otherwise.
+func NewRateLimiter(config *config.Config, store cache.Cache) (*RateLimit, error) {
 	if config.Security.RateLimit == "" {
 		return nil, nil
 	}
@@ -41,11 +44,25 @@ func NewRateLimiter(config *config.Config, redisClient *cache.RedisCache) (*Rate
 	ipv6Mask := net.CIDRMask(64, 128)
 	options := []limiter.Option{limiter.WithIPv6Mask(ipv6Mask)}
 
-	if redisClient == nil || redisClient.Client() == nil {
+	if store == nil {
+		return nil, fmt.Errorf("cache is not initialized")
+	}
+
+	redisCache, ok := store.(*cache.RedisCache)
+	if !ok {
+		return &RateLimit{
+			limiter: limiter.New(memory.NewStoreWithOptions(limiter.StoreOptions{
+				Prefix:          "limiter",
+				CleanUpInterval: limiter.DefaultCleanUpInterval,
+			}), rate, options...),
+		}, nil
+	}
+
+	if redisCache.Client() == nil {
 		return nil, fmt.Errorf("redis client is not initialized")
 	}
 
-	store, err := redis.NewStoreWithOptions(redisClient.Client(), limiter.StoreOptions{
+	redisStore, err := redis.NewStoreWithOptions(redisCache.Client(), limiter.StoreOptions{
 		Prefix:   "limiter",
 		MaxRetry: 3,
 	})
@@ -54,6 +71,6 @@ func NewRateLimiter(config *config.Config, redisClient *cache.RedisCache) (*Rate
 	}
 
 	return &RateLimit{
-		limiter: limiter.New(store, rate, options...),
+		limiter: limiter.New(redisStore, rate, options...),
 	}, nil
 }
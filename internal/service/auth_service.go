@@ -2,27 +2,48 @@ package service
 
 import (
 	"context"
+	"fmt"
+	"net/url"
 	"time"
 
 	"github.com/HasanNugroho/gin-clean/config"
+	"github.com/HasanNugroho/gin-clean/internal/domain/entity"
 	"github.com/HasanNugroho/gin-clean/internal/domain/repository"
+	"github.com/HasanNugroho/gin-clean/internal/infrastructure/presistence/cache"
 	"github.com/HasanNugroho/gin-clean/internal/interfaces/http/dto"
 	"github.com/HasanNugroho/gin-clean/pkg/errors"
 	"github.com/HasanNugroho/gin-clean/pkg/jwt"
 	"github.com/HasanNugroho/gin-clean/pkg/logger"
+	"github.com/HasanNugroho/gin-clean/pkg/mail"
 )
 
+const mfaChallengePurpose = "mfa_challenge"
+
 type AuthService struct {
 	repo           repository.UserRepository
+	sessions       repository.SessionRepository
+	revoker        *TokenRevoker
+	mfa            *MFAService
+	users          *UserService
+	organizations  *OrganizationService
+	cache          cache.Cache
+	mailer         mail.Sender
 	logger         *logger.Logger
 	config         *config.Config
 	jwt            *jwt.TokenGenerator
 	contextTimeout time.Duration
 }
 
-func NewAuthService(repo repository.UserRepository, logger *logger.Logger, config *config.Config, jwt *jwt.TokenGenerator, timeout time.Duration) *AuthService {
+func NewAuthService(repo repository.UserRepository, sessions repository.SessionRepository, revoker *TokenRevoker, mfa *MFAService, users *UserService, organizations *OrganizationService, cache cache.Cache, mailer mail.Sender, logger *logger.Logger, config *config.Config, jwt *jwt.TokenGenerator, timeout time.Duration) *AuthService {
 	return &AuthService{
 		repo:           repo,
+		sessions:       sessions,
+		revoker:        revoker,
+		mfa:            mfa,
+		users:          users,
+		organizations:  organizations,
+		cache:          cache,
+		mailer:         mailer,
 		logger:         logger,
 		config:         config,
 		jwt:            jwt,
@@ -30,7 +51,7 @@ func NewAuthService(repo repository.UserRepository, logger *logger.Logger, confi
 	}
 }
 
-func (s *AuthService) Login(ctx context.Context, req dto.LoginRequest) (result dto.AuthResponse, err error) {
+func (s *AuthService) Login(ctx context.Context, req dto.LoginRequest, client dto.ClientInfo) (result dto.AuthResponse, err error) {
 	ctx, cancel := context.WithTimeout(ctx, s.contextTimeout)
 	defer cancel()
 
@@ -44,38 +65,214 @@ func (s *AuthService) Login(ctx context.Context, req dto.LoginRequest) (result d
 		return result, errors.ErrUnauthorized.WithMessage("invalid email or password")
 	}
 
+	if !user.IsActive {
+		return result, errors.ErrForbidden.WithMessage("user not active")
+	}
+
+	if s.config.Verify.Required && !user.IsEmailVerified() {
+		return result, errors.ErrForbidden.WithMessage("email address has not been verified")
+	}
+
+	if req.OrganizationID != "" {
+		if err := s.checkMembership(ctx, req.OrganizationID, user); err != nil {
+			return result, err
+		}
+	}
+
+	// With MFA the password only earns a short-lived challenge token that is
+	// exchanged for a session at VerifyMFA.
+	if user.MFAEnabled {
+		expiry, _ := time.ParseDuration(s.config.MFA.ChallengeExpiry)
+		challenge, err := s.jwt.GeneratePurposeToken(mfaChallengePurpose, user.ID, expiry, map[string]interface{}{
+			"device": req.Device,
+			"org":    req.OrganizationID,
+		})
+		if err != nil {
+			return result, err
+		}
+
+		return dto.AuthResponse{
+			MFARequired: true,
+			MFAToken:    challenge,
+			Data: map[string]interface{}{
+				"id": user.ID,
+			},
+		}, nil
+	}
+
+	result, err = s.startSession(ctx, user, req.Device, req.OrganizationID, client)
+	if err != nil {
+		return result, err
+	}
+
+	// Accounts forced into MFA may only enroll until they have done so.
+	if s.config.MFA.IsRequiredFor(string(user.Role)) {
+		result.Data.(map[string]interface{})["mfa_enrollment_required"] = true
+	}
+	return result, nil
+}
+
+// Register creates a self-service account according to the configured
+// registration mode and, when enabled, logs the new user in right away.
+func (s *AuthService) Register(ctx context.Context, req dto.RegisterUserRequest, client dto.ClientInfo) (result dto.AuthResponse, err error) {
+	ctx, cancel := context.WithTimeout(ctx, s.contextTimeout)
+	defer cancel()
+
+	switch s.config.Registration.Mode {
+	case config.RegistrationDisabled:
+		return result, errors.ErrForbidden.WithMessage("registration is disabled")
+	case config.RegistrationInvite:
+		return result, errors.ErrForbidden.WithMessage("registration is by invitation only")
+	}
+
+	user, err := s.users.Register(ctx, &req)
+	if err != nil {
+		return result, err
+	}
+
+	result.Data = map[string]interface{}{
+		"id": user.ID,
+	}
+
+	if !s.config.Registration.AutoLogin {
+		return result, nil
+	}
+	if s.config.Verify.Required && !user.IsEmailVerified() {
+		result.Data.(map[string]interface{})["email_verification_required"] = true
+		return result, nil
+	}
+
+	return s.startSession(ctx, user, "", "", client)
+}
+
+// VerifyMFA completes a login started with an MFA challenge token.
+func (s *AuthService) VerifyMFA(ctx context.Context, req dto.MFAVerifyRequest, client dto.ClientInfo) (result dto.AuthResponse, err error) {
+	ctx, cancel := context.WithTimeout(ctx, s.contextTimeout)
+	defer cancel()
+
+	claims, err := s.jwt.ParsePurposeToken(mfaChallengePurpose, req.MFAToken)
+	if err != nil {
+		return result, errors.ErrUnauthorized.WithMessage("invalid or expired MFA token")
+	}
+
+	id, _ := claims["payload"].(string)
+	user, err := s.repo.GetByID(ctx, id)
+	if err != nil || !user.IsActive {
+		return result, errors.ErrUnauthorized.WithMessage("invalid or expired MFA token")
+	}
+
+	// A challenge opens a single session and allows a single attempt, so it is
+	// claimed before a code is checked or a recovery code consumed.
+	expiry, _ := time.ParseDuration(s.config.MFA.ChallengeExpiry)
+	fresh, err := s.cache.SetNX(ctx, "mfa:challenge:"+hashToken(req.MFAToken), true, expiry)
+	if err != nil {
+		return result, errors.Wrap(errors.ErrInternalServer, err)
+	}
+	if !fresh {
+		return result, errors.ErrUnauthorized.WithMessage("MFA token has already been used")
+	}
+
+	remaining := -1
+	if req.RecoveryCode != "" {
+		remaining, err = s.mfa.VerifyRecoveryCode(ctx, user, req.RecoveryCode)
+	} else {
+		err = s.mfa.VerifyCode(ctx, user, req.Code)
+	}
+	if err != nil {
+		return result, err
+	}
+
+	device, _ := claims["device"].(string)
+	organizationID, _ := claims["org"].(string)
+	if organizationID != "" {
+		if err := s.checkMembership(ctx, organizationID, user); err != nil {
+			return result, err
+		}
+	}
+
+	result, err = s.startSession(ctx, user, device, organizationID, client)
+	if err != nil {
+		return result, err
+	}
+
+	if remaining >= 0 {
+		result.Data.(map[string]interface{})["recovery_codes_remaining"] = remaining
+	}
+	return result, nil
+}
+
+// checkMembership makes sure the user may act in the organization.
+func (s *AuthService) checkMembership(ctx context.Context, organizationID string, user *entity.User) error {
+	if _, err := s.organizations.Membership(ctx, organizationID, user.ID); err != nil {
+		if errors.Is(err, errors.ErrNotFound.Code) {
+			return errors.ErrForbidden.WithMessage("not a member of the organization")
+		}
+		return err
+	}
+	return nil
+}
+
+// startSession records a new session for the user and issues its first pair
+// of tokens. With an organization the session and its tokens act in that
+// organization.
+func (s *AuthService) startSession(ctx context.Context, user *entity.User, device string, organizationID string, client dto.ClientInfo) (result dto.AuthResponse, err error) {
+	now := time.Now()
+	session := &entity.Session{
+		UserID:     user.ID,
+		Device:     device,
+		IPAddress:  client.IPAddress,
+		UserAgent:  client.UserAgent,
+		LastSeenAt: now,
+		ExpiresAt:  s.jwt.RefreshExpiresAt(now, now),
+	}
+	if organizationID != "" {
+		session.OrganizationID = &organizationID
+	}
+	if err := s.sessions.Create(ctx, session); err != nil {
+		return result, err
+	}
+
+	subject := jwt.Subject{UserID: user.ID, SessionID: session.ID, Version: user.TokenVersion, TenantID: organizationID}
+
 	// Generate JWT token
-	token, err := s.jwt.GenerateToken(user.ID)
+	token, err := s.jwt.GenerateToken(subject)
 	if err != nil {
 		return result, err
 	}
 
-	refreshToken, err := s.jwt.GenerateRefreshToken(user.ID)
+	refreshToken, err := s.jwt.GenerateRefreshToken(subject)
 	if err != nil {
 		return result, err
 	}
 
+	data := map[string]interface{}{
+		"id":         user.ID,
+		"session_id": session.ID,
+	}
+	if organizationID != "" {
+		data["organization_id"] = organizationID
+	}
+
 	return dto.AuthResponse{
 		Token:        token,
 		RefreshToken: refreshToken,
-		Data: map[string]interface{}{
-			"id": user.ID,
-		},
+		Data:         data,
 	}, nil
 }
 
-func (s *AuthService) RefreshToken(ctx context.Context, req dto.RenewalTokenRequest) (result dto.AuthResponse, err error) {
+func (s *AuthService) RefreshToken(ctx context.Context, req dto.RenewalTokenRequest, client dto.ClientInfo) (result dto.AuthResponse, err error) {
 	ctx, cancel := context.WithTimeout(ctx, s.contextTimeout)
 	defer cancel()
-	claims, err := s.jwt.ParseToken(req.RefreshToken)
+	// Rotation consumes the presented refresh token; replaying it later
+	// revokes the whole token family, which is the session.
+	newRefreshToken, claims, err := s.jwt.RotateRefreshToken(req.RefreshToken)
 	if err != nil {
-		return result, errors.ErrBadRequest.WithMessage("invalid refresh token").WithError(err)
-	}
-
-	if nbfRaw, ok := claims["nbf"]; ok {
-		if nbf, ok := nbfRaw.(float64); ok && time.Now().Before(time.Unix(int64(nbf), 0)) {
-			return result, errors.ErrUnauthorized.WithMessage("refresh token not yet valid")
+		if sessionID, ok := claims["sid"].(string); ok {
+			if revokeErr := s.sessions.Revoke(ctx, sessionID, time.Now()); revokeErr != nil {
+				s.logger.Error("Failed to revoke session", revokeErr, "session_id", sessionID)
+			}
 		}
+		return result, err
 	}
 
 	id, ok := claims["payload"].(string)
@@ -83,41 +280,238 @@ func (s *AuthService) RefreshToken(ctx context.Context, req dto.RenewalTokenRequ
 		return result, errors.ErrUnauthorized.WithMessage("invalid token payload")
 	}
 
+	sessionID, ok := claims["sid"].(string)
+	if !ok {
+		return result, errors.ErrUnauthorized.WithMessage("invalid token session")
+	}
+
 	user, err := s.repo.GetByID(ctx, id)
 	if err != nil {
 		return result, errors.ErrBadRequest.WithMessage("user not found")
 	}
 
-	// Generate new access token
-	newToken, err := s.jwt.GenerateToken(user.ID)
+	if !user.IsActive || jwt.TokenVersion(claims) < user.TokenVersion {
+		return result, errors.ErrUnauthorized.WithMessage("refresh token has been revoked")
+	}
+
+	// A session keeps the organization it was opened in, as long as the user
+	// is still a member of it.
+	session, err := s.sessions.GetByID(ctx, sessionID)
 	if err != nil {
+		return result, errors.ErrUnauthorized.WithMessage("invalid token session")
+	}
+	var organizationID string
+	if session.OrganizationID != nil {
+		organizationID = *session.OrganizationID
+		if err := s.checkMembership(ctx, organizationID, user); err != nil {
+			if revokeErr := s.sessions.Revoke(ctx, sessionID, time.Now()); revokeErr != nil {
+				s.logger.Error("Failed to revoke session", revokeErr, "session_id", sessionID)
+			}
+			return result, errors.ErrUnauthorized.WithMessage("organization access has been revoked")
+		}
+	}
+
+	now := time.Now()
+	if err := s.sessions.Touch(ctx, &entity.Session{
+		ID:         sessionID,
+		IPAddress:  client.IPAddress,
+		UserAgent:  client.UserAgent,
+		LastSeenAt: now,
+		ExpiresAt:  s.jwt.RefreshExpiresAt(session.CreatedAt, now),
+	}); err != nil {
 		return result, err
 	}
 
-	newRefreshToken, err := s.jwt.GenerateRefreshToken(user.ID)
+	// Generate new access token
+	newToken, err := s.jwt.GenerateToken(jwt.Subject{UserID: user.ID, SessionID: sessionID, Version: user.TokenVersion, TenantID: organizationID})
 	if err != nil {
 		return result, err
 	}
 
+	data := map[string]interface{}{
+		"id":         user.ID,
+		"session_id": sessionID,
+	}
+	if organizationID != "" {
+		data["organization_id"] = organizationID
+	}
+
 	result = dto.AuthResponse{
 		Token:        newToken,
 		RefreshToken: newRefreshToken,
-		Data: map[string]interface{}{
-			"id": user.ID,
-		},
+		Data:         data,
 	}
 	return
 }
 
 func (h *AuthService) Logout(c context.Context, accessToken string, req dto.RenewalTokenRequest) (err error) {
+	claims, err := h.jwt.ParseToken(accessToken)
+	if err != nil {
+		return err
+	}
+
+	// Blacklist Refresh Token, which must belong to the same user
+	userID, _ := claims["payload"].(string)
+	if err = h.jwt.RevokeRequestToken(req.RefreshToken, userID); err != nil {
+		return err
+	}
+
 	// Blacklist Access Token
 	if err = h.jwt.RevokeToken(accessToken); err != nil {
 		return errors.ErrInternalServer.WithMessage("failed to revoke access token")
 	}
 
-	// Blacklist Refresh Token
-	if err = h.jwt.RevokeRequestToken(req.RefreshToken); err != nil {
-		return errors.ErrInternalServer.WithMessage("failed to revoke refresh token").WithError(err)
+	if sessionID, ok := claims["sid"].(string); ok {
+		if err = h.sessions.Revoke(c, sessionID, time.Now()); err != nil {
+			return err
+		}
+		return h.jwt.RevokeTokenFamily(sessionID)
 	}
 	return nil
 }
+
+func (s *AuthService) ListSessions(ctx context.Context, userID string, currentSessionID string) (result []dto.SessionResponse, err error) {
+	ctx, cancel := context.WithTimeout(ctx, s.contextTimeout)
+	defer cancel()
+
+	sessions, err := s.sessions.ListActiveByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	result = make([]dto.SessionResponse, 0, len(sessions))
+	for _, session := range sessions {
+		result = append(result, dto.SessionResponse{
+			ID:             session.ID,
+			OrganizationID: session.OrganizationID,
+			Device:         session.Device,
+			IPAddress:      session.IPAddress,
+			UserAgent:      session.UserAgent,
+			CreatedAt:      session.CreatedAt,
+			LastSeenAt:     session.LastSeenAt,
+			ExpiresAt:      session.ExpiresAt,
+			Current:        session.ID == currentSessionID,
+		})
+	}
+	return result, nil
+}
+
+func (s *AuthService) RevokeSession(ctx context.Context, userID string, sessionID string) (err error) {
+	ctx, cancel := context.WithTimeout(ctx, s.contextTimeout)
+	defer cancel()
+
+	session, err := s.sessions.GetByID(ctx, sessionID)
+	if err != nil {
+		return err
+	}
+
+	// Sessions of other users are reported as missing rather than forbidden
+	// so that session ids cannot be probed.
+	if session.UserID != userID {
+		return errors.ErrNotFound.WithMessage("session not found")
+	}
+
+	if err := s.sessions.Revoke(ctx, session.ID, time.Now()); err != nil {
+		return err
+	}
+	return s.jwt.RevokeTokenFamily(session.ID)
+}
+
+// LogoutAll ends every session of the user and revokes all issued tokens.
+func (s *AuthService) LogoutAll(ctx context.Context, userID string) (err error) {
+	ctx, cancel := context.WithTimeout(ctx, s.contextTimeout)
+	defer cancel()
+
+	return s.revoker.RevokeAll(ctx, userID)
+}
+
+// ForgotPassword mails a single-use reset link when the email belongs to an
+// account. The outcome is never reported back so that callers cannot probe
+// which emails are registered.
+func (s *AuthService) ForgotPassword(ctx context.Context, req dto.ForgotPasswordRequest) (err error) {
+	ctx, cancel := context.WithTimeout(ctx, s.contextTimeout)
+	defer cancel()
+
+	// Only the lookup happens before responding, for known and unknown emails
+	// alike. Issuing and mailing the link runs in the background so the
+	// response time does not reveal whether the account exists.
+	user, err := s.repo.GetByEmail(ctx, req.Email)
+	if err == nil && user.IsActive {
+		go s.sendPasswordReset(user)
+	}
+	return nil
+}
+
+// sendPasswordReset issues a reset token for user and mails them the link.
+// Only the latest link of a user stays valid.
+func (s *AuthService) sendPasswordReset(user *entity.User) {
+	ctx, cancel := context.WithTimeout(context.Background(), s.contextTimeout)
+	defer cancel()
+
+	token, err := generateToken()
+	if err != nil {
+		s.logger.Error("Failed to generate password reset token", err, "user_id", user.ID)
+		return
+	}
+
+	var previous string
+	if err := s.cache.Get(ctx, "password-reset:user:"+user.ID, &previous); err == nil {
+		_ = s.cache.Delete(ctx, "password-reset:"+previous)
+	}
+
+	expiry, _ := time.ParseDuration(s.config.Password.ResetExpiry)
+	hash := hashToken(token)
+	if err := s.cache.Set(ctx, "password-reset:"+hash, user.ID, expiry); err != nil {
+		s.logger.Error("Failed to store password reset token", err, "user_id", user.ID)
+		return
+	}
+	if err := s.cache.Set(ctx, "password-reset:user:"+user.ID, hash, expiry); err != nil {
+		s.logger.Error("Failed to store password reset token", err, "user_id", user.ID)
+		return
+	}
+
+	link := fmt.Sprintf("%s?token=%s", s.config.Password.ResetURL, url.QueryEscape(token))
+	msg := mail.Message{
+		To:      user.Email,
+		Subject: "Reset your password",
+		Body: fmt.Sprintf("Hi %s,\n\nUse the link below to choose a new password. It expires in %s and can only be used once.\n\n%s\n\nIf you did not ask for a password reset you can ignore this email.\n",
+			user.Name, expiry, link),
+	}
+	if err := s.mailer.Send(context.Background(), msg); err != nil {
+		s.logger.Error("Failed to send password reset mail", err, "user_id", user.ID)
+	}
+}
+
+// ResetPassword consumes a reset token, sets the new password and ends every
+// existing session of the user, who must still be active.
+func (s *AuthService) ResetPassword(ctx context.Context, req dto.ResetPasswordRequest) (err error) {
+	ctx, cancel := context.WithTimeout(ctx, s.contextTimeout)
+	defer cancel()
+
+	var userID string
+	hash := hashToken(req.Token)
+	if err := s.cache.Take(ctx, "password-reset:"+hash, &userID); err != nil {
+		return errors.ErrBadRequest.WithMessage("invalid or expired reset token")
+	}
+	_ = s.cache.Delete(ctx, "password-reset:user:"+userID)
+
+	user, err := s.repo.GetByID(ctx, userID)
+	if err != nil {
+		return errors.ErrBadRequest.WithMessage("invalid or expired reset token")
+	}
+	// A user deactivated after asking for the link keeps their password.
+	if !user.IsActive {
+		return errors.ErrForbidden.WithMessage("user not active")
+	}
+
+	if err := user.SetPassword(ctx, req.Password); err != nil {
+		return errors.Wrap(errors.ErrBadRequest, err)
+	}
+	user.UpdatedAt = time.Now()
+
+	if err := s.repo.Update(ctx, user); err != nil {
+		return err
+	}
+
+	return s.revoker.RevokeAll(ctx, user.ID)
+}
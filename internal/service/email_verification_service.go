@@ -0,0 +1,123 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/HasanNugroho/gin-clean/config"
+	"github.com/HasanNugroho/gin-clean/internal/domain/entity"
+	"github.com/HasanNugroho/gin-clean/internal/domain/repository"
+	"github.com/HasanNugroho/gin-clean/internal/infrastructure/presistence/cache"
+	"github.com/HasanNugroho/gin-clean/internal/interfaces/http/dto"
+	"github.com/HasanNugroho/gin-clean/pkg/errors"
+	"github.com/HasanNugroho/gin-clean/pkg/jwt"
+	"github.com/HasanNugroho/gin-clean/pkg/logger"
+	"github.com/HasanNugroho/gin-clean/pkg/mail"
+)
+
+const emailVerificationPurpose = "email_verification"
+
+type EmailVerificationService struct {
+	repo           repository.UserRepository
+	cache          cache.Cache
+	mailer         mail.Sender
+	jwt            *jwt.TokenGenerator
+	logger         *logger.Logger
+	config         *config.Config
+	contextTimeout time.Duration
+}
+
+func NewEmailVerificationService(repo repository.UserRepository, cache cache.Cache, mailer mail.Sender, jwt *jwt.TokenGenerator, logger *logger.Logger, config *config.Config, timeout time.Duration) *EmailVerificationService {
+	return &EmailVerificationService{
+		repo:           repo,
+		cache:          cache,
+		mailer:         mailer,
+		jwt:            jwt,
+		logger:         logger,
+		config:         config,
+		contextTimeout: timeout,
+	}
+}
+
+// SendVerification mails a signed verification link to the user's current
+// email address. The link is bound to that address, so it stops working once
+// the email changes.
+func (s *EmailVerificationService) SendVerification(ctx context.Context, user *entity.User) (err error) {
+	expiry, _ := time.ParseDuration(s.config.Verify.Expiry)
+	token, err := s.jwt.GeneratePurposeToken(emailVerificationPurpose, user.ID, expiry, map[string]interface{}{
+		"email": user.Email,
+	})
+	if err != nil {
+		return err
+	}
+
+	link := fmt.Sprintf("%s?token=%s", s.config.Verify.URL, url.QueryEscape(token))
+	msg := mail.Message{
+		To:      user.Email,
+		Subject: "Verify your email address",
+		Body: fmt.Sprintf("Hi %s,\n\nPlease confirm your email address by opening the link below. It expires in %s.\n\n%s\n",
+			user.Name, expiry, link),
+	}
+
+	go func() {
+		if err := s.mailer.Send(context.Background(), msg); err != nil {
+			s.logger.Error("Failed to send verification mail", err, "user_id", user.ID)
+		}
+	}()
+
+	return nil
+}
+
+func (s *EmailVerificationService) VerifyEmail(ctx context.Context, token string) (err error) {
+	ctx, cancel := context.WithTimeout(ctx, s.contextTimeout)
+	defer cancel()
+
+	claims, err := s.jwt.ParsePurposeToken(emailVerificationPurpose, token)
+	if err != nil {
+		return errors.ErrBadRequest.WithMessage("invalid or expired verification link")
+	}
+
+	id, _ := claims["payload"].(string)
+	email, _ := claims["email"].(string)
+
+	user, err := s.repo.GetByID(ctx, id)
+	if err != nil || !strings.EqualFold(user.Email, email) {
+		return errors.ErrBadRequest.WithMessage("invalid or expired verification link")
+	}
+
+	if user.IsEmailVerified() {
+		return nil
+	}
+
+	now := time.Now()
+	user.EmailVerifiedAt = &now
+	return s.repo.Update(ctx, user)
+}
+
+// ResendVerification sends a fresh link to an unverified account. Requests are
+// throttled per email address, and the response never tells whether the
+// address is registered.
+func (s *EmailVerificationService) ResendVerification(ctx context.Context, req dto.ResendVerificationRequest) (err error) {
+	ctx, cancel := context.WithTimeout(ctx, s.contextTimeout)
+	defer cancel()
+
+	throttleKey := "email-verification:throttle:" + hashToken(strings.ToLower(req.Email))
+	if exists, err := s.cache.Exist(ctx, throttleKey); err == nil && exists > 0 {
+		return errors.ErrTooManyRequests.WithMessage("verification email was sent recently, please try again later")
+	}
+
+	interval, _ := time.ParseDuration(s.config.Verify.ResendInterval)
+	if err := s.cache.Set(ctx, throttleKey, true, interval); err != nil {
+		return errors.Wrap(errors.ErrInternalServer, err)
+	}
+
+	user, err := s.repo.GetByEmail(ctx, req.Email)
+	if err != nil || user.IsEmailVerified() {
+		return nil
+	}
+
+	return s.SendVerification(ctx, user)
+}
@@ -0,0 +1,255 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/HasanNugroho/gin-clean/config"
+	"github.com/HasanNugroho/gin-clean/internal/domain/entity"
+	"github.com/HasanNugroho/gin-clean/internal/domain/repository"
+	"github.com/HasanNugroho/gin-clean/internal/interfaces/http/dto"
+	"github.com/HasanNugroho/gin-clean/pkg/constants"
+	"github.com/HasanNugroho/gin-clean/pkg/errors"
+	"github.com/HasanNugroho/gin-clean/pkg/jwt"
+	"github.com/HasanNugroho/gin-clean/pkg/logger"
+	"github.com/HasanNugroho/gin-clean/pkg/mail"
+	"github.com/HasanNugroho/gin-clean/pkg/tenant"
+)
+
+const invitationPurpose = "invitation"
+
+type InvitationService struct {
+	repo           repository.InvitationRepository
+	tx             repository.TxManager
+	userRepo       repository.UserRepository
+	organizations  repository.OrganizationRepository
+	users          *UserService
+	mailer         mail.Sender
+	logger         *logger.Logger
+	jwt            *jwt.TokenGenerator
+	config         *config.Config
+	contextTimeout time.Duration
+}
+
+func NewInvitationService(repo repository.InvitationRepository, tx repository.TxManager, userRepo repository.UserRepository, organizations repository.OrganizationRepository, users *UserService, mailer mail.Sender, logger *logger.Logger, jwt *jwt.TokenGenerator, config *config.Config, timeout time.Duration) *InvitationService {
+	return &InvitationService{
+		repo:           repo,
+		tx:             tx,
+		userRepo:       userRepo,
+		organizations:  organizations,
+		users:          users,
+		mailer:         mailer,
+		logger:         logger,
+		jwt:            jwt,
+		config:         config,
+		contextTimeout: timeout,
+	}
+}
+
+// Invite mails an invitation link to an email that has no account yet. Within
+// an organization the invitee joins it with the given role, and existing
+// accounts may be invited too, to join it once they accept. The inviter must
+// be able to grant the role themselves.
+func (s *InvitationService) Invite(ctx context.Context, inviter *entity.User, req *dto.CreateInvitationRequest) (invitation *entity.Invitation, err error) {
+	ctx, cancel := context.WithTimeout(ctx, s.contextTimeout)
+	defer cancel()
+
+	role := req.Role
+	if role == "" {
+		role = constants.ROLE_USER
+	}
+	if err := s.users.checkRole(ctx, role); err != nil {
+		return nil, err
+	}
+	// Accepting grants the role without another check.
+	if err := s.users.checkGrant(ctx, inviter, role); err != nil {
+		return nil, err
+	}
+
+	organizationID, scoped := tenant.FromContext(ctx)
+	account, _ := s.userRepo.GetByEmail(tenant.Unscoped(ctx), req.Email)
+	if account != nil {
+		if !scoped {
+			return nil, errors.ErrConflict.WithMessage("an account with this email already exists")
+		}
+		if membership, _ := s.organizations.GetMembership(ctx, organizationID, account.ID); membership != nil {
+			return nil, errors.ErrConflict.WithMessage("user is already a member")
+		}
+	}
+	if existing, _ := s.repo.GetPendingByEmail(ctx, req.Email); existing != nil {
+		return nil, errors.ErrConflict.WithMessage("a pending invitation for this email already exists")
+	}
+
+	token, expiresAt, err := s.issueToken(req.Email)
+	if err != nil {
+		return nil, err
+	}
+
+	invitation = &entity.Invitation{
+		Email:     req.Email,
+		Role:      role,
+		InvitedBy: &inviter.ID,
+		TokenHash: hashToken(token),
+		ExpiresAt: expiresAt,
+	}
+	if scoped {
+		invitation.OrganizationID = &organizationID
+	}
+	if err := s.repo.Create(ctx, invitation); err != nil {
+		return nil, err
+	}
+
+	s.send(invitation, token, account != nil)
+	return invitation, nil
+}
+
+func (s *InvitationService) ListPending(ctx context.Context) (invitations []entity.Invitation, err error) {
+	ctx, cancel := context.WithTimeout(ctx, s.contextTimeout)
+	defer cancel()
+
+	return s.repo.ListPending(ctx)
+}
+
+// Resend mails a fresh link and extends the invitation, which also revives
+// expired ones. Links sent before stop working.
+func (s *InvitationService) Resend(ctx context.Context, id string) (err error) {
+	ctx, cancel := context.WithTimeout(ctx, s.contextTimeout)
+	defer cancel()
+
+	invitation, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if invitation.AcceptedAt != nil || invitation.RevokedAt != nil {
+		return errors.ErrConflict.WithMessage("invitation is no longer pending")
+	}
+
+	token, expiresAt, err := s.issueToken(invitation.Email)
+	if err != nil {
+		return err
+	}
+
+	invitation.ExpiresAt = expiresAt
+	if err := s.repo.Renew(ctx, invitation.ID, hashToken(token), expiresAt); err != nil {
+		return err
+	}
+
+	account, _ := s.userRepo.GetByEmail(tenant.Unscoped(ctx), invitation.Email)
+	s.send(invitation, token, account != nil && invitation.OrganizationID != nil)
+	return nil
+}
+
+func (s *InvitationService) Revoke(ctx context.Context, id string) (err error) {
+	ctx, cancel := context.WithTimeout(ctx, s.contextTimeout)
+	defer cancel()
+
+	return s.repo.Revoke(ctx, id, time.Now())
+}
+
+// Accept creates the account of the invitee with the email and role of the
+// invitation, or makes the existing account of the invitee a member of the
+// organization the invitation was sent from. The invitation is consumed even
+// if the caller retries, so a link opens at most one account.
+func (s *InvitationService) Accept(ctx context.Context, req *dto.AcceptInvitationRequest) (email string, err error) {
+	ctx, cancel := context.WithTimeout(ctx, s.contextTimeout)
+	defer cancel()
+
+	invalid := errors.ErrBadRequest.WithMessage("invalid or expired invitation")
+
+	if _, err := s.jwt.ParsePurposeToken(invitationPurpose, req.Token); err != nil {
+		return "", invalid
+	}
+
+	invitation, err := s.repo.GetByTokenHash(ctx, hashToken(req.Token))
+	if err != nil {
+		return "", invalid
+	}
+
+	// Claiming the invitation and creating the account succeed or fail
+	// together, so a failed attempt leaves the invitation usable.
+	err = s.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		claimed, err := s.repo.MarkAccepted(ctx, invitation.ID, time.Now())
+		if err != nil {
+			return err
+		}
+		if !claimed {
+			return invalid
+		}
+
+		// The account joins the organization the invitation was sent from.
+		if invitation.OrganizationID != nil {
+			ctx = tenant.WithTenant(ctx, *invitation.OrganizationID)
+
+			if account, _ := s.userRepo.GetByEmail(tenant.Unscoped(ctx), invitation.Email); account != nil {
+				return s.organizations.AddMember(ctx, &entity.Membership{
+					OrganizationID: *invitation.OrganizationID,
+					UserID:         account.ID,
+					Role:           invitation.Role,
+				})
+			}
+		}
+
+		if req.Name == "" || req.PhoneNumber == "" || req.Password == "" {
+			return errors.ErrBadRequest.WithMessage("name, phone_number and password are required to create the account")
+		}
+
+		_, err = s.users.createAccount(ctx, &dto.CreateUserRequest{
+			Name:          req.Name,
+			Email:         invitation.Email,
+			PhoneNumber:   req.PhoneNumber,
+			Password:      req.Password,
+			Role:          invitation.Role,
+			EmailVerified: true,
+		})
+		return err
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return invitation.Email, nil
+}
+
+// issueToken signs a new invitation token for the email.
+func (s *InvitationService) issueToken(email string) (token string, expiresAt time.Time, err error) {
+	// The nonce keeps tokens issued within the same second apart.
+	nonce, err := generateToken()
+	if err != nil {
+		return "", expiresAt, errors.Wrap(errors.ErrInternalServer, err)
+	}
+
+	expiry, _ := time.ParseDuration(s.config.Invitation.Expiry)
+	expiresAt = time.Now().Add(expiry)
+	token, err = s.jwt.GeneratePurposeToken(invitationPurpose, email, expiry, map[string]interface{}{
+		"nonce": nonce,
+	})
+	if err != nil {
+		return "", expiresAt, err
+	}
+
+	return token, expiresAt, nil
+}
+
+// send mails the link of the invitation. Invitees who already have an account
+// are asked to join the organization instead of choosing a password.
+func (s *InvitationService) send(invitation *entity.Invitation, token string, join bool) {
+	link := fmt.Sprintf("%s?token=%s", s.config.Invitation.URL, url.QueryEscape(token))
+	action := "create an account. Open the link below to choose your password"
+	if join {
+		action = "join an organization with your existing account. Open the link below to accept"
+	}
+	msg := mail.Message{
+		To:      invitation.Email,
+		Subject: fmt.Sprintf("You have been invited to %s", s.config.Server.Name),
+		Body: fmt.Sprintf("Hi,\n\nYou have been invited to %s. It expires on %s and can only be used once.\n\n%s\n\nIf you were not expecting this invitation you can ignore this email.\n",
+			action, invitation.ExpiresAt.Format(time.RFC1123), link),
+	}
+
+	go func() {
+		if err := s.mailer.Send(context.Background(), msg); err != nil {
+			s.logger.Error("Failed to send invitation mail", err, "invitation_id", invitation.ID)
+		}
+	}()
+}
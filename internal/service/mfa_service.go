@@ -0,0 +1,281 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/HasanNugroho/gin-clean/config"
+	"github.com/HasanNugroho/gin-clean/internal/domain/entity"
+	"github.com/HasanNugroho/gin-clean/internal/domain/repository"
+	"github.com/HasanNugroho/gin-clean/internal/infrastructure/presistence/cache"
+	"github.com/HasanNugroho/gin-clean/internal/interfaces/http/dto"
+	"github.com/HasanNugroho/gin-clean/pkg/errors"
+	"github.com/HasanNugroho/gin-clean/pkg/totp"
+)
+
+const (
+	// mfaEnrollmentExpiry bounds how long a generated secret waits for its
+	// first code before the enrollment has to start over.
+	mfaEnrollmentExpiry = 10 * time.Minute
+	// mfaMaxAttempts is the number of wrong codes accepted per user within
+	// mfaAttemptWindow.
+	mfaMaxAttempts   = 5
+	mfaAttemptWindow = 5 * time.Minute
+	// mfaSkew accepts codes from one time step before and after the current.
+	mfaSkew = 1
+	// mfaRecoveryCodeCount is the size of every generated recovery code set.
+	mfaRecoveryCodeCount = 10
+)
+
+type MFAService struct {
+	repo           repository.UserRepository
+	recoveryCodes  repository.RecoveryCodeRepository
+	cache          cache.Cache
+	config         *config.Config
+	contextTimeout time.Duration
+}
+
+func NewMFAService(repo repository.UserRepository, recoveryCodes repository.RecoveryCodeRepository, cache cache.Cache, config *config.Config, timeout time.Duration) *MFAService {
+	return &MFAService{
+		repo:           repo,
+		recoveryCodes:  recoveryCodes,
+		cache:          cache,
+		config:         config,
+		contextTimeout: timeout,
+	}
+}
+
+// Enroll generates a new TOTP secret for the user. It only takes effect once
+// Confirm receives a valid code for it.
+func (s *MFAService) Enroll(ctx context.Context, userID string) (result dto.MFAEnrollResponse, err error) {
+	ctx, cancel := context.WithTimeout(ctx, s.contextTimeout)
+	defer cancel()
+
+	user, err := s.repo.GetByID(ctx, userID)
+	if err != nil {
+		return result, err
+	}
+
+	if user.MFAEnabled {
+		return result, errors.ErrConflict.WithMessage("MFA is already enabled")
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return result, errors.Wrap(errors.ErrInternalServer, err)
+	}
+
+	if err := s.cache.Set(ctx, "mfa:enroll:"+user.ID, secret, mfaEnrollmentExpiry); err != nil {
+		return result, errors.Wrap(errors.ErrInternalServer, err)
+	}
+
+	return dto.MFAEnrollResponse{
+		Secret:     secret,
+		OTPAuthURI: totp.URI(s.config.Server.Name, user.Email, secret),
+	}, nil
+}
+
+// Confirm enables MFA once the user proves their authenticator produces valid
+// codes for the pending secret, and hands out the first set of recovery codes.
+func (s *MFAService) Confirm(ctx context.Context, userID string, req dto.MFACodeRequest) (result dto.MFARecoveryCodesResponse, err error) {
+	ctx, cancel := context.WithTimeout(ctx, s.contextTimeout)
+	defer cancel()
+
+	user, err := s.repo.GetByID(ctx, userID)
+	if err != nil {
+		return result, err
+	}
+
+	var secret string
+	if err := s.cache.Get(ctx, "mfa:enroll:"+user.ID, &secret); err != nil {
+		return result, errors.ErrBadRequest.WithMessage("no pending MFA enrollment")
+	}
+
+	if err := s.verify(ctx, user.ID, secret, req.Code); err != nil {
+		return result, err
+	}
+
+	codes, err := s.replaceRecoveryCodes(ctx, user.ID)
+	if err != nil {
+		return result, err
+	}
+
+	user.MFASecret = secret
+	user.MFAEnabled = true
+	if err := s.repo.Update(ctx, user); err != nil {
+		return result, err
+	}
+
+	_ = s.cache.Delete(ctx, "mfa:enroll:"+user.ID)
+
+	return dto.MFARecoveryCodesResponse{RecoveryCodes: codes}, nil
+}
+
+// RegenerateRecoveryCodes replaces the user's recovery codes with a new set;
+// codes of the old set stop working immediately.
+func (s *MFAService) RegenerateRecoveryCodes(ctx context.Context, userID string, req dto.MFACodeRequest) (result dto.MFARecoveryCodesResponse, err error) {
+	ctx, cancel := context.WithTimeout(ctx, s.contextTimeout)
+	defer cancel()
+
+	user, err := s.repo.GetByID(ctx, userID)
+	if err != nil {
+		return result, err
+	}
+
+	if err := s.VerifyCode(ctx, user, req.Code); err != nil {
+		return result, err
+	}
+
+	codes, err := s.replaceRecoveryCodes(ctx, user.ID)
+	if err != nil {
+		return result, err
+	}
+
+	return dto.MFARecoveryCodesResponse{RecoveryCodes: codes}, nil
+}
+
+func (s *MFAService) Disable(ctx context.Context, userID string, req dto.MFACodeRequest) (err error) {
+	ctx, cancel := context.WithTimeout(ctx, s.contextTimeout)
+	defer cancel()
+
+	user, err := s.repo.GetByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if !user.MFAEnabled {
+		return errors.ErrBadRequest.WithMessage("MFA is not enabled")
+	}
+
+	if s.config.MFA.IsRequiredFor(string(user.Role)) {
+		return errors.ErrForbidden.WithMessage("MFA is required for this account")
+	}
+
+	if err := s.VerifyCode(ctx, user, req.Code); err != nil {
+		return err
+	}
+
+	user.MFASecret = ""
+	user.MFAEnabled = false
+	if err := s.repo.Update(ctx, user); err != nil {
+		return err
+	}
+
+	return s.recoveryCodes.DeleteByUser(ctx, user.ID)
+}
+
+// VerifyCode checks a code against the user's enrolled secret.
+func (s *MFAService) VerifyCode(ctx context.Context, user *entity.User, code string) error {
+	if !user.MFAEnabled {
+		return errors.ErrBadRequest.WithMessage("MFA is not enabled")
+	}
+	return s.verify(ctx, user.ID, user.MFASecret, code)
+}
+
+// VerifyRecoveryCode consumes one of the user's recovery codes and returns
+// how many unused codes remain.
+func (s *MFAService) VerifyRecoveryCode(ctx context.Context, user *entity.User, code string) (remaining int, err error) {
+	if !user.MFAEnabled {
+		return 0, errors.ErrBadRequest.WithMessage("MFA is not enabled")
+	}
+
+	if err := s.checkAttempts(ctx, user.ID); err != nil {
+		return 0, err
+	}
+
+	codes, err := s.recoveryCodes.ListUnusedByUser(ctx, user.ID)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, candidate := range codes {
+		if !candidate.Matches(code) {
+			continue
+		}
+
+		used, err := s.recoveryCodes.MarkUsed(ctx, candidate.ID, time.Now())
+		if err != nil {
+			return 0, err
+		}
+		if !used {
+			return 0, errors.ErrUnauthorized.WithMessage("recovery code has already been used")
+		}
+
+		_ = s.cache.Delete(ctx, mfaAttemptsKey(user.ID))
+		return len(codes) - 1, nil
+	}
+
+	return 0, errors.ErrUnauthorized.WithMessage("invalid recovery code")
+}
+
+// replaceRecoveryCodes stores a fresh set of hashed recovery codes and returns
+// the plain codes, which are never shown again.
+func (s *MFAService) replaceRecoveryCodes(ctx context.Context, userID string) ([]string, error) {
+	plain := make([]string, 0, mfaRecoveryCodeCount)
+	codes := make([]entity.RecoveryCode, 0, mfaRecoveryCodeCount)
+	for i := 0; i < mfaRecoveryCodeCount; i++ {
+		code, err := generateRecoveryCode()
+		if err != nil {
+			return nil, errors.Wrap(errors.ErrInternalServer, err)
+		}
+
+		recoveryCode, err := entity.NewRecoveryCode(userID, code)
+		if err != nil {
+			return nil, errors.Wrap(errors.ErrInternalServer, err)
+		}
+
+		plain = append(plain, code)
+		codes = append(codes, *recoveryCode)
+	}
+
+	if err := s.recoveryCodes.Replace(ctx, userID, codes); err != nil {
+		return nil, err
+	}
+
+	return plain, nil
+}
+
+// checkAttempts counts a verification attempt against the user's budget of
+// mfaMaxAttempts per mfaAttemptWindow, shared by TOTP and recovery codes.
+func (s *MFAService) checkAttempts(ctx context.Context, userID string) error {
+	attemptsKey := mfaAttemptsKey(userID)
+	attempts := s.cache.Incr(ctx, attemptsKey)
+	if attempts == 1 {
+		_ = s.cache.Expire(ctx, attemptsKey, mfaAttemptWindow)
+	}
+	if attempts > mfaMaxAttempts {
+		return errors.ErrTooManyRequests.WithMessage("too many MFA attempts, please try again later")
+	}
+	return nil
+}
+
+// verify validates a code with attempt limiting and replay protection: every
+// time step can be used only once per user.
+func (s *MFAService) verify(ctx context.Context, userID, secret, code string) error {
+	if err := s.checkAttempts(ctx, userID); err != nil {
+		return err
+	}
+
+	counter, ok := totp.Validate(secret, code, time.Now(), mfaSkew)
+	if !ok {
+		return errors.ErrUnauthorized.WithMessage("invalid MFA code")
+	}
+
+	// A code stays acceptable for (2*skew+1) periods; remember it that long.
+	usedKey := fmt.Sprintf("mfa:used:%s:%d", userID, counter)
+	fresh, err := s.cache.SetNX(ctx, usedKey, true, (2*mfaSkew+1)*totp.Period)
+	if err != nil {
+		return errors.Wrap(errors.ErrInternalServer, err)
+	}
+	if !fresh {
+		return errors.ErrUnauthorized.WithMessage("MFA code has already been used")
+	}
+
+	_ = s.cache.Delete(ctx, mfaAttemptsKey(userID))
+	return nil
+}
+
+func mfaAttemptsKey(userID string) string {
+	return "mfa:attempts:" + userID
+}
@@ -0,0 +1,81 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/HasanNugroho/gin-clean/internal/domain/entity"
+	"github.com/HasanNugroho/gin-clean/internal/domain/repository"
+	"github.com/HasanNugroho/gin-clean/internal/interfaces/http/dto"
+	"github.com/HasanNugroho/gin-clean/pkg/constants"
+	"github.com/HasanNugroho/gin-clean/pkg/errors"
+)
+
+type OrganizationService struct {
+	repo           repository.OrganizationRepository
+	contextTimeout time.Duration
+}
+
+func NewOrganizationService(repo repository.OrganizationRepository, timeout time.Duration) *OrganizationService {
+	return &OrganizationService{
+		repo:           repo,
+		contextTimeout: timeout,
+	}
+}
+
+// Create sets up an organization with the owner as its first admin.
+func (s *OrganizationService) Create(ctx context.Context, owner *entity.User, req *dto.CreateOrganizationRequest) (organization *entity.Organization, err error) {
+	ctx, cancel := context.WithTimeout(ctx, s.contextTimeout)
+	defer cancel()
+
+	if existing, _ := s.repo.GetBySlug(ctx, req.Slug); existing != nil {
+		return nil, errors.ErrConflict.WithMessage("slug is already in use")
+	}
+
+	organization = &entity.Organization{
+		Name: req.Name,
+		Slug: req.Slug,
+	}
+	membership := &entity.Membership{
+		UserID: owner.ID,
+		Role:   constants.ROLE_ADMIN,
+	}
+	if err := s.repo.Create(ctx, organization, membership); err != nil {
+		return nil, err
+	}
+
+	return organization, nil
+}
+
+// ListMine returns the organizations the user belongs to, with their role in
+// each.
+func (s *OrganizationService) ListMine(ctx context.Context, userID string) (memberships []entity.Membership, err error) {
+	ctx, cancel := context.WithTimeout(ctx, s.contextTimeout)
+	defer cancel()
+
+	return s.repo.ListMemberships(ctx, userID)
+}
+
+func (s *OrganizationService) ListMembers(ctx context.Context, organizationID string) (memberships []entity.Membership, err error) {
+	ctx, cancel := context.WithTimeout(ctx, s.contextTimeout)
+	defer cancel()
+
+	return s.repo.ListMembers(ctx, organizationID)
+}
+
+// RemoveMember takes away the access of a user to the organization. Tokens
+// issued for the organization stop working on their next request since the
+// membership is checked on every request.
+func (s *OrganizationService) RemoveMember(ctx context.Context, organizationID string, userID string) (err error) {
+	ctx, cancel := context.WithTimeout(ctx, s.contextTimeout)
+	defer cancel()
+
+	return s.repo.RemoveMember(ctx, organizationID, userID)
+}
+
+func (s *OrganizationService) Membership(ctx context.Context, organizationID string, userID string) (membership *entity.Membership, err error) {
+	ctx, cancel := context.WithTimeout(ctx, s.contextTimeout)
+	defer cancel()
+
+	return s.repo.GetMembership(ctx, organizationID, userID)
+}
@@ -0,0 +1,100 @@
+package service
+
+import (
+	"context"
+	"slices"
+	"sync"
+
+	"github.com/HasanNugroho/gin-clean/internal/domain/entity"
+	domain "github.com/HasanNugroho/gin-clean/internal/domain/service"
+	"github.com/HasanNugroho/gin-clean/pkg/errors"
+)
+
+// Policy decides whether actor may perform action on resource.
+type Policy interface {
+	Allow(ctx context.Context, actor *entity.User, action string, resource domain.Resource) (bool, error)
+}
+
+type PolicyFunc func(ctx context.Context, actor *entity.User, action string, resource domain.Resource) (bool, error)
+
+func (f PolicyFunc) Allow(ctx context.Context, actor *entity.User, action string, resource domain.Resource) (bool, error) {
+	return f(ctx, actor, action, resource)
+}
+
+// PolicyService answers whether an actor may perform an action on a resource
+// using the policy registered for the resource type. Resource types without a
+// policy are denied.
+//
+// Modules register the policies of their resource types when they are built,
+// e.g. in container.RegisterModul:
+//
+//	policies := ctn.Get("policy-service").(*service.PolicyService)
+//	policies.Register("orders", policies.SelfOrPermission(domain.ActionRead))
+type PolicyService struct {
+	rbac *RBACService
+
+	mu       sync.RWMutex
+	policies map[string]Policy
+}
+
+func NewPolicyService(rbac *RBACService) *PolicyService {
+	s := &PolicyService{
+		rbac:     rbac,
+		policies: make(map[string]Policy),
+	}
+
+	s.Register(domain.ResourceUsers, s.SelfOrPermission(domain.ActionRead, domain.ActionUpdate, domain.ActionDelete))
+	return s
+}
+
+// Register sets the policy of a resource type, replacing any previous one.
+func (s *PolicyService) Register(resourceType string, policy Policy) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.policies[resourceType] = policy
+}
+
+func (s *PolicyService) Authorize(ctx context.Context, actor *entity.User, action string, resource domain.Resource) (err error) {
+	s.mu.RLock()
+	policy, ok := s.policies[resource.Type]
+	s.mu.RUnlock()
+
+	if !ok || actor == nil {
+		return errors.ErrForbidden.WithMessage("access denied")
+	}
+
+	allowed, err := policy.Allow(ctx, actor, action, resource)
+	if err != nil {
+		return err
+	}
+	if !allowed {
+		return errors.ErrForbidden.WithMessage("not allowed to " + action + " this " + resource.Type)
+	}
+
+	return nil
+}
+
+// Permission allows an action when the role of the actor grants the
+// "<type>:<action>" permission. domain.ActionUpdatePrivileges maps to the update
+// permission.
+func (s *PolicyService) Permission() Policy {
+	return PolicyFunc(func(ctx context.Context, actor *entity.User, action string, resource domain.Resource) (bool, error) {
+		if action == domain.ActionUpdatePrivileges {
+			action = domain.ActionUpdate
+		}
+		return s.rbac.HasPermission(ctx, actor, resource.Type+":"+action)
+	})
+}
+
+// SelfOrPermission additionally allows the given actions to the owner of the
+// resource.
+func (s *PolicyService) SelfOrPermission(selfActions ...string) Policy {
+	permission := s.Permission()
+	return PolicyFunc(func(ctx context.Context, actor *entity.User, action string, resource domain.Resource) (bool, error) {
+		if resource.OwnerID != "" && resource.OwnerID == actor.ID && slices.Contains(selfActions, action) {
+			return true, nil
+		}
+		return permission.Allow(ctx, actor, action, resource)
+	})
+}
@@ -0,0 +1,188 @@
+package service
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"github.com/HasanNugroho/gin-clean/internal/domain/entity"
+	"github.com/HasanNugroho/gin-clean/internal/domain/repository"
+	"github.com/HasanNugroho/gin-clean/internal/infrastructure/presistence/cache"
+	"github.com/HasanNugroho/gin-clean/internal/interfaces/http/dto"
+	"github.com/HasanNugroho/gin-clean/pkg/constants"
+	"github.com/HasanNugroho/gin-clean/pkg/errors"
+)
+
+const (
+	// rbacVersionKey is bumped on every change to roles or their permissions,
+	// which invalidates all cached permission sets at once.
+	rbacVersionKey = "rbac:version"
+	// rbacCacheExpiry bounds how long a resolved permission set is kept.
+	rbacCacheExpiry = time.Hour
+)
+
+// cachedPermissions is the permission set of a role as resolved at a given
+// rbac version.
+type cachedPermissions struct {
+	Version     int      `json:"version"`
+	Permissions []string `json:"permissions"`
+}
+
+type RBACService struct {
+	roles          repository.RoleRepository
+	cache          cache.Cache
+	contextTimeout time.Duration
+}
+
+func NewRBACService(roles repository.RoleRepository, cache cache.Cache, timeout time.Duration) *RBACService {
+	return &RBACService{
+		roles:          roles,
+		cache:          cache,
+		contextTimeout: timeout,
+	}
+}
+
+func (s *RBACService) ListRoles(ctx context.Context) (roles []entity.Role, err error) {
+	ctx, cancel := context.WithTimeout(ctx, s.contextTimeout)
+	defer cancel()
+
+	return s.roles.List(ctx)
+}
+
+func (s *RBACService) GetRole(ctx context.Context, name string) (role *entity.Role, err error) {
+	ctx, cancel := context.WithTimeout(ctx, s.contextTimeout)
+	defer cancel()
+
+	return s.roles.GetByName(ctx, name)
+}
+
+func (s *RBACService) CreateRole(ctx context.Context, req *dto.CreateRoleRequest) (err error) {
+	ctx, cancel := context.WithTimeout(ctx, s.contextTimeout)
+	defer cancel()
+
+	if existing, _ := s.roles.GetByName(ctx, req.Name); existing != nil {
+		return errors.ErrConflict.WithMessage("role already exists")
+	}
+
+	return s.roles.Create(ctx, &entity.Role{
+		Name:        req.Name,
+		Description: req.Description,
+	})
+}
+
+// DeleteRole removes a role that no user holds. The built-in roles cannot be
+// deleted.
+func (s *RBACService) DeleteRole(ctx context.Context, name string) (err error) {
+	ctx, cancel := context.WithTimeout(ctx, s.contextTimeout)
+	defer cancel()
+
+	role := constants.Role(name)
+	if role.IsValidRole() {
+		return errors.ErrForbidden.WithMessage("built-in roles cannot be deleted")
+	}
+
+	inUse, err := s.roles.InUse(ctx, name)
+	if err != nil {
+		return err
+	}
+	if inUse {
+		return errors.ErrConflict.WithMessage("role is still assigned to users")
+	}
+
+	if err := s.roles.Delete(ctx, name); err != nil {
+		return err
+	}
+
+	return s.invalidate(ctx)
+}
+
+func (s *RBACService) ListPermissions(ctx context.Context) (permissions []entity.Permission, err error) {
+	ctx, cancel := context.WithTimeout(ctx, s.contextTimeout)
+	defer cancel()
+
+	return s.roles.ListPermissions(ctx)
+}
+
+func (s *RBACService) GrantPermissions(ctx context.Context, role string, req *dto.GrantPermissionsRequest) (err error) {
+	ctx, cancel := context.WithTimeout(ctx, s.contextTimeout)
+	defer cancel()
+
+	if _, err := s.roles.GetByName(ctx, role); err != nil {
+		return err
+	}
+
+	known, err := s.roles.ListPermissions(ctx)
+	if err != nil {
+		return err
+	}
+	for _, permission := range req.Permissions {
+		if !slices.ContainsFunc(known, func(p entity.Permission) bool { return p.Name == permission }) {
+			return errors.ErrBadRequest.WithMessage("unknown permission: " + permission)
+		}
+	}
+
+	if err := s.roles.Grant(ctx, role, req.Permissions); err != nil {
+		return err
+	}
+
+	return s.invalidate(ctx)
+}
+
+func (s *RBACService) RevokePermission(ctx context.Context, role string, permission string) (err error) {
+	ctx, cancel := context.WithTimeout(ctx, s.contextTimeout)
+	defer cancel()
+
+	if err := s.roles.Revoke(ctx, role, permission); err != nil {
+		return err
+	}
+
+	return s.invalidate(ctx)
+}
+
+// HasPermission reports whether the role of the user grants the permission.
+// The resolved permission set is cached per user until the role of the user
+// or any role assignment changes.
+func (s *RBACService) HasPermission(ctx context.Context, user *entity.User, permission string) (ok bool, err error) {
+	ctx, cancel := context.WithTimeout(ctx, s.contextTimeout)
+	defer cancel()
+
+	permissions, err := s.permissionsOf(ctx, user)
+	if err != nil {
+		return false, err
+	}
+
+	return slices.Contains(permissions, permission), nil
+}
+
+// permissionsOf resolves the permissions of the role the user acts with. They
+// are cached by role rather than by user, since within an organization the
+// same user acts with the role of their membership there.
+func (s *RBACService) permissionsOf(ctx context.Context, user *entity.User) ([]string, error) {
+	var version int
+	_ = s.cache.Get(ctx, rbacVersionKey, &version)
+
+	key := "rbac:permissions:" + string(user.Role)
+	var cached cachedPermissions
+	if err := s.cache.Get(ctx, key, &cached); err == nil && cached.Version == version {
+		return cached.Permissions, nil
+	}
+
+	permissions, err := s.roles.PermissionsOf(ctx, string(user.Role))
+	if err != nil {
+		return nil, err
+	}
+
+	_ = s.cache.Set(ctx, key, cachedPermissions{
+		Version:     version,
+		Permissions: permissions,
+	}, rbacCacheExpiry)
+
+	return permissions, nil
+}
+
+func (s *RBACService) invalidate(ctx context.Context) error {
+	if s.cache.Incr(ctx, rbacVersionKey) == 0 {
+		return errors.ErrInternalServer.WithMessage("failed to invalidate cached permissions")
+	}
+	return nil
+}
@@ -0,0 +1,39 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/HasanNugroho/gin-clean/internal/domain/repository"
+	"github.com/HasanNugroho/gin-clean/pkg/jwt"
+)
+
+// TokenRevoker invalidates every token issued to a user at once, e.g. on
+// logout-everywhere, password change or deactivation.
+type TokenRevoker struct {
+	repo     repository.UserRepository
+	sessions repository.SessionRepository
+	jwt      *jwt.TokenGenerator
+}
+
+func NewTokenRevoker(repo repository.UserRepository, sessions repository.SessionRepository, jwt *jwt.TokenGenerator) *TokenRevoker {
+	return &TokenRevoker{
+		repo:     repo,
+		sessions: sessions,
+		jwt:      jwt,
+	}
+}
+
+// RevokeAll bumps the user's token version and ends all of their sessions.
+func (r *TokenRevoker) RevokeAll(ctx context.Context, userID string) error {
+	version, err := r.repo.IncrementTokenVersion(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if err := r.jwt.RevokeUserTokens(userID, version); err != nil {
+		return err
+	}
+
+	return r.sessions.RevokeAllByUser(ctx, userID, time.Now())
+}
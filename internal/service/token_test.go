@@ -0,0 +1,25 @@
+package service
+
+import (
+	"regexp"
+	"testing"
+)
+
+func TestGenerateRecoveryCode(t *testing.T) {
+	format := regexp.MustCompile(`^[a-z2-7]{5}-[a-z2-7]{5}$`)
+
+	seen := make(map[string]bool)
+	for i := 0; i < 100; i++ {
+		code, err := generateRecoveryCode()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !format.MatchString(code) {
+			t.Fatalf("code %q does not have the form xxxxx-xxxxx", code)
+		}
+		if seen[code] {
+			t.Fatalf("code %q was generated twice", code)
+		}
+		seen[code] = true
+	}
+}
@@ -0,0 +1,74 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/HasanNugroho/gin-clean/internal/domain/repository"
+	"github.com/HasanNugroho/gin-clean/pkg/logger"
+)
+
+// UserRetention purges soft-deleted users once their grace period is over.
+type UserRetention struct {
+	repo           repository.UserRepository
+	logger         *logger.Logger
+	enabled        bool
+	gracePeriod    time.Duration
+	contextTimeout time.Duration
+}
+
+func NewUserRetention(repo repository.UserRepository, logger *logger.Logger, enabled bool, gracePeriod, timeout time.Duration) *UserRetention {
+	return &UserRetention{
+		repo:           repo,
+		logger:         logger,
+		enabled:        enabled,
+		gracePeriod:    gracePeriod,
+		contextTimeout: timeout,
+	}
+}
+
+// Enabled reports whether users are purged automatically at all.
+func (r *UserRetention) Enabled() bool {
+	return r.enabled
+}
+
+// PurgeAt returns when a user deleted at deletedAt is purged, or nil if never.
+func (r *UserRetention) PurgeAt(deletedAt time.Time) *time.Time {
+	if !r.Enabled() {
+		return nil
+	}
+	purgeAt := deletedAt.Add(r.gracePeriod)
+	return &purgeAt
+}
+
+// PurgeExpired permanently deletes the users deleted longer than the grace
+// period ago and returns their number.
+func (r *UserRetention) PurgeExpired(ctx context.Context) (int64, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.contextTimeout)
+	defer cancel()
+
+	return r.repo.PurgeDeletedBefore(ctx, time.Now().Add(-r.gracePeriod))
+}
+
+// Run purges expired users right away and then every interval until ctx is
+// done.
+func (r *UserRetention) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		purged, err := r.PurgeExpired(ctx)
+		switch {
+		case err != nil:
+			r.logger.Error("Failed to purge deleted users", err)
+		case purged > 0:
+			r.logger.Info("Purged deleted users", "count", purged)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -2,48 +2,139 @@ package service
 
 import (
 	"context"
+	"slices"
 	"time"
 
 	"github.com/HasanNugroho/gin-clean/internal/domain/entity"
 	"github.com/HasanNugroho/gin-clean/internal/domain/repository"
 	"github.com/HasanNugroho/gin-clean/internal/interfaces/http/dto"
+	"github.com/HasanNugroho/gin-clean/pkg/constants"
+	"github.com/HasanNugroho/gin-clean/pkg/cursor"
 	"github.com/HasanNugroho/gin-clean/pkg/errors"
+	"github.com/HasanNugroho/gin-clean/pkg/highlight"
+	"github.com/HasanNugroho/gin-clean/pkg/tenant"
 )
 
+// defaultPageLimit is the page size of listings that do not ask for one.
+const defaultPageLimit = 20
+
 type UserService struct {
 	repo           repository.UserRepository
+	tx             repository.TxManager
+	roles          repository.RoleRepository
+	organizations  repository.OrganizationRepository
+	revoker        *TokenRevoker
+	verification   *EmailVerificationService
+	cursors        *cursor.Codec
+	retention      *UserRetention
 	contextTimeout time.Duration
 }
 
-func NewUserService(repo repository.UserRepository, timeout time.Duration) *UserService {
+// userCursor is the content of the opaque cursors of user listings. Exactly
+// one of the positions is set, telling which way to page from it.
+type userCursor struct {
+	After  *repository.Cursor `json:"a,omitempty"`
+	Before *repository.Cursor `json:"b,omitempty"`
+}
+
+func NewUserService(repo repository.UserRepository, tx repository.TxManager, roles repository.RoleRepository, organizations repository.OrganizationRepository, revoker *TokenRevoker, verification *EmailVerificationService, cursors *cursor.Codec, retention *UserRetention, timeout time.Duration) *UserService {
 	return &UserService{
 		repo:           repo,
+		tx:             tx,
+		roles:          roles,
+		organizations:  organizations,
+		revoker:        revoker,
+		verification:   verification,
+		cursors:        cursors,
+		retention:      retention,
 		contextTimeout: timeout,
 	}
 }
 
-func (u *UserService) Create(ctx context.Context, req *dto.CreateUserRequest) (err error) {
+// Create creates a user on behalf of actor, who must be able to grant the
+// requested role.
+func (u *UserService) Create(ctx context.Context, actor *entity.User, req *dto.CreateUserRequest) (user *entity.User, err error) {
 	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
 	defer cancel()
 
-	existing, err := u.repo.GetByEmail(ctx, req.Email)
-	if existing != nil {
-		return errors.Wrap(errors.ErrConflict, err)
+	role := req.Role
+	if role == "" {
+		role = constants.ROLE_USER
+	}
+	if err := u.checkGrant(ctx, actor, role); err != nil {
+		return nil, err
 	}
 
-	user := &entity.User{
+	return u.createAccount(ctx, req)
+}
+
+// createAccount creates the user described by req, whose role has been
+// authorized by the caller.
+func (u *UserService) createAccount(ctx context.Context, req *dto.CreateUserRequest) (user *entity.User, err error) {
+	user = &entity.User{
 		Name:        req.Name,
 		Email:       req.Email,
 		PhoneNumber: req.PhoneNumber,
 		Role:        req.Role,
 		IsActive:    true,
 	}
+	if user.Role == "" {
+		user.Role = constants.ROLE_USER
+	}
+	if req.EmailVerified {
+		now := time.Now()
+		user.EmailVerifiedAt = &now
+	}
+
+	if err := u.checkRole(ctx, user.Role); err != nil {
+		return nil, err
+	}
 
-	if err := user.SetPassword(ctx, req.Password); err != nil {
+	if err := u.create(ctx, user, req.Password); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// Register creates a self-service account, which always gets the user role.
+func (u *UserService) Register(ctx context.Context, req *dto.RegisterUserRequest) (user *entity.User, err error) {
+	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
+	defer cancel()
+
+	user = &entity.User{
+		Name:        req.Name,
+		Email:       req.Email,
+		PhoneNumber: req.PhoneNumber,
+		Role:        constants.ROLE_USER,
+		IsActive:    true,
+	}
+
+	if err := u.create(ctx, user, req.Password); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+func (u *UserService) create(ctx context.Context, user *entity.User, password string) (err error) {
+	if err := user.SetPassword(ctx, password); err != nil {
 		return errors.Wrap(errors.ErrBadRequest, err)
 	}
 
-	return u.repo.Create(ctx, user)
+	err = u.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		// Emails are unique across all organizations.
+		if existing, _ := u.repo.GetByEmail(tenant.Unscoped(ctx), user.Email); existing != nil {
+			return errors.ErrConflict.WithMessage("email is already in use")
+		}
+		return u.repo.Create(ctx, user)
+	})
+	if err != nil {
+		return err
+	}
+
+	if user.IsEmailVerified() {
+		return nil
+	}
+	return u.verification.SendVerification(ctx, user)
 }
 
 func (u *UserService) GetById(ctx context.Context, id string) (user *entity.User, err error) {
@@ -60,29 +151,370 @@ func (u *UserService) GetByEmail(ctx context.Context, email string) (user *entit
 	return u.repo.GetByEmail(ctx, email)
 }
 
-func (u *UserService) Update(ctx context.Context, id string, updatedUser *dto.UpdateUserRequest) (err error) {
+// List returns a page of users along with the number of users matching the
+// query across all pages. Missing page and limit are filled in on query.
+func (u *UserService) List(ctx context.Context, query *dto.ListUsersQuery) (users []entity.User, total int64, err error) {
+	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
+	defer cancel()
+
+	if query.Page == 0 {
+		query.Page = 1
+	}
+	if query.Limit == 0 {
+		query.Limit = defaultPageLimit
+	}
+
+	filter, err := userFilter(query)
+	if err != nil {
+		return nil, 0, err
+	}
+	filter.Sort = query.Sort
+	filter.Offset = (query.Page - 1) * query.Limit
+	filter.Limit = query.Limit
+
+	return u.repo.List(ctx, filter)
+}
+
+// ListByCursor returns the page of users at the cursor of query, in order of
+// creation, along with the cursors of the next and previous pages. These are
+// empty at the ends of the listing. A missing limit is filled in on query.
+func (u *UserService) ListByCursor(ctx context.Context, query *dto.ListUsersQuery) (users []entity.User, next, prev string, err error) {
+	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
+	defer cancel()
+
+	if query.Limit == 0 {
+		query.Limit = defaultPageLimit
+	}
+	if query.Sort != "" || query.Page != 0 {
+		return nil, "", "", errors.ErrBadRequest.WithMessage("sort and page cannot be used with cursor pagination")
+	}
+
+	filter, err := userFilter(query)
+	if err != nil {
+		return nil, "", "", err
+	}
+
+	page := repository.CursorPage{Limit: query.Limit}
+	if query.Cursor != "" {
+		var position userCursor
+		if err := u.cursors.Decode(query.Cursor, &position); err != nil || (position.After == nil) == (position.Before == nil) {
+			return nil, "", "", errors.ErrBadRequest.WithMessage("invalid cursor")
+		}
+		page.After, page.Before = position.After, position.Before
+	}
+
+	users, result, err := u.repo.ListByCursor(ctx, filter, page)
+	if err != nil {
+		return nil, "", "", err
+	}
+
+	if result.Next != nil {
+		if next, err = u.cursors.Encode(userCursor{After: result.Next}); err != nil {
+			return nil, "", "", errors.Wrap(errors.ErrInternalServer, err)
+		}
+	}
+	if result.Prev != nil {
+		if prev, err = u.cursors.Encode(userCursor{Before: result.Prev}); err != nil {
+			return nil, "", "", errors.Wrap(errors.ErrInternalServer, err)
+		}
+	}
+
+	return users, next, prev, nil
+}
+
+// Search finds users by their name, email or phone number, tolerating
+// partial words and typos. A missing limit is filled in on query.
+func (u *UserService) Search(ctx context.Context, query *dto.SearchUsersQuery) (results []dto.UserSearchResult, err error) {
+	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
+	defer cancel()
+
+	if query.Limit == 0 {
+		query.Limit = defaultPageLimit
+	}
+
+	matches, err := u.repo.Search(ctx, query.Query, query.Limit)
+	if err != nil {
+		return nil, err
+	}
+
+	terms := highlight.Terms(query.Query)
+	results = make([]dto.UserSearchResult, len(matches))
+	for i, match := range matches {
+		highlights := map[string]string{}
+		for field, value := range map[string]string{
+			"name":         match.User.Name,
+			"email":        match.User.Email,
+			"phone_number": match.User.PhoneNumber,
+		} {
+			if marked, ok := highlight.Mark(value, terms); ok {
+				highlights[field] = marked
+			}
+		}
+
+		results[i] = dto.UserSearchResult{
+			User:       match.User,
+			Rank:       match.Rank,
+			Highlights: highlights,
+		}
+	}
+
+	return results, nil
+}
+
+// userFilter turns the filters of a listing query into a repository filter.
+func userFilter(query *dto.ListUsersQuery) (repository.UserFilter, error) {
+	if query.CreatedFrom != nil && query.CreatedTo != nil && query.CreatedTo.Before(*query.CreatedFrom) {
+		return repository.UserFilter{}, errors.ErrBadRequest.WithMessage("created_to must not be before created_from")
+	}
+
+	return repository.UserFilter{
+		Role:        query.Role,
+		IsActive:    query.IsActive,
+		CreatedFrom: query.CreatedFrom,
+		CreatedTo:   query.CreatedTo,
+		Search:      query.Search,
+	}, nil
+}
+
+// Update replaces the user with updatedUser on behalf of actor and returns the
+// result.
+func (u *UserService) Update(ctx context.Context, actor *entity.User, id string, updatedUser *dto.UpdateUserRequest) (user *entity.User, err error) {
 	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
 	defer cancel()
 
 	existing, err := u.repo.GetByID(ctx, id)
 	if err != nil {
-		return errors.Wrap(errors.ErrNotFound, err)
+		return nil, errors.Wrap(errors.ErrNotFound, err)
 	}
 	if existing == nil {
-		return errors.ErrNotFound
+		return nil, errors.ErrNotFound
 	}
 
+	if err := u.replace(ctx, actor, existing, updatedUser); err != nil {
+		return nil, err
+	}
+	return existing, nil
+}
+
+// Patch applies a merge patch to the user on behalf of actor and returns the
+// result. Members missing from the patch keep their current value.
+func (u *UserService) Patch(ctx context.Context, actor *entity.User, id string, patch *dto.PatchUserRequest) (user *entity.User, err error) {
+	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
+	defer cancel()
+
+	existing, err := u.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	updatedUser := userRequest(existing)
+	updatedUser.Version = patch.Version
+	if patch.Name.Present() {
+		updatedUser.Name = patch.Name.Value
+	}
+	if patch.Email.Present() {
+		updatedUser.Email = patch.Email.Value
+	}
+	if patch.PhoneNumber.Present() {
+		updatedUser.PhoneNumber = patch.PhoneNumber.Value
+	}
+	if patch.Password.Present() {
+		updatedUser.Password = patch.Password.Value
+	}
+	if patch.Role.Present() {
+		updatedUser.Role = patch.Role.Value
+	}
+	if patch.IsActive.Present() {
+		updatedUser.IsActive = &patch.IsActive.Value
+	}
+
+	if err := u.replace(ctx, actor, existing, updatedUser); err != nil {
+		return nil, err
+	}
+	return existing, nil
+}
+
+// replace checks that an update is based on the current version of the user,
+// that actor may grant a new role and, on behalf of an organization, that it
+// stays within what the organization may change, then applies it.
+func (u *UserService) replace(ctx context.Context, actor *entity.User, existing *entity.User, updatedUser *dto.UpdateUserRequest) (err error) {
+	if err := checkVersion(existing, updatedUser.Version); err != nil {
+		return err
+	}
+
+	if existing.Role != updatedUser.Role {
+		if err := u.checkGrant(ctx, actor, updatedUser.Role); err != nil {
+			return err
+		}
+	}
+
+	if _, scoped := tenant.FromContext(ctx); scoped {
+		// The credentials, email and active state of an account are never an
+		// organization's to change, since they grant access beyond it.
+		if existing.Email != updatedUser.Email ||
+			updatedUser.Password != "" ||
+			existing.IsActive != *updatedUser.IsActive {
+			return errors.ErrForbidden.WithMessage("email, password and active state cannot be changed within an organization")
+		}
+
+		// Neither is the profile of a user who also belongs to other
+		// organizations, only their role in this one.
+		if existing.Name != updatedUser.Name || existing.PhoneNumber != updatedUser.PhoneNumber {
+			memberships, err := u.organizations.ListMemberships(ctx, existing.ID)
+			if err != nil {
+				return err
+			}
+			if len(memberships) > 1 {
+				return errors.ErrForbidden.WithMessage("user belongs to other organizations, only their role can be changed")
+			}
+		}
+	}
+
+	return u.update(ctx, existing, updatedUser)
+}
+
+func (u *UserService) update(ctx context.Context, existing *entity.User, updatedUser *dto.UpdateUserRequest) (err error) {
+	id := existing.ID
+
+	if existing.Role != updatedUser.Role {
+		if err := u.checkRole(ctx, updatedUser.Role); err != nil {
+			return err
+		}
+	}
+
+	emailChanged := existing.Email != updatedUser.Email
+
+	if updatedUser.Password != "" {
+		if err := existing.SetPassword(ctx, updatedUser.Password); err != nil {
+			return errors.Wrap(errors.ErrBadRequest, err)
+		}
+	}
+
+	// Deactivation and changes to the identity, credentials or privileges of
+	// the user invalidate every token issued so far.
+	revoke := (existing.IsActive && !*updatedUser.IsActive) ||
+		emailChanged ||
+		updatedUser.Password != "" ||
+		existing.Role != updatedUser.Role
+
 	existing.Name = updatedUser.Name
 	existing.Email = updatedUser.Email
 	existing.PhoneNumber = updatedUser.PhoneNumber
 	existing.Role = updatedUser.Role
-	existing.IsActive = updatedUser.IsActive
+	existing.IsActive = *updatedUser.IsActive
 	existing.UpdatedAt = time.Now()
+	if emailChanged {
+		existing.EmailVerifiedAt = nil
+	}
+
+	version := existing.Version
+	err = u.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		// A retried attempt starts over from the version the update is
+		// based on.
+		existing.Version = version
+		if emailChanged {
+			if taken, _ := u.repo.GetByEmail(tenant.Unscoped(ctx), updatedUser.Email); taken != nil {
+				return errors.ErrConflict.WithMessage("email is already in use")
+			}
+		}
+		return u.repo.Update(ctx, existing)
+	})
+	if err != nil {
+		return err
+	}
 
-	return u.repo.Update(ctx, existing)
+	if emailChanged {
+		if err := u.verification.SendVerification(ctx, existing); err != nil {
+			return err
+		}
+	}
+
+	if revoke {
+		return u.revoker.RevokeAll(ctx, id)
+	}
+	return nil
 }
 
-func (u *UserService) Delete(ctx context.Context, id string) (err error) {
+// userRequest returns the update that leaves user as it is.
+func userRequest(user *entity.User) *dto.UpdateUserRequest {
+	isActive := user.IsActive
+	return &dto.UpdateUserRequest{
+		Name:        user.Name,
+		Email:       user.Email,
+		PhoneNumber: user.PhoneNumber,
+		Role:        user.Role,
+		IsActive:    &isActive,
+		Version:     user.Version,
+	}
+}
+
+// checkVersion fails unless version is the current version of user.
+func checkVersion(user *entity.User, version int) error {
+	if version != user.Version {
+		return errors.ErrPreconditionFailed.WithMessage("user has been modified since it was read")
+	}
+	return nil
+}
+
+// UpdateProfile applies the fields a user may change on their own account.
+func (u *UserService) UpdateProfile(ctx context.Context, id string, req *dto.UpdateProfileRequest) (err error) {
+	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
+	defer cancel()
+
+	existing, err := u.repo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	update := userRequest(existing)
+	if req.Name != nil {
+		update.Name = *req.Name
+	}
+	if req.Email != nil {
+		update.Email = *req.Email
+	}
+	if req.PhoneNumber != nil {
+		update.PhoneNumber = *req.PhoneNumber
+	}
+
+	// Whoever controls the email can reset the password, so changing it takes
+	// the current password like changing the password does.
+	if update.Email != existing.Email && !existing.VerifyPassword(req.CurrentPassword) {
+		return errors.ErrBadRequest.WithMessage("current password is incorrect")
+	}
+
+	return u.update(ctx, existing, update)
+}
+
+// ChangePassword replaces the password after checking the current one. Every
+// session of the user is ended, so all devices have to log in again.
+func (u *UserService) ChangePassword(ctx context.Context, id string, req *dto.ChangePasswordRequest) (err error) {
+	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
+	defer cancel()
+
+	existing, err := u.repo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if !existing.VerifyPassword(req.CurrentPassword) {
+		return errors.ErrBadRequest.WithMessage("current password is incorrect")
+	}
+
+	if err := existing.SetPassword(ctx, req.NewPassword); err != nil {
+		return errors.Wrap(errors.ErrBadRequest, err)
+	}
+	existing.UpdatedAt = time.Now()
+
+	if err := u.repo.Update(ctx, existing); err != nil {
+		return err
+	}
+
+	return u.revoker.RevokeAll(ctx, id)
+}
+
+// Delete deletes the user if it is still at version.
+func (u *UserService) Delete(ctx context.Context, id string, version int) (err error) {
 	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
 	defer cancel()
 
@@ -93,6 +525,140 @@ func (u *UserService) Delete(ctx context.Context, id string) (err error) {
 	if existing == nil {
 		return errors.ErrNotFound
 	}
+	if err := checkVersion(existing, version); err != nil {
+		return err
+	}
+
+	// Within an organization only the membership goes away. Tokens for the
+	// organization fail the membership check from then on, while the sessions
+	// of the user in other organizations stay untouched.
+	if _, scoped := tenant.FromContext(ctx); scoped {
+		return u.repo.Delete(ctx, id)
+	}
+
+	if err := u.revoker.RevokeAll(ctx, id); err != nil {
+		return err
+	}
 
 	return u.repo.Delete(ctx, id)
 }
+
+// ListDeleted returns a page of the soft-deleted users and their total number.
+// Missing page and limit are filled in on query.
+func (u *UserService) ListDeleted(ctx context.Context, query *dto.ListDeletedUsersQuery) (users []dto.DeletedUserResponse, total int64, err error) {
+	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
+	defer cancel()
+
+	if err := checkUnscoped(ctx); err != nil {
+		return nil, 0, err
+	}
+
+	if query.Page == 0 {
+		query.Page = 1
+	}
+	if query.Limit == 0 {
+		query.Limit = defaultPageLimit
+	}
+
+	deleted, total, err := u.repo.ListDeleted(ctx, (query.Page-1)*query.Limit, query.Limit)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	users = make([]dto.DeletedUserResponse, len(deleted))
+	for i, user := range deleted {
+		users[i] = dto.DeletedUserResponse{
+			User:      user,
+			DeletedAt: user.DeletedAt.Time,
+			PurgeAt:   u.retention.PurgeAt(user.DeletedAt.Time),
+		}
+	}
+	return users, total, nil
+}
+
+// Restore undeletes a soft-deleted user, unless their email has been taken
+// since, and returns them. They have to log in again.
+func (u *UserService) Restore(ctx context.Context, id string) (user *entity.User, err error) {
+	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
+	defer cancel()
+
+	if err := checkUnscoped(ctx); err != nil {
+		return nil, err
+	}
+
+	err = u.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		user, err = u.repo.GetDeletedByID(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		if taken, _ := u.repo.GetByEmail(ctx, user.Email); taken != nil {
+			return errors.ErrConflict.WithMessage("email is in use by another user")
+		}
+
+		return u.repo.Restore(ctx, id)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Restoring counts as a change of the user, so it is read back with its
+	// new version.
+	return u.repo.GetByID(ctx, id)
+}
+
+// Purge permanently deletes a soft-deleted user along with their sessions and
+// memberships.
+func (u *UserService) Purge(ctx context.Context, id string) (err error) {
+	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
+	defer cancel()
+
+	if err := checkUnscoped(ctx); err != nil {
+		return err
+	}
+
+	return u.repo.Purge(ctx, id)
+}
+
+// checkUnscoped forbids acting on deleted users from within an organization,
+// whose members they no longer are.
+func checkUnscoped(ctx context.Context) error {
+	if _, scoped := tenant.FromContext(ctx); scoped {
+		return errors.ErrForbidden.WithMessage("deleted users cannot be managed within an organization")
+	}
+	return nil
+}
+
+func (u *UserService) checkRole(ctx context.Context, role constants.Role) error {
+	if _, err := u.roles.GetByName(ctx, string(role)); err != nil {
+		if errors.Is(err, errors.ErrNotFound.Code) {
+			return errors.ErrBadRequest.WithMessage("unknown role: " + string(role))
+		}
+		return err
+	}
+	return nil
+}
+
+// checkGrant makes sure actor may hand out role: either they manage roles, or
+// the role grants no permission they do not hold themselves. Otherwise anyone
+// allowed to update users could make a user, themselves included, an admin.
+func (u *UserService) checkGrant(ctx context.Context, actor *entity.User, role constants.Role) error {
+	held, err := u.roles.PermissionsOf(ctx, string(actor.Role))
+	if err != nil {
+		return err
+	}
+	if slices.Contains(held, constants.PERMISSION_ROLES_MANAGE) {
+		return nil
+	}
+
+	granted, err := u.roles.PermissionsOf(ctx, string(role))
+	if err != nil {
+		return err
+	}
+	for _, permission := range granted {
+		if !slices.Contains(held, permission) {
+			return errors.ErrForbidden.WithMessage("cannot grant role " + string(role) + ": it has permission " + permission + ", which you do not have")
+		}
+	}
+	return nil
+}
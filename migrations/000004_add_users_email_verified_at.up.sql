@@ -0,0 +1,5 @@
+ALTER TABLE users ADD COLUMN email_verified_at TIMESTAMPTZ;
+
+-- Accounts created before email verification existed are treated as verified
+-- so that enabling EMAIL_VERIFICATION_REQUIRED does not lock them out.
+UPDATE users SET email_verified_at = created_at WHERE email_verified_at IS NULL;
@@ -0,0 +1,3 @@
+ALTER TABLE users
+    ADD COLUMN mfa_enabled BOOLEAN NOT NULL DEFAULT FALSE,
+    ADD COLUMN mfa_secret TEXT;
@@ -0,0 +1,13 @@
+DROP INDEX IF EXISTS idx_users_role;
+
+CREATE TYPE role AS ENUM ('admin', 'user');
+
+UPDATE users SET role = 'user' WHERE role NOT IN ('admin', 'user');
+ALTER TABLE users DROP CONSTRAINT IF EXISTS fk_users_role;
+ALTER TABLE users ALTER COLUMN role DROP DEFAULT;
+ALTER TABLE users ALTER COLUMN role TYPE role USING role::role;
+ALTER TABLE users ALTER COLUMN role SET DEFAULT 'user';
+
+DROP TABLE IF EXISTS role_permissions;
+DROP TABLE IF EXISTS permissions;
+DROP TABLE IF EXISTS roles;
@@ -0,0 +1,41 @@
+CREATE TABLE roles (
+    name TEXT PRIMARY KEY,
+    description TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE TABLE permissions (
+    name TEXT PRIMARY KEY,
+    description TEXT NOT NULL DEFAULT ''
+);
+
+CREATE TABLE role_permissions (
+    role_name TEXT NOT NULL REFERENCES roles(name) ON DELETE CASCADE,
+    permission_name TEXT NOT NULL REFERENCES permissions(name) ON DELETE CASCADE,
+    PRIMARY KEY (role_name, permission_name)
+);
+
+INSERT INTO roles (name, description) VALUES
+    ('admin', 'Administrator'),
+    ('user', 'Regular user');
+
+INSERT INTO permissions (name, description) VALUES
+    ('users:create', 'Create users'),
+    ('users:read', 'View any user'),
+    ('users:update', 'Update any user'),
+    ('users:delete', 'Delete any user'),
+    ('roles:read', 'View roles and permissions'),
+    ('roles:manage', 'Create and delete roles and change their permissions');
+
+INSERT INTO role_permissions (role_name, permission_name)
+SELECT 'admin', name FROM permissions;
+
+-- Roles are rows now instead of a fixed enum
+ALTER TABLE users ALTER COLUMN role DROP DEFAULT;
+ALTER TABLE users ALTER COLUMN role TYPE TEXT USING role::TEXT;
+ALTER TABLE users ALTER COLUMN role SET DEFAULT 'user';
+ALTER TABLE users ADD CONSTRAINT fk_users_role FOREIGN KEY (role) REFERENCES roles(name);
+DROP TYPE role;
+
+-- Indexes
+CREATE INDEX idx_users_role ON users(role);
@@ -0,0 +1,6 @@
+DELETE FROM permissions WHERE name IN ('organizations:create', 'organizations:manage');
+
+ALTER TABLE sessions DROP COLUMN IF EXISTS organization_id;
+
+DROP TABLE IF EXISTS memberships;
+DROP TABLE IF EXISTS organizations;
@@ -0,0 +1,29 @@
+CREATE TABLE organizations (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    name TEXT NOT NULL,
+    slug TEXT UNIQUE NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE TABLE memberships (
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    role TEXT NOT NULL DEFAULT 'user' REFERENCES roles(name),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (organization_id, user_id)
+);
+
+ALTER TABLE sessions
+    ADD COLUMN organization_id UUID REFERENCES organizations(id) ON DELETE CASCADE;
+
+INSERT INTO permissions (name, description) VALUES
+    ('organizations:create', 'Create organizations'),
+    ('organizations:manage', 'Add and remove members of the current organization');
+
+INSERT INTO role_permissions (role_name, permission_name) VALUES
+    ('admin', 'organizations:create'),
+    ('admin', 'organizations:manage');
+
+-- Indexes
+CREATE INDEX idx_memberships_user_id ON memberships(user_id);
@@ -0,0 +1,3 @@
+DELETE FROM permissions WHERE name = 'invitations:manage';
+
+DROP TABLE IF EXISTS invitations;
@@ -0,0 +1,23 @@
+CREATE TABLE invitations (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    email TEXT NOT NULL,
+    role TEXT NOT NULL DEFAULT 'user' REFERENCES roles(name),
+    organization_id UUID REFERENCES organizations(id) ON DELETE CASCADE,
+    invited_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    token_hash TEXT UNIQUE NOT NULL,
+    expires_at TIMESTAMPTZ NOT NULL,
+    accepted_at TIMESTAMPTZ,
+    revoked_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+INSERT INTO permissions (name, description) VALUES
+    ('invitations:manage', 'Invite users and manage pending invitations');
+
+INSERT INTO role_permissions (role_name, permission_name) VALUES
+    ('admin', 'invitations:manage');
+
+-- Indexes
+CREATE INDEX idx_invitations_email ON invitations(email);
+CREATE INDEX idx_invitations_organization_id ON invitations(organization_id);
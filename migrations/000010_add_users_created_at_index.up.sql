@@ -0,0 +1,2 @@
+-- Keyset pagination seeks and walks users by (created_at, id).
+CREATE INDEX idx_users_created_at_id ON users(created_at, id);
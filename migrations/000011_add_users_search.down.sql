@@ -0,0 +1,10 @@
+DROP INDEX IF EXISTS idx_users_phone_number_trgm;
+DROP INDEX IF EXISTS idx_users_email_trgm;
+DROP INDEX IF EXISTS idx_users_name_trgm;
+DROP INDEX IF EXISTS idx_users_search_vector;
+
+ALTER TABLE users DROP COLUMN IF EXISTS search_vector;
+
+CREATE INDEX idx_users_name ON users(name);
+
+DROP EXTENSION IF EXISTS pg_trgm;
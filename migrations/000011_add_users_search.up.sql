@@ -0,0 +1,16 @@
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+ALTER TABLE users ADD COLUMN search_vector TSVECTOR GENERATED ALWAYS AS (
+    setweight(to_tsvector('simple', coalesce(name, '')), 'A') ||
+    setweight(to_tsvector('simple', coalesce(email, '')), 'B') ||
+    setweight(to_tsvector('simple', coalesce(phone_number, '')), 'C')
+) STORED;
+
+-- The trigram index also serves the prefix and infix lookups the plain name
+-- index was there for.
+DROP INDEX IF EXISTS idx_users_name;
+
+CREATE INDEX idx_users_search_vector ON users USING GIN (search_vector);
+CREATE INDEX idx_users_name_trgm ON users USING GIN (name gin_trgm_ops);
+CREATE INDEX idx_users_email_trgm ON users USING GIN (email gin_trgm_ops);
+CREATE INDEX idx_users_phone_number_trgm ON users USING GIN (phone_number gin_trgm_ops);
@@ -0,0 +1,5 @@
+DELETE FROM permissions WHERE name IN ('users:restore', 'users:purge');
+
+-- Fails while a deleted user shares the email of another user.
+DROP INDEX IF EXISTS idx_users_email_active;
+ALTER TABLE users ADD CONSTRAINT users_email_key UNIQUE (email);
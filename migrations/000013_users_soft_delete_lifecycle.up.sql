@@ -0,0 +1,12 @@
+-- Deleted users keep their row until purged, so only active users need a
+-- unique email.
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_email_key;
+CREATE UNIQUE INDEX idx_users_email_active ON users(email) WHERE deleted_at IS NULL;
+
+INSERT INTO permissions (name, description) VALUES
+    ('users:restore', 'List and restore deleted users'),
+    ('users:purge', 'Permanently delete deleted users');
+
+INSERT INTO role_permissions (role_name, permission_name) VALUES
+    ('admin', 'users:restore'),
+    ('admin', 'users:purge');
@@ -0,0 +1,19 @@
+package constants
+
+// Permissions checked by the HTTP layer. They are seeded by the migrations and
+// granted to roles at runtime.
+const (
+	PERMISSION_USERS_CREATE  = "users:create"
+	PERMISSION_USERS_READ    = "users:read"
+	PERMISSION_USERS_UPDATE  = "users:update"
+	PERMISSION_USERS_DELETE  = "users:delete"
+	PERMISSION_USERS_RESTORE = "users:restore"
+	PERMISSION_USERS_PURGE   = "users:purge"
+	PERMISSION_ROLES_READ    = "roles:read"
+	PERMISSION_ROLES_MANAGE  = "roles:manage"
+
+	PERMISSION_ORGANIZATIONS_CREATE = "organizations:create"
+	PERMISSION_ORGANIZATIONS_MANAGE = "organizations:manage"
+
+	PERMISSION_INVITATIONS_MANAGE = "invitations:manage"
+)
@@ -0,0 +1,86 @@
+package cursor
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+type position struct {
+	CreatedAt time.Time `json:"created_at"`
+	ID        string    `json:"id"`
+}
+
+func TestRoundTrip(t *testing.T) {
+	codec := NewCodec([]byte("secret"))
+	want := position{CreatedAt: time.Date(2025, 1, 2, 3, 4, 5, 6, time.UTC), ID: "42"}
+
+	encoded, err := codec.Encode(want)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.ContainsAny(encoded, "+/=") {
+		t.Errorf("cursor %q is not URL safe", encoded)
+	}
+
+	var got position
+	if err := codec.Decode(encoded, &got); err != nil {
+		t.Fatal(err)
+	}
+	if !got.CreatedAt.Equal(want.CreatedAt) || got.ID != want.ID {
+		t.Errorf("Decode = %+v, want %+v", got, want)
+	}
+}
+
+func TestDecodeRejectsTampering(t *testing.T) {
+	codec := NewCodec([]byte("secret"))
+	encoded, err := codec.Encode(position{ID: "42"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	payload, signature, _ := strings.Cut(encoded, ".")
+
+	forged, err := codec.Encode(position{ID: "43"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	forgedPayload, _, _ := strings.Cut(forged, ".")
+
+	otherKey, err := NewCodec([]byte("other")).Encode(position{ID: "42"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := map[string]string{
+		"empty":              "",
+		"no signature":       payload,
+		"swapped payload":    forgedPayload + "." + signature,
+		"truncated":          encoded[:len(encoded)-2],
+		"invalid base64":     payload + ".***",
+		"signed by other":    otherKey,
+		"signature only":     "." + signature,
+		"extra separator":    encoded + ".x",
+		"payload not base64": "!!." + signature,
+	}
+	for name, cursor := range tests {
+		t.Run(name, func(t *testing.T) {
+			var got position
+			if err := codec.Decode(cursor, &got); err != ErrInvalid {
+				t.Errorf("Decode(%q) = %v, want ErrInvalid", cursor, err)
+			}
+		})
+	}
+}
+
+func TestDecodeRejectsMismatchedType(t *testing.T) {
+	codec := NewCodec([]byte("secret"))
+	encoded, err := codec.Encode("not an object")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got position
+	if err := codec.Decode(encoded, &got); err != ErrInvalid {
+		t.Errorf("Decode = %v, want ErrInvalid", err)
+	}
+}
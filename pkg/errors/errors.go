@@ -37,12 +37,16 @@ func New(code, message string, status int, err error) *AppError {
 }
 
 var (
-	ErrNotFound       = base("NOT_FOUND", http.StatusNotFound)
-	ErrUnauthorized   = base("UNAUTHORIZED", http.StatusUnauthorized)
-	ErrForbidden      = base("FORBIDDEN", http.StatusForbidden)
-	ErrBadRequest     = base("BAD_REQUEST", http.StatusBadRequest)
-	ErrInternalServer = base("INTERNAL_SERVER_ERROR", http.StatusInternalServerError)
-	ErrConflict       = base("CONFLICT", http.StatusConflict)
+	ErrNotFound             = base("NOT_FOUND", http.StatusNotFound)
+	ErrUnauthorized         = base("UNAUTHORIZED", http.StatusUnauthorized)
+	ErrForbidden            = base("FORBIDDEN", http.StatusForbidden)
+	ErrBadRequest           = base("BAD_REQUEST", http.StatusBadRequest)
+	ErrInternalServer       = base("INTERNAL_SERVER_ERROR", http.StatusInternalServerError)
+	ErrConflict             = base("CONFLICT", http.StatusConflict)
+	ErrPreconditionFailed   = base("PRECONDITION_FAILED", http.StatusPreconditionFailed)
+	ErrUnprocessable        = base("UNPROCESSABLE_ENTITY", http.StatusUnprocessableEntity)
+	ErrPreconditionRequired = base("PRECONDITION_REQUIRED", http.StatusPreconditionRequired)
+	ErrTooManyRequests      = base("TOO_MANY_REQUESTS", http.StatusTooManyRequests)
 )
 
 func base(code string, status int) *AppError {
@@ -62,24 +66,31 @@ func Wrap(base *AppError, err error) *AppError {
 	}
 }
 
+// The With* helpers return a modified copy, so they are safe to call on the
+// shared Err* values.
+
 func (e *AppError) WithMessage(msg string) *AppError {
-	e.Message = msg
-	return e
+	c := *e
+	c.Message = msg
+	return &c
 }
 
 func (e *AppError) WithCode(code string) *AppError {
-	e.Code = code
-	return e
+	c := *e
+	c.Code = code
+	return &c
 }
 
 func (e *AppError) WithStatus(status int) *AppError {
-	e.Status = status
-	return e
+	c := *e
+	c.Status = status
+	return &c
 }
 
 func (e *AppError) WithError(err error) *AppError {
-	e.Err = err
-	return e
+	c := *e
+	c.Err = err
+	return &c
 }
 
 func Custom(code, message string, status int) *AppError {
@@ -114,6 +125,14 @@ func defaultMessage(code string) string {
 		return "Bad request"
 	case "CONFLICT":
 		return "Conflict"
+	case "PRECONDITION_FAILED":
+		return "Precondition failed"
+	case "PRECONDITION_REQUIRED":
+		return "Precondition required"
+	case "UNPROCESSABLE_ENTITY":
+		return "Unprocessable entity"
+	case "TOO_MANY_REQUESTS":
+		return "Too many requests"
 	case "INTERNAL_SERVER_ERROR":
 		return "Internal server error"
 	default:
@@ -0,0 +1,65 @@
+// Package highlight marks where search terms occur in a text.
+package highlight
+
+import (
+	"html"
+	"strings"
+	"unicode"
+)
+
+const (
+	openTag  = "<mark>"
+	closeTag = "</mark>"
+)
+
+// Terms splits a search query into the words to highlight.
+func Terms(query string) []string {
+	return strings.FieldsFunc(strings.ToLower(query), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// Mark wraps the case-insensitive occurrences of terms in value in <mark>
+// tags. The rest of value is HTML escaped so the result can be rendered as is.
+// ok reports whether any term occurs.
+func Mark(value string, terms []string) (marked string, ok bool) {
+	text := []rune(value)
+	lower := make([]rune, len(text))
+	for i, r := range text {
+		lower[i] = unicode.ToLower(r)
+	}
+
+	matched := make([]bool, len(text))
+	for _, term := range terms {
+		needle := []rune(term)
+		if len(needle) == 0 {
+			continue
+		}
+		for i := 0; i+len(needle) <= len(lower); i++ {
+			if string(lower[i:i+len(needle)]) == term {
+				for j := i; j < i+len(needle); j++ {
+					matched[j] = true
+				}
+				ok = true
+			}
+		}
+	}
+	if !ok {
+		return html.EscapeString(value), false
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(text); {
+		j := i
+		for j < len(text) && matched[j] == matched[i] {
+			j++
+		}
+		segment := html.EscapeString(string(text[i:j]))
+		if matched[i] {
+			segment = openTag + segment + closeTag
+		}
+		b.WriteString(segment)
+		i = j
+	}
+	return b.String(), true
+}
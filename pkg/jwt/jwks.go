@@ -0,0 +1,116 @@
+package jwt
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"sort"
+	"time"
+)
+
+// JWK is the public part of a signing key as described by RFC 7517.
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid,omitempty"`
+	Use string `json:"use,omitempty"`
+	Alg string `json:"alg,omitempty"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+	Y   string `json:"y,omitempty"`
+}
+
+// JWKSet is the document served from /.well-known/jwks.json.
+type JWKSet struct {
+	Keys []JWK `json:"keys"`
+}
+
+// PublicJWK returns the public key as a JWK. Symmetric keys are never
+// published, so ok is false for them.
+func (k *SigningKey) PublicJWK() (jwk JWK, ok bool) {
+	jwk = JWK{Kid: k.ID, Use: "sig", Alg: k.Method.Alg()}
+
+	switch pub := k.verifyKey.(type) {
+	case *rsa.PublicKey:
+		jwk.Kty = "RSA"
+		jwk.N = encodeSegment(pub.N.Bytes())
+		jwk.E = encodeSegment(big.NewInt(int64(pub.E)).Bytes())
+	case *ecdsa.PublicKey:
+		size := (pub.Curve.Params().BitSize + 7) / 8
+		jwk.Kty = "EC"
+		jwk.Crv = pub.Curve.Params().Name
+		jwk.X = encodeSegment(pub.X.FillBytes(make([]byte, size)))
+		jwk.Y = encodeSegment(pub.Y.FillBytes(make([]byte, size)))
+	case ed25519.PublicKey:
+		jwk.Kty = "OKP"
+		jwk.Crv = "Ed25519"
+		jwk.X = encodeSegment(pub)
+	default:
+		return JWK{}, false
+	}
+
+	return jwk, true
+}
+
+// Thumbprint computes the RFC 7638 SHA-256 thumbprint of the key.
+func (j JWK) Thumbprint() (string, error) {
+	// Only the required members, in lexicographic order, take part.
+	var members interface{}
+	switch j.Kty {
+	case "RSA":
+		members = struct {
+			E   string `json:"e"`
+			Kty string `json:"kty"`
+			N   string `json:"n"`
+		}{j.E, j.Kty, j.N}
+	case "EC":
+		members = struct {
+			Crv string `json:"crv"`
+			Kty string `json:"kty"`
+			X   string `json:"x"`
+			Y   string `json:"y"`
+		}{j.Crv, j.Kty, j.X, j.Y}
+	default:
+		members = struct {
+			Crv string `json:"crv"`
+			Kty string `json:"kty"`
+			X   string `json:"x"`
+		}{j.Crv, j.Kty, j.X}
+	}
+
+	data, err := json.Marshal(members)
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256(data)
+	return encodeSegment(sum[:]), nil
+}
+
+// JWKS returns the public keys that can verify tokens issued by t, including
+// keys that no longer sign but are still trusted.
+func (t *TokenGenerator) JWKS() JWKSet {
+	set := JWKSet{Keys: []JWK{}}
+
+	now := time.Now()
+	for _, key := range t.keys {
+		if !key.canVerify(now) {
+			continue
+		}
+		if jwk, ok := key.PublicJWK(); ok {
+			set.Keys = append(set.Keys, jwk)
+		}
+	}
+
+	sort.Slice(set.Keys, func(i, j int) bool { return set.Keys[i].Kid < set.Keys[j].Kid })
+	return set
+}
+
+func encodeSegment(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
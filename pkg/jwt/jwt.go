@@ -2,7 +2,8 @@ package jwt
 
 import (
 	"context"
-	"fmt"
+	"crypto/rand"
+	"encoding/hex"
 	"time"
 
 	"github.com/HasanNugroho/gin-clean/config"
@@ -11,58 +12,330 @@ import (
 	"github.com/golang-jwt/jwt/v5"
 )
 
+// Token types carried by the "typ" claim, so a token is only ever accepted
+// for what it was issued for.
+const (
+	tokenTypeAccess  = "access"
+	tokenTypeRefresh = "refresh"
+)
+
 type (
+	// Subject identifies whom a token is issued to. Version is the user's
+	// token version at issue time; bumping it revokes every older token.
+	// TenantID is the organization the token acts in, if any.
+	Subject struct {
+		UserID    string
+		SessionID string
+		Version   int
+		TenantID  string
+	}
+
 	TokenGenerator struct {
-		cache               *cache.RedisCache
-		secret              []byte
+		cache               cache.Cache
+		key                 *SigningKey
+		keys                map[string]*SigningKey
 		tokenExpired        time.Duration
 		refreshTokenExpired time.Duration
+		sessionMaxLifetime  time.Duration
 	}
 )
 
-func SetJWTHelper(config *config.Config, redis *cache.RedisCache) *TokenGenerator {
+func SetJWTHelper(config *config.Config, cache cache.Cache) (*TokenGenerator, error) {
 	tokenExpiry, _ := time.ParseDuration(config.Secret.TokenExpiry)
 	refreshExpiry, _ := time.ParseDuration(config.Secret.RefreshTokenExpiry)
+	sessionMaxLifetime, _ := time.ParseDuration(config.Secret.SessionMaxLifetime)
+
+	key, keys, err := newSigningKeys(config, max(tokenExpiry, refreshExpiry))
+	if err != nil {
+		return nil, err
+	}
+
 	return &TokenGenerator{
-		cache:               redis,
-		secret:              []byte(config.Secret.Jwt),
+		cache:               cache,
+		key:                 key,
+		keys:                keys,
 		tokenExpired:        tokenExpiry,
 		refreshTokenExpired: refreshExpiry,
+		sessionMaxLifetime:  sessionMaxLifetime,
+	}, nil
+}
+
+// newSigningKeys selects the signing key and the set of verification keys from
+// configuration. With JWT_KEYRING_PATH the key ring decides both; otherwise
+// HS256 signs with SECRET_KEY and every other algorithm loads a single PEM
+// private key from disk.
+func newSigningKeys(config *config.Config, lifetime time.Duration) (*SigningKey, map[string]*SigningKey, error) {
+	if config.Secret.JwtKeyRingPath != "" {
+		ring, err := LoadKeyRing(config.Secret.JwtKeyRingPath)
+		if err != nil {
+			return nil, nil, err
+		}
+		return ring.SigningKeys(time.Now(), lifetime)
+	}
+
+	var (
+		key *SigningKey
+		err error
+	)
+	if config.Secret.JwtAlgorithm == "" || config.Secret.JwtAlgorithm == AlgorithmHS256 {
+		id := config.Secret.JwtKeyID
+		if id == "" {
+			id = "default"
+		}
+		key = NewHMACKey(id, []byte(config.Secret.Jwt))
+	} else {
+		key, err = LoadSigningKey(config.Secret.JwtKeyID, config.Secret.JwtAlgorithm, config.Secret.JwtPrivateKeyPath)
+		if err != nil {
+			return nil, nil, err
+		}
 	}
+
+	return key, map[string]*SigningKey{key.ID: key}, nil
 }
 
-func (t *TokenGenerator) GenerateToken(payload string) (string, error) {
-	claims := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
-		"payload": payload,
+// GenerateToken issues an access token bound to the session via "sid".
+func (t *TokenGenerator) GenerateToken(subject Subject) (string, error) {
+	claims := jwt.MapClaims{
+		"payload": subject.UserID,
+		"typ":     tokenTypeAccess,
+		"sid":     subject.SessionID,
+		"ver":     subject.Version,
 		"exp":     time.Now().Add(t.tokenExpired).Unix(),
 		"iat":     time.Now().Unix(),
-	})
+	}
+	if subject.TenantID != "" {
+		claims["tid"] = subject.TenantID
+	}
+	return t.sign(claims)
+}
+
+// GenerateRefreshToken issues the first refresh token of a session. The session
+// id names the token family: every refresh token carries a unique "jti" and
+// the "sid" of its family, and the family's current jti is tracked in the
+// cache so each token is single-use. The "auth_time" of the family is the
+// time the session started.
+func (t *TokenGenerator) GenerateRefreshToken(subject Subject) (string, error) {
+	jti, err := newTokenID()
+	if err != nil {
+		return "", err
+	}
+
+	now := time.Now()
+	expiresAt := t.RefreshExpiresAt(now, now)
+	if err := t.cache.Set(context.Background(), familyKey(subject.SessionID), jti, expiresAt.Sub(now)); err != nil {
+		return "", errors.ErrInternalServer.WithMessage("failed to store refresh token family").WithError(err)
+	}
 
-	return claims.SignedString(t.secret)
+	return t.signRefreshToken(subject, jti, now, expiresAt)
 }
 
-func (t *TokenGenerator) GenerateRefreshToken(payload string) (string, error) {
-	claims := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
-		"payload": payload,
-		"exp":     time.Now().Add(t.refreshTokenExpired).Unix(),
-		"iat":     time.Now().Unix(),
-		"nbf":     time.Now().Add(t.tokenExpired).Unix(),
+// RotateRefreshToken exchanges a refresh token for a new one in the same family.
+// Presenting a token that was already rotated is treated as token theft: the
+// whole family is revoked and every token issued from it stops working. The
+// claims are returned whenever the token signature is valid, even alongside an
+// error, so callers can act on the affected session.
+func (t *TokenGenerator) RotateRefreshToken(rawToken string) (string, jwt.MapClaims, error) {
+	claims, err := t.parseTyped(rawToken, tokenTypeRefresh)
+	if err != nil {
+		return "", nil, err
+	}
+
+	payload, _ := claims["payload"].(string)
+	familyID, _ := claims["sid"].(string)
+	jti, _ := claims["jti"].(string)
+	if payload == "" || familyID == "" || jti == "" {
+		return "", nil, errors.ErrUnauthorized.WithMessage("invalid refresh token claims")
+	}
+	subject := Subject{UserID: payload, SessionID: familyID, Version: TokenVersion(claims)}
+
+	// The family never outlives the session's maximum lifetime, however
+	// often it is rotated.
+	now := time.Now()
+	authTime := sessionStart(claims)
+	expiresAt := t.RefreshExpiresAt(authTime, now)
+	if !expiresAt.After(now) {
+		if err := t.RevokeTokenFamily(familyID); err != nil {
+			return "", claims, err
+		}
+		return "", claims, errors.ErrUnauthorized.WithMessage("session has expired")
+	}
+
+	newJti, err := newTokenID()
+	if err != nil {
+		return "", nil, err
+	}
+
+	ctx := context.Background()
+	swapped, err := t.cache.CompareAndSwap(ctx, familyKey(familyID), jti, newJti, expiresAt.Sub(now))
+	if err != nil {
+		return "", nil, errors.ErrInternalServer.WithMessage("failed to rotate refresh token").WithError(err)
+	}
+
+	if !swapped {
+		// Either the family was already revoked or this token has been used
+		// before. In both cases nothing from this family may be trusted.
+		if err := t.RevokeTokenFamily(familyID); err != nil {
+			return "", claims, err
+		}
+		return "", claims, errors.ErrUnauthorized.WithMessage("refresh token reuse detected")
+	}
+
+	if err := t.blacklistRefreshToken(rawToken, claims); err != nil {
+		return "", claims, err
+	}
+
+	newToken, err := t.signRefreshToken(subject, newJti, authTime, expiresAt)
+	if err != nil {
+		return "", claims, err
+	}
+
+	return newToken, claims, nil
+}
+
+// RevokeTokenFamily invalidates every refresh token belonging to the family.
+// Access tokens of the same session are rejected as well, see IsSessionActive.
+func (t *TokenGenerator) RevokeTokenFamily(familyID string) error {
+	if err := t.cache.Delete(context.Background(), familyKey(familyID)); err != nil {
+		return errors.ErrInternalServer.WithMessage("failed to revoke refresh token family").WithError(err)
+	}
+	return nil
+}
+
+// IsSessionActive reports whether the session's token family is still alive.
+func (t *TokenGenerator) IsSessionActive(sessionID string) bool {
+	val, err := t.cache.Exist(context.Background(), familyKey(sessionID))
+	return err == nil && val > 0
+}
+
+// RefreshExpiresAt is when a refresh token issued now for a session started
+// at sessionStart expires: after the refresh token lifetime, which is how
+// long a session may stay idle, but never past the session's maximum
+// lifetime.
+func (t *TokenGenerator) RefreshExpiresAt(sessionStart, now time.Time) time.Time {
+	expiresAt := now.Add(t.refreshTokenExpired)
+	if limit := sessionStart.Add(t.sessionMaxLifetime); limit.Before(expiresAt) {
+		return limit
+	}
+	return expiresAt
+}
+
+// sessionStart returns the "auth_time" of a refresh token. Tokens issued
+// before it was introduced count from their own issue time.
+func sessionStart(claims jwt.MapClaims) time.Time {
+	if authTime, ok := claims["auth_time"].(float64); ok {
+		return time.Unix(int64(authTime), 0)
+	}
+	if iat, err := claims.GetIssuedAt(); err == nil && iat != nil {
+		return iat.Time
+	}
+	return time.Now()
+}
+
+// RevokeUserTokens records the user's new token version. Every token carrying
+// an older version is rejected from now on, whichever session it belongs to.
+func (t *TokenGenerator) RevokeUserTokens(userID string, version int) error {
+	ttl := max(t.tokenExpired, t.refreshTokenExpired)
+	if err := t.cache.Set(context.Background(), tokenVersionKey(userID), version, ttl); err != nil {
+		return errors.ErrInternalServer.WithMessage("failed to store token version").WithError(err)
+	}
+	return nil
+}
+
+// TokenVersion returns the "ver" claim; tokens issued before versions were
+// introduced count as version 0.
+func TokenVersion(claims jwt.MapClaims) int {
+	ver, _ := claims["ver"].(float64)
+	return int(ver)
+}
+
+// TenantID returns the organization an access token acts in, or "" for tokens
+// issued outside of any organization.
+func TenantID(claims jwt.MapClaims) string {
+	tid, _ := claims["tid"].(string)
+	return tid
+}
+
+func (t *TokenGenerator) signRefreshToken(subject Subject, jti string, authTime, expiresAt time.Time) (string, error) {
+	return t.sign(jwt.MapClaims{
+		"payload":   subject.UserID,
+		"typ":       tokenTypeRefresh,
+		"sid":       subject.SessionID,
+		"ver":       subject.Version,
+		"jti":       jti,
+		"auth_time": authTime.Unix(),
+		"exp":       expiresAt.Unix(),
+		"iat":       time.Now().Unix(),
+		"nbf":       time.Now().Add(t.tokenExpired).Unix(),
 	})
+}
+
+// sign issues a token signed with the current key and tagged with its kid.
+func (t *TokenGenerator) sign(claims jwt.MapClaims) (string, error) {
+	token := jwt.NewWithClaims(t.key.Method, claims)
+	token.Header["kid"] = t.key.ID
 
-	return claims.SignedString(t.secret)
+	return token.SignedString(t.key.signKey)
 }
 
+// ParseToken verifies an access token. Refresh tokens and purpose tokens such
+// as email verification links never authenticate.
 func (t *TokenGenerator) ParseToken(rawToken string) (jwt.MapClaims, error) {
-	if t.IsTokenRevoked("refreshtoken:blacklist:"+rawToken) || t.IsTokenRevoked("token:blacklist:"+rawToken) {
+	if t.IsTokenRevoked("token:blacklist:" + rawToken) {
+		return nil, errors.ErrUnauthorized.WithMessage("invalid or expired token")
+	}
+
+	return t.parseTyped(rawToken, tokenTypeAccess)
+}
+
+// parseTyped is parse for tokens of the given type only.
+func (t *TokenGenerator) parseTyped(rawToken, tokenType string, options ...jwt.ParserOption) (jwt.MapClaims, error) {
+	claims, err := t.parse(rawToken, options...)
+	if err != nil {
+		return nil, err
+	}
+
+	if typ, _ := claims["typ"].(string); typ != tokenType {
 		return nil, errors.ErrUnauthorized.WithMessage("invalid or expired token")
 	}
+	return claims, nil
+}
 
-	token, err := jwt.Parse(rawToken, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, errors.ErrUnauthorized.WithMessage("unexpected signing method")
+// GeneratePurposeToken issues a short-lived token that is only accepted by
+// ParsePurposeToken for the same purpose, e.g. a link sent by email.
+func (t *TokenGenerator) GeneratePurposeToken(purpose, payload string, expiry time.Duration, extra map[string]interface{}) (string, error) {
+	claims := jwt.MapClaims{
+		"payload": payload,
+		"purpose": purpose,
+		"exp":     time.Now().Add(expiry).Unix(),
+		"iat":     time.Now().Unix(),
+	}
+	for key, value := range extra {
+		if _, reserved := claims[key]; !reserved {
+			claims[key] = value
 		}
-		return t.secret, nil
-	})
+	}
+
+	return t.sign(claims)
+}
+
+// ParsePurposeToken verifies a token issued by GeneratePurposeToken.
+func (t *TokenGenerator) ParsePurposeToken(purpose, rawToken string) (jwt.MapClaims, error) {
+	claims, err := t.parse(rawToken)
+	if err != nil {
+		return nil, err
+	}
+
+	if claims["purpose"] != purpose {
+		return nil, errors.ErrUnauthorized.WithMessage("invalid token purpose")
+	}
+	return claims, nil
+}
+
+// parse verifies the signature and registered claims of rawToken and rejects
+// session tokens older than the user's token version, without consulting the
+// blacklist.
+func (t *TokenGenerator) parse(rawToken string, options ...jwt.ParserOption) (jwt.MapClaims, error) {
+	token, err := jwt.Parse(rawToken, t.verificationKey, options...)
 
 	if err != nil || !token.Valid {
 		return nil, errors.ErrUnauthorized.WithMessage("invalid or expired token").WithError(err)
@@ -72,22 +345,55 @@ func (t *TokenGenerator) ParseToken(rawToken string) (jwt.MapClaims, error) {
 	if !ok && token.Valid {
 		return nil, errors.ErrUnauthorized.WithMessage("invalid token claims")
 	}
+
+	// Purpose tokens are not bound to a session and carry no version.
+	if _, isPurpose := claims["purpose"]; isPurpose {
+		return claims, nil
+	}
+
+	if userID, ok := claims["payload"].(string); ok {
+		var current int
+		if err := t.cache.Get(context.Background(), tokenVersionKey(userID), &current); err == nil && TokenVersion(claims) < current {
+			return nil, errors.ErrUnauthorized.WithMessage("token has been revoked")
+		}
+	}
 	return claims, nil
 }
 
+// verificationKey resolves the key for a token from its kid header. Tokens
+// issued before key ids were introduced carry no kid and fall back to the
+// current signing key; the algorithm must always match the key to prevent
+// algorithm confusion.
+func (t *TokenGenerator) verificationKey(token *jwt.Token) (interface{}, error) {
+	key := t.key
+	if kid, ok := token.Header["kid"].(string); ok {
+		key, ok = t.keys[kid]
+		if !ok || !key.canVerify(time.Now()) {
+			return nil, errors.ErrUnauthorized.WithMessage("unknown signing key")
+		}
+	}
+
+	if token.Method.Alg() != key.Method.Alg() {
+		return nil, errors.ErrUnauthorized.WithMessage("unexpected signing method")
+	}
+	return key.verifyKey, nil
+}
+
+// ParseRefreshToken verifies a refresh token that has not been revoked. Unlike
+// rotation it does not wait for the token's "nbf", so that a session can be
+// logged out right after it was opened.
 func (t *TokenGenerator) ParseRefreshToken(rawToken string) (jwt.MapClaims, error) {
-	if t.IsTokenRevoked("refreshtoken:blacklist:"+rawToken) || t.IsTokenRevoked("token:blacklist:"+rawToken) {
+	if t.IsTokenRevoked("refreshtoken:blacklist:" + rawToken) {
 		return nil, errors.ErrUnauthorized.WithMessage("invalid or expired token")
 	}
 
-	token, _, err := jwt.NewParser().ParseUnverified(rawToken, jwt.MapClaims{})
+	claims, err := t.parseTyped(rawToken, tokenTypeRefresh, jwt.WithoutClaimsValidation())
 	if err != nil {
-		return nil, errors.ErrUnauthorized.WithMessage("failed to parse refresh token")
+		return nil, err
 	}
 
-	claims, ok := token.Claims.(jwt.MapClaims)
-	if !ok && token.Valid {
-		return nil, errors.ErrUnauthorized.WithMessage("invalid token claims")
+	if exp, err := claims.GetExpirationTime(); err != nil || exp == nil || !time.Now().Before(exp.Time) {
+		return nil, errors.ErrUnauthorized.WithMessage("invalid or expired token")
 	}
 	return claims, nil
 }
@@ -107,28 +413,42 @@ func (t *TokenGenerator) RevokeToken(token string) error {
 	if err = t.cache.Set(context.Background(), "token:blacklist:"+token, "revoked", ttl); err != nil {
 		return errors.ErrUnauthorized.WithMessage("failed to store token in blacklist")
 	}
-	fmt.Println("Token blacklisted:", token, "TTL:", ttl)
-
 	return nil
 }
 
-func (t *TokenGenerator) RevokeRequestToken(token string) error {
+// RevokeRequestToken blacklists a refresh token of userID and revokes its
+// family so that no token rotated from it can be used either. Tokens of other
+// users are rejected.
+func (t *TokenGenerator) RevokeRequestToken(token string, userID string) error {
 	claims, err := t.ParseRefreshToken(token)
 	if err != nil {
 		return err
 	}
 
+	if payload, _ := claims["payload"].(string); payload == "" || payload != userID {
+		return errors.ErrUnauthorized.WithMessage("refresh token does not belong to the caller")
+	}
+
+	if err := t.blacklistRefreshToken(token, claims); err != nil {
+		return err
+	}
+
+	if familyID, ok := claims["sid"].(string); ok && familyID != "" {
+		return t.RevokeTokenFamily(familyID)
+	}
+	return nil
+}
+
+func (t *TokenGenerator) blacklistRefreshToken(token string, claims jwt.MapClaims) error {
 	exp, ok := claims["exp"].(float64)
 	if !ok {
 		return errors.ErrUnauthorized.WithMessage("invalid expiration claim")
 	}
 
 	ttl := time.Until(time.Unix(int64(exp), 0))
-	if err = t.cache.Set(context.Background(), "refreshtoken:blacklist:"+token, "revoked", ttl); err != nil {
+	if err := t.cache.Set(context.Background(), "refreshtoken:blacklist:"+token, "revoked", ttl); err != nil {
 		return errors.ErrUnauthorized.WithMessage("failed to store refresh token in blacklist")
 	}
-	fmt.Println("Token blacklisted:", token, "TTL:", ttl)
-
 	return nil
 }
 
@@ -136,3 +456,19 @@ func (t *TokenGenerator) IsTokenRevoked(tokenKey string) bool {
 	val, err := t.cache.Exist(context.Background(), tokenKey)
 	return err == nil && val > 0
 }
+
+func tokenVersionKey(userID string) string {
+	return "user:token-version:" + userID
+}
+
+func familyKey(familyID string) string {
+	return "refreshtoken:family:" + familyID
+}
+
+func newTokenID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", errors.ErrInternalServer.WithMessage("failed to generate token id").WithError(err)
+	}
+	return hex.EncodeToString(b), nil
+}
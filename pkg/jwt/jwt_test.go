@@ -0,0 +1,173 @@
+package jwt
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/HasanNugroho/gin-clean/internal/infrastructure/presistence/cache"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func newTestGenerator(secret string, tokenExpiry time.Duration) *TokenGenerator {
+	return newKeyedGenerator(NewHMACKey("test", []byte(secret)), tokenExpiry)
+}
+
+// newKeyedGenerator returns a generator signing with key, backed by a memory
+// cache.
+func newKeyedGenerator(key *SigningKey, tokenExpiry time.Duration) *TokenGenerator {
+	return &TokenGenerator{
+		key:                 key,
+		keys:                map[string]*SigningKey{key.ID: key},
+		tokenExpired:        tokenExpiry,
+		refreshTokenExpired: time.Hour,
+		sessionMaxLifetime:  24 * time.Hour,
+		cache:               cache.NewMemoryCache(),
+	}
+}
+
+func TestTokenTypes(t *testing.T) {
+	generator := newTestGenerator("secret", 0)
+
+	access, err := generator.GenerateToken(Subject{UserID: "user", SessionID: "session"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	refresh, err := generator.signRefreshToken(Subject{UserID: "user", SessionID: "family"}, "jti", time.Now(), time.Now().Add(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := generator.parseTyped(refresh, tokenTypeRefresh); err != nil {
+		t.Errorf("refresh token as refresh token: %v", err)
+	}
+	if _, err := generator.parseTyped(refresh, tokenTypeAccess); err == nil {
+		t.Error("a refresh token was accepted as access token")
+	}
+	if _, err := generator.parseTyped(access, tokenTypeRefresh, jwt.WithoutClaimsValidation()); err == nil {
+		t.Error("an access token was accepted as refresh token")
+	}
+}
+
+func TestRefreshTokenClaims(t *testing.T) {
+	generator := newTestGenerator("secret", time.Minute)
+	refresh, err := generator.signRefreshToken(Subject{UserID: "user", SessionID: "family"}, "jti", time.Now(), time.Now().Add(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Refresh tokens only rotate once the access token issued with them
+	// has expired.
+	if _, err := generator.parseTyped(refresh, tokenTypeRefresh); err == nil {
+		t.Error("a refresh token was accepted before its nbf")
+	}
+
+	claims, err := generator.parseTyped(refresh, tokenTypeRefresh, jwt.WithoutClaimsValidation())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for key, want := range map[string]string{"payload": "user", "sid": "family", "jti": "jti"} {
+		if claims[key] != want {
+			t.Errorf("%s = %v, want %s", key, claims[key], want)
+		}
+	}
+}
+
+func TestParseRejectsOtherSecret(t *testing.T) {
+	token, err := newTestGenerator("secret", time.Minute).GenerateToken(Subject{UserID: "user", SessionID: "session"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := newTestGenerator("other", time.Minute).parseTyped(token, tokenTypeAccess); err == nil {
+		t.Error("a token signed with another secret was accepted")
+	}
+}
+
+func TestParseRejectsUnsignedToken(t *testing.T) {
+	token, err := jwt.NewWithClaims(jwt.SigningMethodNone, jwt.MapClaims{
+		"payload": "user",
+		"typ":     tokenTypeRefresh,
+		"exp":     time.Now().Add(time.Hour).Unix(),
+	}).SignedString(jwt.UnsafeAllowNoneSignatureType)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := newTestGenerator("secret", 0).parseTyped(token, tokenTypeRefresh, jwt.WithoutClaimsValidation()); err == nil {
+		t.Error("an unsigned token was accepted")
+	}
+}
+
+func TestPurposeTokens(t *testing.T) {
+	generator := newTestGenerator("secret", time.Minute)
+	token, err := generator.GeneratePurposeToken("verify_email", "user", time.Hour, map[string]interface{}{"email": "a@example.com", "purpose": "other"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	claims, err := generator.ParsePurposeToken("verify_email", token)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if claims["payload"] != "user" || claims["email"] != "a@example.com" {
+		t.Errorf("claims = %v", claims)
+	}
+
+	if _, err := generator.ParsePurposeToken("reset_password", token); err == nil {
+		t.Error("a token was accepted for another purpose")
+	}
+	if _, err := generator.parseTyped(token, tokenTypeAccess); err == nil {
+		t.Error("a purpose token was accepted as access token")
+	}
+}
+
+func TestRefreshExpiresAt(t *testing.T) {
+	generator := newTestGenerator("secret", 0)
+	now := time.Now()
+
+	if got := generator.RefreshExpiresAt(now, now); !got.Equal(now.Add(time.Hour)) {
+		t.Errorf("new session: expires at %v, want %v", got, now.Add(time.Hour))
+	}
+
+	start := now.Add(-23*time.Hour - 30*time.Minute)
+	if got := generator.RefreshExpiresAt(start, now); !got.Equal(start.Add(24 * time.Hour)) {
+		t.Errorf("session close to its maximum lifetime: expires at %v, want %v", got, start.Add(24*time.Hour))
+	}
+}
+
+func TestRotateRefreshTokenCapsSessionLifetime(t *testing.T) {
+	generator := newTestGenerator("secret", 0)
+	subject := Subject{UserID: "user", SessionID: "family"}
+
+	refresh, err := generator.GenerateRefreshToken(subject)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rotated, _, err := generator.RotateRefreshToken(refresh)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	claims, err := generator.parseTyped(rotated, tokenTypeRefresh)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := claims["auth_time"]; !ok {
+		t.Fatal("a rotated refresh token lost the auth_time of its session")
+	}
+
+	// A session that started longer ago than the maximum lifetime cannot be
+	// refreshed anymore, and its family is gone.
+	old, err := generator.signRefreshToken(subject, "old", time.Now().Add(-25*time.Hour), time.Now().Add(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := generator.cache.Set(context.Background(), familyKey(subject.SessionID), "old", time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := generator.RotateRefreshToken(old); err == nil {
+		t.Error("a session past its maximum lifetime was refreshed")
+	}
+	if generator.IsSessionActive(subject.SessionID) {
+		t.Error("the family of an expired session is still active")
+	}
+}
@@ -0,0 +1,313 @@
+package jwt
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// KeyStatus describes where a key is in its rotation lifecycle.
+//
+//	generate -> verify -> (promote) active -> (promote another) verify -> (retire) retired
+//
+// Only the active key signs. Verify keys are accepted so that tokens signed
+// before a promotion stay valid, and so that a freshly generated key can be
+// distributed to every instance before it starts signing. Retired keys keep
+// verifying until every token they could have signed has expired.
+type KeyStatus string
+
+const (
+	KeyStatusActive  KeyStatus = "active"
+	KeyStatusVerify  KeyStatus = "verify"
+	KeyStatusRetired KeyStatus = "retired"
+)
+
+// keyIDPattern restricts key ids to characters that are safe in the name of
+// the key file, so that an id cannot point the file outside of the ring.
+var keyIDPattern = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// KeyEntry is a single key recorded in the key ring manifest.
+type KeyEntry struct {
+	ID        string     `json:"kid"`
+	Algorithm string     `json:"alg"`
+	Status    KeyStatus  `json:"status"`
+	File      string     `json:"file"`
+	CreatedAt time.Time  `json:"created_at"`
+	RetiredAt *time.Time `json:"retired_at,omitempty"`
+}
+
+// KeyRing is the manifest of signing keys stored as JSON on disk. Key material
+// lives in separate files next to the manifest.
+type KeyRing struct {
+	Keys []*KeyEntry `json:"keys"`
+
+	path string
+}
+
+// LoadKeyRing reads the manifest at path. A missing manifest yields an empty
+// ring so that the first "generate" can create it.
+func LoadKeyRing(path string) (*KeyRing, error) {
+	ring := &KeyRing{path: path}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return ring, nil
+		}
+		return nil, fmt.Errorf("failed to read key ring %s: %w", path, err)
+	}
+
+	if err := json.Unmarshal(data, ring); err != nil {
+		return nil, fmt.Errorf("failed to parse key ring %s: %w", path, err)
+	}
+	return ring, nil
+}
+
+// Save writes the manifest back to disk.
+func (r *KeyRing) Save() error {
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(r.path), 0o700); err != nil {
+		return err
+	}
+	return os.WriteFile(r.path, data, 0o600)
+}
+
+// Find returns the entry with the given key id.
+func (r *KeyRing) Find(id string) (*KeyEntry, error) {
+	for _, entry := range r.Keys {
+		if entry.ID == id {
+			return entry, nil
+		}
+	}
+	return nil, fmt.Errorf("key %q not found", id)
+}
+
+// Generate creates new key material for algorithm and adds it to the ring in
+// the verify state. For HS256 an existing secret may be imported instead of
+// generating a random one, which allows moving a SECRET_KEY into the ring
+// without logging users out.
+func (r *KeyRing) Generate(algorithm, id string, secret []byte) (*KeyEntry, error) {
+	material, err := generateKeyMaterial(algorithm, secret)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := parseKeyMaterial(id, algorithm, material)
+	if err != nil {
+		return nil, err
+	}
+	if key.ID == "" {
+		key.ID = fmt.Sprintf("hs-%d", time.Now().Unix())
+	}
+	if !keyIDPattern.MatchString(key.ID) {
+		return nil, fmt.Errorf("invalid key id %q: only letters, digits, '-' and '_' are allowed", key.ID)
+	}
+
+	if _, err := r.Find(key.ID); err == nil {
+		return nil, fmt.Errorf("key %q already exists", key.ID)
+	}
+
+	entry := &KeyEntry{
+		ID:        key.ID,
+		Algorithm: algorithm,
+		Status:    KeyStatusVerify,
+		File:      "key_" + key.ID + keyFileExtension(algorithm),
+		CreatedAt: time.Now().UTC(),
+	}
+
+	if err := os.MkdirAll(filepath.Dir(r.path), 0o700); err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(r.keyPath(entry), material, 0o600); err != nil {
+		return nil, fmt.Errorf("failed to write key file: %w", err)
+	}
+
+	r.Keys = append(r.Keys, entry)
+	return entry, nil
+}
+
+// Promote makes the key the signing key. The previously active key is demoted
+// to verify so that the tokens it signed remain valid.
+func (r *KeyRing) Promote(id string) error {
+	entry, err := r.Find(id)
+	if err != nil {
+		return err
+	}
+	if entry.Status == KeyStatusRetired {
+		return fmt.Errorf("key %q is retired and cannot be promoted", id)
+	}
+
+	for _, other := range r.Keys {
+		if other.Status == KeyStatusActive {
+			other.Status = KeyStatusVerify
+		}
+	}
+	entry.Status = KeyStatusActive
+	return nil
+}
+
+// Retire stops trusting the key once the tokens it signed have expired.
+func (r *KeyRing) Retire(id string, now time.Time) error {
+	entry, err := r.Find(id)
+	if err != nil {
+		return err
+	}
+	if entry.Status == KeyStatusActive {
+		return fmt.Errorf("key %q is active; promote another key first", id)
+	}
+	if entry.Status == KeyStatusRetired {
+		return nil
+	}
+
+	retiredAt := now.UTC()
+	entry.Status = KeyStatusRetired
+	entry.RetiredAt = &retiredAt
+	return nil
+}
+
+// Prune removes retired keys whose verification window has elapsed, deleting
+// their key files. It returns the ids of the removed keys.
+func (r *KeyRing) Prune(now time.Time, lifetime time.Duration) ([]string, error) {
+	var (
+		kept   []*KeyEntry
+		pruned []string
+	)
+
+	for _, entry := range r.Keys {
+		if !entry.expired(now, lifetime) {
+			kept = append(kept, entry)
+			continue
+		}
+
+		if err := os.Remove(r.keyPath(entry)); err != nil && !os.IsNotExist(err) {
+			return pruned, err
+		}
+		pruned = append(pruned, entry.ID)
+	}
+
+	r.Keys = kept
+	return pruned, nil
+}
+
+// SigningKeys loads the key material of the ring. It returns the active key
+// and every key that may still verify tokens, indexed by kid. lifetime is the
+// longest lifetime of any issued token.
+func (r *KeyRing) SigningKeys(now time.Time, lifetime time.Duration) (*SigningKey, map[string]*SigningKey, error) {
+	var (
+		active *SigningKey
+		keys   = make(map[string]*SigningKey)
+	)
+
+	for _, entry := range r.Keys {
+		if entry.expired(now, lifetime) {
+			continue
+		}
+
+		material, err := os.ReadFile(r.keyPath(entry))
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to read key %q: %w", entry.ID, err)
+		}
+
+		key, err := parseKeyMaterial(entry.ID, entry.Algorithm, material)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to load key %q: %w", entry.ID, err)
+		}
+
+		if entry.RetiredAt != nil {
+			key.expiresAt = entry.RetiredAt.Add(lifetime)
+		}
+
+		keys[key.ID] = key
+		if entry.Status == KeyStatusActive {
+			active = key
+		}
+	}
+
+	if active == nil {
+		return nil, nil, fmt.Errorf("key ring %s has no active key", r.path)
+	}
+	return active, keys, nil
+}
+
+func (e *KeyEntry) expired(now time.Time, lifetime time.Duration) bool {
+	return e.Status == KeyStatusRetired && e.RetiredAt != nil && !now.Before(e.RetiredAt.Add(lifetime))
+}
+
+func (r *KeyRing) keyPath(entry *KeyEntry) string {
+	if filepath.IsAbs(entry.File) {
+		return entry.File
+	}
+	return filepath.Join(filepath.Dir(r.path), entry.File)
+}
+
+func keyFileExtension(algorithm string) string {
+	if algorithm == AlgorithmHS256 {
+		return ".key"
+	}
+	return ".pem"
+}
+
+// parseKeyMaterial turns the content of a key file into a SigningKey. HMAC
+// secrets are stored base64 encoded, asymmetric keys as PEM.
+func parseKeyMaterial(id, algorithm string, material []byte) (*SigningKey, error) {
+	if algorithm != AlgorithmHS256 {
+		return ParseSigningKey(id, algorithm, material)
+	}
+
+	secret, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(material)))
+	if err != nil {
+		return nil, fmt.Errorf("invalid HS256 key material: %w", err)
+	}
+	return NewHMACKey(id, secret), nil
+}
+
+func generateKeyMaterial(algorithm string, secret []byte) ([]byte, error) {
+	var (
+		privateKey interface{}
+		err        error
+	)
+
+	switch algorithm {
+	case AlgorithmHS256:
+		if len(secret) == 0 {
+			secret = make([]byte, 32)
+			if _, err := rand.Read(secret); err != nil {
+				return nil, err
+			}
+		}
+		return []byte(base64.StdEncoding.EncodeToString(secret) + "\n"), nil
+	case AlgorithmRS256:
+		privateKey, err = rsa.GenerateKey(rand.Reader, 2048)
+	case AlgorithmES256:
+		privateKey, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	case AlgorithmEdDSA:
+		_, privateKey, err = ed25519.GenerateKey(rand.Reader)
+	default:
+		return nil, fmt.Errorf("unsupported algorithm %q", algorithm)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	der, err := x509.MarshalPKCS8PrivateKey(privateKey)
+	if err != nil {
+		return nil, err
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), nil
+}
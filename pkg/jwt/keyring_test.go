@@ -0,0 +1,235 @@
+package jwt
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/HasanNugroho/gin-clean/internal/infrastructure/presistence/cache"
+)
+
+func newTestKeyRing(t *testing.T) *KeyRing {
+	t.Helper()
+	ring, err := LoadKeyRing(filepath.Join(t.TempDir(), "keys", "keyring.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ring.Keys) != 0 {
+		t.Fatalf("a missing manifest loaded %d keys", len(ring.Keys))
+	}
+	return ring
+}
+
+func TestKeyRingGenerate(t *testing.T) {
+	for _, algorithm := range []string{AlgorithmHS256, AlgorithmRS256, AlgorithmES256, AlgorithmEdDSA} {
+		t.Run(algorithm, func(t *testing.T) {
+			ring := newTestKeyRing(t)
+			entry, err := ring.Generate(algorithm, "k1", nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if entry.Status != KeyStatusVerify || entry.Algorithm != algorithm {
+				t.Errorf("entry = %+v, want a %s key in the verify state", entry, algorithm)
+			}
+			if _, err := os.Stat(ring.keyPath(entry)); err != nil {
+				t.Errorf("key file: %v", err)
+			}
+
+			if err := ring.Promote("k1"); err != nil {
+				t.Fatal(err)
+			}
+			active, keys, err := ring.SigningKeys(time.Now(), time.Hour)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if active.ID != "k1" || active.Method.Alg() != algorithm || len(keys) != 1 {
+				t.Errorf("SigningKeys = %s %s with %d keys", active.ID, active.Method.Alg(), len(keys))
+			}
+		})
+	}
+}
+
+func TestKeyRingGenerateRejects(t *testing.T) {
+	ring := newTestKeyRing(t)
+	if _, err := ring.Generate("none", "k1", nil); err == nil {
+		t.Error("Generate accepted an unsupported algorithm")
+	}
+
+	if _, err := ring.Generate(AlgorithmHS256, "k1", nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ring.Generate(AlgorithmHS256, "k1", nil); err == nil {
+		t.Error("Generate accepted a duplicate key id")
+	}
+
+	for _, id := range []string{"../k2", "k2/k3", "k 2", ".."} {
+		if _, err := ring.Generate(AlgorithmHS256, id, nil); err == nil {
+			t.Errorf("Generate accepted the key id %q", id)
+		}
+	}
+}
+
+func TestKeyRingImportsSecret(t *testing.T) {
+	ring := newTestKeyRing(t)
+	if _, err := ring.Generate(AlgorithmHS256, "imported", []byte("secret")); err != nil {
+		t.Fatal(err)
+	}
+	if err := ring.Promote("imported"); err != nil {
+		t.Fatal(err)
+	}
+
+	active, _, err := ring.SigningKeys(time.Now(), time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(active.signKey.([]byte)) != "secret" {
+		t.Errorf("imported secret = %q", active.signKey)
+	}
+}
+
+func TestKeyRingLifecycle(t *testing.T) {
+	ring := newTestKeyRing(t)
+	for _, id := range []string{"old", "new"} {
+		if _, err := ring.Generate(AlgorithmHS256, id, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if _, _, err := ring.SigningKeys(time.Now(), time.Hour); err == nil {
+		t.Error("SigningKeys accepted a ring without an active key")
+	}
+
+	if err := ring.Promote("old"); err != nil {
+		t.Fatal(err)
+	}
+	if err := ring.Retire("old", time.Now()); err == nil {
+		t.Error("Retire accepted the active key")
+	}
+
+	if err := ring.Promote("new"); err != nil {
+		t.Fatal(err)
+	}
+	if old, _ := ring.Find("old"); old.Status != KeyStatusVerify {
+		t.Errorf("previous active key is %s, want verify", old.Status)
+	}
+
+	retiredAt := time.Now()
+	if err := ring.Retire("old", retiredAt); err != nil {
+		t.Fatal(err)
+	}
+	if err := ring.Promote("old"); err == nil {
+		t.Error("Promote accepted a retired key")
+	}
+
+	// The retired key verifies until the longest token lifetime has passed.
+	_, keys, err := ring.SigningKeys(retiredAt, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if key := keys["old"]; key == nil || !key.canVerify(retiredAt.Add(59*time.Minute)) || key.canVerify(retiredAt.Add(time.Hour)) {
+		t.Errorf("retired key verifies outside of its window")
+	}
+	if _, keys, _ := ring.SigningKeys(retiredAt.Add(time.Hour), time.Hour); keys["old"] != nil {
+		t.Error("SigningKeys loaded an expired key")
+	}
+
+	old, _ := ring.Find("old")
+	pruned, err := ring.Prune(retiredAt.Add(time.Hour), time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pruned) != 1 || pruned[0] != "old" || len(ring.Keys) != 1 {
+		t.Errorf("Prune = %v, leaving %d keys", pruned, len(ring.Keys))
+	}
+	if _, err := os.Stat(ring.keyPath(old)); !os.IsNotExist(err) {
+		t.Errorf("key file of a pruned key: %v", err)
+	}
+}
+
+func TestKeyRingSaveAndLoad(t *testing.T) {
+	ring := newTestKeyRing(t)
+	if _, err := ring.Generate(AlgorithmES256, "k1", nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := ring.Promote("k1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := ring.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := LoadKeyRing(ring.path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	entry, err := loaded.Find("k1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if entry.Status != KeyStatusActive || entry.Algorithm != AlgorithmES256 {
+		t.Errorf("loaded entry = %+v", entry)
+	}
+}
+
+// ringGenerator returns a generator with the keys of the saved ring as of now.
+func ringGenerator(t *testing.T, ring *KeyRing, now time.Time) *TokenGenerator {
+	t.Helper()
+	loaded, err := LoadKeyRing(ring.path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	active, keys, err := loaded.SigningKeys(now, 2*time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &TokenGenerator{key: active, keys: keys, tokenExpired: time.Hour, cache: cache.NewMemoryCache()}
+}
+
+// TestKeyRingRotation checks that tokens signed before a promotion stay valid
+// and that tokens of an expired key are rejected.
+func TestKeyRingRotation(t *testing.T) {
+	ring := newTestKeyRing(t)
+	if _, err := ring.Generate(AlgorithmHS256, "old", nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := ring.Promote("old"); err != nil {
+		t.Fatal(err)
+	}
+	if err := ring.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	token, err := ringGenerator(t, ring, time.Now()).GenerateToken(Subject{UserID: "user", SessionID: "session"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := ring.Generate(AlgorithmEdDSA, "new", nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := ring.Promote("new"); err != nil {
+		t.Fatal(err)
+	}
+	if err := ring.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	after := ringGenerator(t, ring, time.Now())
+	if after.key.ID != "new" {
+		t.Fatalf("signing key = %s, want new", after.key.ID)
+	}
+	if _, err := after.parseTyped(token, tokenTypeAccess); err != nil {
+		t.Errorf("token of the demoted key: %v", err)
+	}
+
+	if err := ring.Retire("old", time.Now().Add(-2*time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	if err := ring.Save(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ringGenerator(t, ring, time.Now()).parseTyped(token, tokenTypeAccess); err == nil {
+		t.Error("token of an expired key was accepted")
+	}
+}
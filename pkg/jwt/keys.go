@@ -0,0 +1,126 @@
+package jwt
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Supported signing algorithms.
+const (
+	AlgorithmHS256 = "HS256"
+	AlgorithmRS256 = "RS256"
+	AlgorithmES256 = "ES256"
+	AlgorithmEdDSA = "EdDSA"
+)
+
+// SigningKey pairs a signing method with the key material used to sign and
+// verify tokens. ID is published as the "kid" header of issued tokens.
+type SigningKey struct {
+	ID        string
+	Method    jwt.SigningMethod
+	signKey   interface{}
+	verifyKey interface{}
+	expiresAt time.Time
+}
+
+// NewHMACKey builds an HS256 key from a shared secret.
+func NewHMACKey(id string, secret []byte) *SigningKey {
+	return &SigningKey{
+		ID:        id,
+		Method:    jwt.SigningMethodHS256,
+		signKey:   secret,
+		verifyKey: secret,
+	}
+}
+
+// LoadSigningKey reads a PEM encoded private key from path and prepares it for
+// the given asymmetric algorithm. When id is empty the RFC 7638 thumbprint of
+// the public key is used as key id.
+func LoadSigningKey(id, algorithm, path string) (*SigningKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read private key %s: %w", path, err)
+	}
+
+	return ParseSigningKey(id, algorithm, data)
+}
+
+// ParseSigningKey parses a PEM encoded private key for the given algorithm.
+func ParseSigningKey(id, algorithm string, data []byte) (*SigningKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("no PEM block found in private key")
+	}
+
+	privateKey, err := parsePrivateKey(block)
+	if err != nil {
+		return nil, err
+	}
+
+	key := &SigningKey{ID: id, signKey: privateKey}
+	switch algorithm {
+	case AlgorithmRS256:
+		rsaKey, ok := privateKey.(*rsa.PrivateKey)
+		if !ok {
+			return nil, fmt.Errorf("%s requires an RSA private key", algorithm)
+		}
+		key.Method = jwt.SigningMethodRS256
+		key.verifyKey = &rsaKey.PublicKey
+	case AlgorithmES256:
+		ecKey, ok := privateKey.(*ecdsa.PrivateKey)
+		if !ok || ecKey.Curve != elliptic.P256() {
+			return nil, fmt.Errorf("%s requires an ECDSA P-256 private key", algorithm)
+		}
+		key.Method = jwt.SigningMethodES256
+		key.verifyKey = &ecKey.PublicKey
+	case AlgorithmEdDSA:
+		edKey, ok := privateKey.(ed25519.PrivateKey)
+		if !ok {
+			return nil, fmt.Errorf("%s requires an Ed25519 private key", algorithm)
+		}
+		key.Method = jwt.SigningMethodEdDSA
+		key.verifyKey = edKey.Public()
+	default:
+		return nil, fmt.Errorf("unsupported asymmetric algorithm %q", algorithm)
+	}
+
+	if key.ID == "" {
+		jwk, _ := key.PublicJWK()
+		thumbprint, err := jwk.Thumbprint()
+		if err != nil {
+			return nil, err
+		}
+		key.ID = thumbprint
+	}
+
+	return key, nil
+}
+
+func parsePrivateKey(block *pem.Block) (crypto.PrivateKey, error) {
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		return x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "EC PRIVATE KEY":
+		return x509.ParseECPrivateKey(block.Bytes)
+	case "PRIVATE KEY":
+		return x509.ParsePKCS8PrivateKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("unsupported PEM block type %q", block.Type)
+	}
+}
+
+// canVerify reports whether the key may still be used to verify tokens. Keys
+// of retired key ring entries stop verifying after their expiry.
+func (k *SigningKey) canVerify(now time.Time) bool {
+	return k.expiresAt.IsZero() || now.Before(k.expiresAt)
+}
@@ -0,0 +1,229 @@
+package jwt
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func encodePEM(t *testing.T, blockType string, der []byte) []byte {
+	t.Helper()
+	return pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der})
+}
+
+func pkcs8(t *testing.T, key interface{}) []byte {
+	t.Helper()
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return encodePEM(t, "PRIVATE KEY", der)
+}
+
+func TestParseSigningKey(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecDER, err := x509.MarshalECPrivateKey(ecKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name      string
+		algorithm string
+		pem       []byte
+		kty       string
+	}{
+		{"RSA PKCS#1", AlgorithmRS256, encodePEM(t, "RSA PRIVATE KEY", x509.MarshalPKCS1PrivateKey(rsaKey)), "RSA"},
+		{"RSA PKCS#8", AlgorithmRS256, pkcs8(t, rsaKey), "RSA"},
+		{"EC SEC 1", AlgorithmES256, encodePEM(t, "EC PRIVATE KEY", ecDER), "EC"},
+		{"EC PKCS#8", AlgorithmES256, pkcs8(t, ecKey), "EC"},
+		{"Ed25519", AlgorithmEdDSA, pkcs8(t, edKey), "OKP"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			key, err := ParseSigningKey("", tt.algorithm, tt.pem)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if key.Method.Alg() != tt.algorithm {
+				t.Errorf("method = %s, want %s", key.Method.Alg(), tt.algorithm)
+			}
+
+			jwk, ok := key.PublicJWK()
+			if !ok || jwk.Kty != tt.kty || jwk.Alg != tt.algorithm {
+				t.Fatalf("PublicJWK = %+v, %v", jwk, ok)
+			}
+			thumbprint, err := jwk.Thumbprint()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if key.ID != thumbprint || jwk.Kid != thumbprint {
+				t.Errorf("kid = %q, jwk kid = %q, want the thumbprint %q", key.ID, jwk.Kid, thumbprint)
+			}
+
+			named, err := ParseSigningKey("named", tt.algorithm, tt.pem)
+			if err != nil || named.ID != "named" {
+				t.Errorf("ParseSigningKey with a kid = %v, %v", named, err)
+			}
+		})
+	}
+}
+
+func TestParseSigningKeyRejects(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p384, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name      string
+		algorithm string
+		pem       []byte
+	}{
+		{"not PEM", AlgorithmRS256, []byte("secret")},
+		{"unknown block", AlgorithmRS256, encodePEM(t, "CERTIFICATE", []byte{1})},
+		{"RSA key for ES256", AlgorithmES256, pkcs8(t, rsaKey)},
+		{"RSA key for EdDSA", AlgorithmEdDSA, pkcs8(t, rsaKey)},
+		{"P-384 key for ES256", AlgorithmES256, pkcs8(t, p384)},
+		{"EC key for RS256", AlgorithmRS256, pkcs8(t, p384)},
+		{"HS256 is not asymmetric", AlgorithmHS256, pkcs8(t, rsaKey)},
+	}
+	for _, tt := range tests {
+		if _, err := ParseSigningKey("", tt.algorithm, tt.pem); err == nil {
+			t.Errorf("%s: ParseSigningKey succeeded", tt.name)
+		}
+	}
+}
+
+func TestLoadSigningKey(t *testing.T) {
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "key.pem")
+	if err := os.WriteFile(path, pkcs8(t, edKey), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := LoadSigningKey("k1", AlgorithmEdDSA, path); err != nil {
+		t.Error(err)
+	}
+	if _, err := LoadSigningKey("k1", AlgorithmEdDSA, path+".missing"); err == nil {
+		t.Error("LoadSigningKey accepted a missing file")
+	}
+}
+
+// TestThumbprint checks the example of RFC 7638, section 3.1.
+func TestThumbprint(t *testing.T) {
+	jwk := JWK{
+		Kty: "RSA",
+		Kid: "2011-04-29",
+		Alg: "RS256",
+		E:   "AQAB",
+		N:   "0vx7agoebGcQSuuPiLJXZptN9nndrQmbXEps2aiAFbWhM78LhWx4cbbfAAtVT86zwu1RK7aPFFxuhDR1L6tSoc_BJECPebWKRXjBZCiFV4n3oknjhMstn64tZ_2W-5JsGY4Hc5n9yBXArwl93lqt7_RN5w6Cf0h4QyQ5v-65YGjQR0_FDW2QvzqY368QQMicAtaSqzs8KJZgnYb9c7d0zgdAZHzu6qMQvRL5hajrn1n91CbOpbISD08qNLyrdkt-bFTWhAI4vMQFh6WeZu0fM4lFd2NcRwr3XPksINHaQ-G_xBniIqbw0Ls1jF44-csFCur-kEgU8awapJzKnqDKgw",
+	}
+
+	thumbprint, err := jwk.Thumbprint()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "NzbLsXh8uDCcd-6MNwXF4W_7noWXFZAfHkxZsRGC9Xs"; thumbprint != want {
+		t.Errorf("Thumbprint = %s, want %s", thumbprint, want)
+	}
+}
+
+func TestJWKSPublishesOnlyAsymmetricKeys(t *testing.T) {
+	if keys := newTestGenerator("secret", 0).JWKS().Keys; len(keys) != 0 {
+		t.Errorf("JWKS published %d keys of an HS256 secret", len(keys))
+	}
+
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, err := ParseSigningKey("k1", AlgorithmEdDSA, pkcs8(t, edKey))
+	if err != nil {
+		t.Fatal(err)
+	}
+	generator := newKeyedGenerator(key, time.Hour)
+	if keys := generator.JWKS().Keys; len(keys) != 1 || keys[0].Kid != "k1" {
+		t.Errorf("JWKS = %+v", keys)
+	}
+}
+
+func TestAsymmetricTokens(t *testing.T) {
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, err := ParseSigningKey("k1", AlgorithmEdDSA, pkcs8(t, edKey))
+	if err != nil {
+		t.Fatal(err)
+	}
+	generator := newKeyedGenerator(key, time.Hour)
+
+	token, err := generator.GenerateToken(Subject{UserID: "user", SessionID: "session"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	claims, err := generator.parseTyped(token, tokenTypeAccess)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if claims["payload"] != "user" {
+		t.Errorf("payload = %v", claims["payload"])
+	}
+
+	// An HS256 token keyed with the public key must not pass as EdDSA.
+	forged, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"payload": "admin",
+		"typ":     tokenTypeAccess,
+		"exp":     time.Now().Add(time.Hour).Unix(),
+	}).SignedString([]byte(edKey.Public().(ed25519.PublicKey)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := generator.parseTyped(forged, tokenTypeAccess); err == nil {
+		t.Error("an HS256 token keyed with the public key was accepted")
+	}
+
+	// Tokens naming another key are rejected.
+	other := jwt.NewWithClaims(jwt.SigningMethodEdDSA, jwt.MapClaims{
+		"payload": "user",
+		"typ":     tokenTypeAccess,
+		"exp":     time.Now().Add(time.Hour).Unix(),
+	})
+	other.Header["kid"] = "k2"
+	signed, err := other.SignedString(edKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := generator.parseTyped(signed, tokenTypeAccess); err == nil {
+		t.Error("a token of an unknown kid was accepted")
+	}
+}
@@ -0,0 +1,100 @@
+package mail
+
+import (
+	"context"
+	"fmt"
+	"net/smtp"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/HasanNugroho/gin-clean/config"
+	"github.com/HasanNugroho/gin-clean/pkg/logger"
+)
+
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Sender delivers transactional mail such as password reset links.
+type Sender interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// NewSender picks the sender configured by MAIL_DRIVER. The console and file
+// drivers are meant for local development.
+func NewSender(config *config.Config, log *logger.Logger) (Sender, error) {
+	switch config.Mail.Driver {
+	case "", "console":
+		return &ConsoleSender{log: log}, nil
+	case "file":
+		if err := os.MkdirAll(config.Mail.FileDir, 0o755); err != nil {
+			return nil, fmt.Errorf("failed to create mail directory: %w", err)
+		}
+		return &FileSender{from: config.Mail.From, dir: config.Mail.FileDir}, nil
+	case "smtp":
+		return &SMTPSender{
+			from: config.Mail.From,
+			addr: fmt.Sprintf("%s:%s", config.Mail.SMTPHost, config.Mail.SMTPPort),
+			auth: smtp.PlainAuth("", config.Mail.SMTPUser, config.Mail.SMTPPass, config.Mail.SMTPHost),
+		}, nil
+	default:
+		return nil, fmt.Errorf("unsupported MAIL_DRIVER %q", config.Mail.Driver)
+	}
+}
+
+// ConsoleSender writes messages to the application log.
+type ConsoleSender struct {
+	log *logger.Logger
+}
+
+func (s *ConsoleSender) Send(ctx context.Context, msg Message) error {
+	s.log.Info("Mail sent", "to", msg.To, "subject", msg.Subject, "body", msg.Body)
+	return nil
+}
+
+// FileSender stores every message as an .eml file in a directory.
+type FileSender struct {
+	from string
+	dir  string
+}
+
+func (s *FileSender) Send(ctx context.Context, msg Message) error {
+	name := fmt.Sprintf("%d-%s.eml", time.Now().UnixNano(), sanitize(msg.To))
+	return os.WriteFile(filepath.Join(s.dir, name), compose(s.from, msg), 0o644)
+}
+
+// SMTPSender delivers messages through an SMTP relay.
+type SMTPSender struct {
+	from string
+	addr string
+	auth smtp.Auth
+}
+
+func (s *SMTPSender) Send(ctx context.Context, msg Message) error {
+	return smtp.SendMail(s.addr, s.auth, s.from, []string{msg.To}, compose(s.from, msg))
+}
+
+func compose(from string, msg Message) []byte {
+	var b strings.Builder
+	fmt.Fprintf(&b, "From: %s\r\n", from)
+	fmt.Fprintf(&b, "To: %s\r\n", msg.To)
+	fmt.Fprintf(&b, "Subject: %s\r\n", msg.Subject)
+	fmt.Fprintf(&b, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=UTF-8\r\n\r\n")
+	b.WriteString(msg.Body)
+	return []byte(b.String())
+}
+
+func sanitize(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '@' || r == '.' || r == '-' || r == '_' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' {
+			return r
+		}
+		return '_'
+	}, s)
+}
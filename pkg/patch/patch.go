@@ -0,0 +1,38 @@
+// Package patch supports JSON merge patch (RFC 7396) request bodies, where an
+// omitted member leaves a value unchanged and null removes it.
+package patch
+
+import "encoding/json"
+
+// Field is a member of a merge patch document. Unlike a pointer it tells an
+// omitted member apart from one that is null.
+type Field[T any] struct {
+	// Set reports whether the member was present in the document.
+	Set bool
+	// Null reports whether the member was null.
+	Null  bool
+	Value T
+}
+
+func (f *Field[T]) UnmarshalJSON(data []byte) error {
+	f.Set = true
+	if string(data) == "null" {
+		var zero T
+		f.Null, f.Value = true, zero
+		return nil
+	}
+	f.Null = false
+	return json.Unmarshal(data, &f.Value)
+}
+
+// Present reports whether the member was present with a value other than
+// null.
+func (f Field[T]) Present() bool {
+	return f.Set && !f.Null
+}
+
+// State returns the presence and value of the field without knowing T, for
+// validation.
+func (f Field[T]) State() (set, null bool, value interface{}) {
+	return f.Set, f.Null, f.Value
+}
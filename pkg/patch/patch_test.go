@@ -0,0 +1,53 @@
+package patch
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+type document struct {
+	Name  Field[string] `json:"name"`
+	Age   Field[int]    `json:"age"`
+	Admin Field[bool]   `json:"admin"`
+}
+
+func TestField(t *testing.T) {
+	var doc document
+	if err := json.Unmarshal([]byte(`{"name":"Jane","age":null}`), &doc); err != nil {
+		t.Fatal(err)
+	}
+
+	if !doc.Name.Set || doc.Name.Null || !doc.Name.Present() || doc.Name.Value != "Jane" {
+		t.Errorf("name = %+v, want present with Jane", doc.Name)
+	}
+	if !doc.Age.Set || !doc.Age.Null || doc.Age.Present() {
+		t.Errorf("age = %+v, want null", doc.Age)
+	}
+	if doc.Admin.Set || doc.Admin.Present() {
+		t.Errorf("admin = %+v, want omitted", doc.Admin)
+	}
+}
+
+func TestFieldNullResetsValue(t *testing.T) {
+	field := Field[string]{Value: "before"}
+	if err := json.Unmarshal([]byte(`null`), &field); err != nil {
+		t.Fatal(err)
+	}
+	if field.Value != "" || !field.Null {
+		t.Errorf("field = %+v, want null with zero value", field)
+	}
+}
+
+func TestFieldRejectsWrongType(t *testing.T) {
+	var doc document
+	if err := json.Unmarshal([]byte(`{"age":"old"}`), &doc); err == nil {
+		t.Error("Unmarshal accepted a string for an int field")
+	}
+}
+
+func TestState(t *testing.T) {
+	set, null, value := Field[int]{Set: true, Value: 3}.State()
+	if !set || null || value != 3 {
+		t.Errorf("State() = %v, %v, %v, want true, false, 3", set, null, value)
+	}
+}
@@ -0,0 +1,130 @@
+package policy
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// condition is a comparison "<left> <op> <right>" where both operands are
+// either attribute references such as subject.id or YAML literals such as
+// true, 3 or [admin, user]. Supported operators are ==, !=, in and not_in.
+type condition struct {
+	expr        string
+	left, right operand
+	op          string
+}
+
+type operand struct {
+	ref     string
+	literal interface{}
+}
+
+func parseCondition(expr string) (condition, error) {
+	parts := strings.Fields(expr)
+	if len(parts) < 3 {
+		return condition{}, fmt.Errorf("condition %q must have the form <left> <op> <right>", expr)
+	}
+
+	op := parts[1]
+	switch op {
+	case "==", "!=", "in", "not_in":
+	default:
+		return condition{}, fmt.Errorf("condition %q has unknown operator %q", expr, op)
+	}
+
+	left, err := parseOperand(parts[0])
+	if err != nil {
+		return condition{}, fmt.Errorf("condition %q: %w", expr, err)
+	}
+	right, err := parseOperand(strings.Join(parts[2:], " "))
+	if err != nil {
+		return condition{}, fmt.Errorf("condition %q: %w", expr, err)
+	}
+
+	return condition{expr: expr, left: left, right: right, op: op}, nil
+}
+
+func parseOperand(raw string) (operand, error) {
+	for _, prefix := range []string{"subject.", "resource.", "request."} {
+		if strings.HasPrefix(raw, prefix) {
+			return operand{ref: raw}, nil
+		}
+	}
+
+	var literal interface{}
+	if err := yaml.Unmarshal([]byte(raw), &literal); err != nil {
+		return operand{}, fmt.Errorf("invalid literal %q", raw)
+	}
+	return operand{literal: literal}, nil
+}
+
+func (c condition) eval(in Input) (bool, error) {
+	left, right := c.left.value(in), c.right.value(in)
+
+	switch c.op {
+	case "==":
+		return c.equal(left, right), nil
+	case "!=":
+		return !c.equal(left, right), nil
+	}
+
+	list, ok := right.([]interface{})
+	if !ok {
+		return false, fmt.Errorf("right operand of %s must be a list", c.op)
+	}
+	found := false
+	for _, item := range list {
+		if left != nil && equal(left, item) {
+			found = true
+			break
+		}
+	}
+	if c.op == "in" {
+		return found, nil
+	}
+	return !found, nil
+}
+
+// value resolves a reference against the input; unknown attributes are nil.
+func (o operand) value(in Input) interface{} {
+	if o.ref == "" {
+		return o.literal
+	}
+
+	scope, key, _ := strings.Cut(o.ref, ".")
+	switch scope {
+	case "subject":
+		return in.Subject[key]
+	case "resource":
+		return in.Attributes[key]
+	default:
+		return in.Request[key]
+	}
+}
+
+// equal treats missing attributes like SQL NULL: they only equal the null
+// literal, so "resource.id == subject.id" is false when both are missing.
+func (c condition) equal(left, right interface{}) bool {
+	if c.left.ref == "" && c.left.literal == nil {
+		return right == nil
+	}
+	if c.right.ref == "" && c.right.literal == nil {
+		return left == nil
+	}
+	return equal(left, right)
+}
+
+// equal compares loosely so that YAML literals match attribute values of
+// other numeric or string types.
+func equal(a, b interface{}) bool {
+	if a == nil || b == nil {
+		return false
+	}
+	if reflect.DeepEqual(a, b) {
+		return true
+	}
+	return fmt.Sprint(a) == fmt.Sprint(b)
+}
@@ -0,0 +1,275 @@
+// Package policy evaluates declarative authorization rules loaded from a YAML
+// file. A rule matches a request by the role of the subject, the resource
+// type, the action and optional conditions on attributes; deny rules take
+// precedence over allow rules and requests no rule matches get the default
+// effect of the file.
+//
+//	default: deny
+//	rules:
+//	  - name: users-manage-themselves
+//	    effect: allow
+//	    roles: [authenticated]
+//	    resources: [users]
+//	    actions: [read, update]
+//	    conditions:
+//	      - resource.id == subject.id
+package policy
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"slices"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+type Effect string
+
+const (
+	Allow Effect = "allow"
+	Deny  Effect = "deny"
+)
+
+// Special role names a rule can match on.
+const (
+	RoleAny           = "*"
+	RoleAnonymous     = "anonymous"
+	RoleAuthenticated = "authenticated"
+)
+
+type Rule struct {
+	Name       string   `yaml:"name" json:"name"`
+	Effect     Effect   `yaml:"effect" json:"effect"`
+	Roles      []string `yaml:"roles" json:"roles"`
+	Resources  []string `yaml:"resources" json:"resources"`
+	Actions    []string `yaml:"actions" json:"actions"`
+	Conditions []string `yaml:"conditions" json:"conditions,omitempty"`
+
+	conditions []condition
+}
+
+type File struct {
+	Default Effect `yaml:"default" json:"default"`
+	Rules   []Rule `yaml:"rules" json:"rules"`
+}
+
+// Input is the request being authorized. Attributes are addressed in
+// conditions as subject.<key>, resource.<key> (from Attributes) and
+// request.<key>.
+type Input struct {
+	Role       string                 `json:"role"`
+	Action     string                 `json:"action"`
+	Resource   string                 `json:"resource"`
+	Subject    map[string]interface{} `json:"subject"`
+	Attributes map[string]interface{} `json:"attributes"`
+	Request    map[string]interface{} `json:"request"`
+}
+
+// Decision is the outcome of an evaluation. Trace explains how every rule
+// was judged, for debugging denials.
+type Decision struct {
+	Allowed bool        `json:"allowed"`
+	Rule    string      `json:"rule,omitempty"`
+	Reason  string      `json:"reason"`
+	Trace   []RuleTrace `json:"trace,omitempty"`
+}
+
+type RuleTrace struct {
+	Rule    string `json:"rule"`
+	Effect  Effect `json:"effect"`
+	Matched bool   `json:"matched"`
+	Reason  string `json:"reason"`
+}
+
+// Engine holds the rules of a policy file and can reload them at runtime.
+type Engine struct {
+	path string
+
+	mu       sync.RWMutex
+	file     File
+	modified time.Time
+}
+
+// Load reads and compiles the policy file at path.
+func Load(path string) (*Engine, error) {
+	e := &Engine{path: path}
+	if err := e.Reload(); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+func (e *Engine) Path() string {
+	return e.path
+}
+
+// Reload replaces the rules with the current content of the policy file. The
+// previous rules stay in effect if the file is invalid.
+func (e *Engine) Reload() error {
+	info, err := os.Stat(e.path)
+	if err != nil {
+		return fmt.Errorf("failed to read policy file: %w", err)
+	}
+
+	data, err := os.ReadFile(e.path)
+	if err != nil {
+		return fmt.Errorf("failed to read policy file: %w", err)
+	}
+
+	file, err := Parse(data)
+	if err != nil {
+		return err
+	}
+
+	e.mu.Lock()
+	e.file = file
+	e.modified = info.ModTime()
+	e.mu.Unlock()
+	return nil
+}
+
+// Parse decodes and validates a policy file.
+func Parse(data []byte) (File, error) {
+	var file File
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return file, fmt.Errorf("invalid policy file: %w", err)
+	}
+
+	switch file.Default {
+	case "":
+		file.Default = Deny
+	case Allow, Deny:
+	default:
+		return file, fmt.Errorf("invalid policy file: default must be allow or deny, got %q", file.Default)
+	}
+
+	for i := range file.Rules {
+		rule := &file.Rules[i]
+		if rule.Name == "" {
+			rule.Name = fmt.Sprintf("rule-%d", i+1)
+		}
+		if rule.Effect != Allow && rule.Effect != Deny {
+			return file, fmt.Errorf("invalid policy rule %s: effect must be allow or deny", rule.Name)
+		}
+		for _, pattern := range slices.Concat(rule.Resources, rule.Actions) {
+			if _, err := path.Match(pattern, ""); err != nil {
+				return file, fmt.Errorf("invalid policy rule %s: bad pattern %q", rule.Name, pattern)
+			}
+		}
+		for _, expr := range rule.Conditions {
+			cond, err := parseCondition(expr)
+			if err != nil {
+				return file, fmt.Errorf("invalid policy rule %s: %w", rule.Name, err)
+			}
+			rule.conditions = append(rule.conditions, cond)
+		}
+	}
+
+	return file, nil
+}
+
+// File returns the rules currently in effect.
+func (e *Engine) File() File {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.file
+}
+
+// Evaluate decides the input against every rule: a matching deny rule wins,
+// then a matching allow rule, then the default effect.
+func (e *Engine) Evaluate(in Input) Decision {
+	file := e.File()
+
+	var (
+		decision Decision
+		allowed  string
+		denied   string
+	)
+	for _, rule := range file.Rules {
+		matched, reason := rule.match(in)
+		decision.Trace = append(decision.Trace, RuleTrace{
+			Rule:    rule.Name,
+			Effect:  rule.Effect,
+			Matched: matched,
+			Reason:  reason,
+		})
+		if !matched {
+			continue
+		}
+		if rule.Effect == Deny && denied == "" {
+			denied = rule.Name
+		}
+		if rule.Effect == Allow && allowed == "" {
+			allowed = rule.Name
+		}
+	}
+
+	switch {
+	case denied != "":
+		decision.Rule = denied
+		decision.Reason = "denied by rule " + denied
+	case allowed != "":
+		decision.Allowed = true
+		decision.Rule = allowed
+		decision.Reason = "allowed by rule " + allowed
+	default:
+		decision.Allowed = file.Default == Allow
+		decision.Reason = fmt.Sprintf("no rule matched, default is %s", file.Default)
+	}
+
+	return decision
+}
+
+func (r Rule) match(in Input) (bool, string) {
+	if !matchRole(r.Roles, in.Role) {
+		return false, fmt.Sprintf("role %q not in %v", in.Role, r.Roles)
+	}
+	if !matchPattern(r.Resources, in.Resource) {
+		return false, fmt.Sprintf("resource %q not in %v", in.Resource, r.Resources)
+	}
+	if !matchPattern(r.Actions, in.Action) {
+		return false, fmt.Sprintf("action %q not in %v", in.Action, r.Actions)
+	}
+	for _, cond := range r.conditions {
+		ok, err := cond.eval(in)
+		if err != nil {
+			return false, fmt.Sprintf("condition %q failed: %v", cond.expr, err)
+		}
+		if !ok {
+			return false, fmt.Sprintf("condition %q is false", cond.expr)
+		}
+	}
+	return true, "matched"
+}
+
+// matchRole treats an empty list like RoleAny.
+func matchRole(roles []string, role string) bool {
+	if len(roles) == 0 {
+		return true
+	}
+	for _, r := range roles {
+		switch {
+		case r == RoleAny, r == role:
+			return true
+		case r == RoleAuthenticated && role != RoleAnonymous:
+			return true
+		}
+	}
+	return false
+}
+
+// matchPattern matches shell-style patterns and treats an empty list as "*".
+func matchPattern(patterns []string, value string) bool {
+	if len(patterns) == 0 {
+		return true
+	}
+	for _, p := range patterns {
+		if ok, _ := path.Match(p, value); ok {
+			return true
+		}
+	}
+	return false
+}
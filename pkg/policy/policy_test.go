@@ -0,0 +1,256 @@
+package policy
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testPolicy = `
+default: deny
+rules:
+  - name: admins-manage-everything
+    effect: allow
+    roles: [admin]
+  - name: users-manage-themselves
+    effect: allow
+    roles: [authenticated]
+    resources: [users]
+    actions: [read, update]
+    conditions:
+      - resource.id == subject.id
+  - name: anyone-reads-docs
+    effect: allow
+    roles: ["*"]
+    resources: [docs.*]
+    actions: [read]
+  - name: no-deleting-protected
+    effect: deny
+    actions: [delete]
+    conditions:
+      - resource.status in [locked, archived]
+`
+
+func mustParse(t *testing.T, data string) *Engine {
+	t.Helper()
+	file, err := Parse([]byte(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &Engine{file: file}
+}
+
+func TestEvaluate(t *testing.T) {
+	engine := mustParse(t, testPolicy)
+
+	tests := []struct {
+		name     string
+		in       Input
+		allowed  bool
+		wantRule string
+	}{
+		{
+			name:     "admin on anything",
+			in:       Input{Role: "admin", Resource: "roles", Action: "delete"},
+			allowed:  true,
+			wantRule: "admins-manage-everything",
+		},
+		{
+			name: "user reads themselves",
+			in: Input{
+				Role: "user", Resource: "users", Action: "read",
+				Subject: map[string]interface{}{"id": "1"}, Attributes: map[string]interface{}{"id": "1"},
+			},
+			allowed:  true,
+			wantRule: "users-manage-themselves",
+		},
+		{
+			name: "user reads someone else",
+			in: Input{
+				Role: "user", Resource: "users", Action: "read",
+				Subject: map[string]interface{}{"id": "1"}, Attributes: map[string]interface{}{"id": "2"},
+			},
+		},
+		{
+			name: "missing attributes are not equal",
+			in:   Input{Role: "user", Resource: "users", Action: "read"},
+		},
+		{
+			name: "action outside the rule",
+			in: Input{
+				Role: "user", Resource: "users", Action: "delete",
+				Subject: map[string]interface{}{"id": "1"}, Attributes: map[string]interface{}{"id": "1"},
+			},
+		},
+		{
+			name: "anonymous is not authenticated",
+			in: Input{
+				Role: RoleAnonymous, Resource: "users", Action: "read",
+				Subject: map[string]interface{}{"id": ""}, Attributes: map[string]interface{}{"id": ""},
+			},
+		},
+		{
+			name:     "pattern on resources",
+			in:       Input{Role: RoleAnonymous, Resource: "docs.swagger", Action: "read"},
+			allowed:  true,
+			wantRule: "anyone-reads-docs",
+		},
+		{
+			name:     "deny wins over allow",
+			in:       Input{Role: "admin", Resource: "users", Action: "delete", Attributes: map[string]interface{}{"status": "locked"}},
+			wantRule: "no-deleting-protected",
+		},
+		{
+			name:     "deny condition not met",
+			in:       Input{Role: "admin", Resource: "users", Action: "delete", Attributes: map[string]interface{}{"status": "active"}},
+			allowed:  true,
+			wantRule: "admins-manage-everything",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			decision := engine.Evaluate(tt.in)
+			if decision.Allowed != tt.allowed || decision.Rule != tt.wantRule {
+				t.Errorf("Evaluate = allowed %v by %q (%s), want allowed %v by %q",
+					decision.Allowed, decision.Rule, decision.Reason, tt.allowed, tt.wantRule)
+			}
+			if len(decision.Trace) != 4 {
+				t.Errorf("trace has %d rules, want 4", len(decision.Trace))
+			}
+		})
+	}
+}
+
+func TestEvaluateDefault(t *testing.T) {
+	for _, tt := range []struct {
+		data    string
+		allowed bool
+	}{
+		{"rules: []", false},
+		{"default: deny", false},
+		{"default: allow", true},
+	} {
+		decision := mustParse(t, tt.data).Evaluate(Input{Role: "user", Resource: "users", Action: "read"})
+		if decision.Allowed != tt.allowed || decision.Rule != "" {
+			t.Errorf("%q: Evaluate = %+v, want allowed %v by no rule", tt.data, decision, tt.allowed)
+		}
+	}
+}
+
+func TestConditions(t *testing.T) {
+	in := Input{
+		Subject:    map[string]interface{}{"id": "1", "level": 3},
+		Attributes: map[string]interface{}{"owner": "1", "public": true},
+		Request:    map[string]interface{}{"method": "GET"},
+	}
+
+	tests := map[string]bool{
+		"resource.owner == subject.id":       true,
+		"resource.owner != subject.id":       false,
+		"resource.public == true":            true,
+		"subject.level == 3":                 true,
+		"subject.level == '3'":               true,
+		"request.method in [GET, HEAD]":      true,
+		"request.method not_in [GET, HEAD]":  false,
+		"resource.missing == null":           true,
+		"resource.missing != null":           false,
+		"resource.missing == subject.absent": false,
+		"resource.missing in [null]":         false,
+		"resource.missing not_in [a]":        true,
+	}
+	for expr, want := range tests {
+		cond, err := parseCondition(expr)
+		if err != nil {
+			t.Fatalf("parseCondition(%q): %v", expr, err)
+		}
+		got, err := cond.eval(in)
+		if err != nil {
+			t.Fatalf("eval(%q): %v", expr, err)
+		}
+		if got != want {
+			t.Errorf("eval(%q) = %v, want %v", expr, got, want)
+		}
+	}
+}
+
+func TestConditionInNeedsList(t *testing.T) {
+	cond, err := parseCondition("subject.id in admin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := cond.eval(Input{}); err == nil {
+		t.Error("eval accepted in with a scalar right operand")
+	}
+}
+
+func TestParseRejects(t *testing.T) {
+	tests := map[string]string{
+		"default":   "default: maybe",
+		"effect":    "rules: [{name: r, effect: permit}]",
+		"pattern":   "rules: [{name: r, effect: allow, resources: ['[']}]",
+		"operator":  "rules: [{name: r, effect: allow, conditions: ['subject.id ~ 1']}]",
+		"too short": "rules: [{name: r, effect: allow, conditions: ['subject.id ==']}]",
+		"literal":   "rules: [{name: r, effect: allow, conditions: ['subject.id == [a']}]",
+		"yaml":      "rules: {",
+	}
+	for name, data := range tests {
+		if _, err := Parse([]byte(data)); err == nil {
+			t.Errorf("%s: Parse accepted %q", name, data)
+		}
+	}
+}
+
+func TestParseNamesRules(t *testing.T) {
+	file, err := Parse([]byte("rules: [{effect: allow}, {name: named, effect: deny}]"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if file.Default != Deny {
+		t.Errorf("default = %q, want deny", file.Default)
+	}
+	if file.Rules[0].Name != "rule-1" || file.Rules[1].Name != "named" {
+		t.Errorf("names = %q, %q", file.Rules[0].Name, file.Rules[1].Name)
+	}
+}
+
+func TestReloadKeepsRulesOfInvalidFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "policies.yaml")
+	if err := os.WriteFile(path, []byte("default: allow"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	engine, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !engine.Evaluate(Input{}).Allowed {
+		t.Fatal("Evaluate denied under default allow")
+	}
+
+	if err := os.WriteFile(path, []byte("default: maybe"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := engine.Reload(); err == nil || !strings.Contains(err.Error(), "default") {
+		t.Errorf("Reload = %v, want an invalid default error", err)
+	}
+	if !engine.Evaluate(Input{}).Allowed {
+		t.Error("an invalid file replaced the rules in effect")
+	}
+
+	if err := os.WriteFile(path, []byte("default: deny"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := engine.Reload(); err != nil {
+		t.Fatal(err)
+	}
+	if engine.Evaluate(Input{}).Allowed {
+		t.Error("Reload did not apply the new rules")
+	}
+}
+
+func TestLoadMissingFile(t *testing.T) {
+	if _, err := Load(filepath.Join(t.TempDir(), "missing.yaml")); err == nil {
+		t.Error("Load accepted a missing file")
+	}
+}
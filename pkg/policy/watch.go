@@ -0,0 +1,46 @@
+package policy
+
+import (
+	"context"
+	"os"
+	"time"
+)
+
+// Watch reloads the policy file whenever its modification time changes,
+// checking every interval until ctx is done. onReload is called after every
+// reload attempt with its error, if any.
+func (e *Engine) Watch(ctx context.Context, interval time.Duration, onReload func(error)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		info, err := os.Stat(e.path)
+		if err != nil {
+			continue
+		}
+
+		e.mu.RLock()
+		changed := !info.ModTime().Equal(e.modified)
+		e.mu.RUnlock()
+		if !changed {
+			continue
+		}
+
+		err = e.Reload()
+		if err != nil {
+			// Do not retry a broken file until it changes again.
+			e.mu.Lock()
+			e.modified = info.ModTime()
+			e.mu.Unlock()
+		}
+		if onReload != nil {
+			onReload(err)
+		}
+	}
+}
@@ -5,12 +5,14 @@ import (
 )
 
 type Meta struct {
-	Code       int    `json:"code"`
-	Message    string `json:"message"`
-	Page       *int   `json:"page,omitempty"`
-	Limit      *int   `json:"limit,omitempty"`
-	TotalRows  *int   `json:"total_rows,omitempty"`
-	TotalPages *int   `json:"total_pages,omitempty"`
+	Code       int     `json:"code"`
+	Message    string  `json:"message"`
+	Page       *int    `json:"page,omitempty"`
+	Limit      *int    `json:"limit,omitempty"`
+	TotalRows  *int    `json:"total_rows,omitempty"`
+	TotalPages *int    `json:"total_pages,omitempty"`
+	NextCursor *string `json:"next_cursor,omitempty"`
+	PrevCursor *string `json:"prev_cursor,omitempty"`
 }
 
 type Response struct {
@@ -46,6 +48,29 @@ func SendPagination(c *gin.Context, code int, message string, data interface{},
 	})
 }
 
+// SendCursorPagination sends a cursor paginated success response. Empty
+// cursors are left out.
+func SendCursorPagination(c *gin.Context, code int, message string, data interface{},
+	limit int, nextCursor, prevCursor string) {
+
+	meta := Meta{
+		Code:    code,
+		Message: message,
+		Limit:   &limit,
+	}
+	if nextCursor != "" {
+		meta.NextCursor = &nextCursor
+	}
+	if prevCursor != "" {
+		meta.PrevCursor = &prevCursor
+	}
+
+	c.JSON(code, Response{
+		Data: data,
+		Meta: meta,
+	})
+}
+
 // SendError sends an error response
 func SendError(c *gin.Context, code int, message string, err interface{}) {
 	c.JSON(code, Response{
@@ -0,0 +1,33 @@
+// Package tenant carries the organization a request acts in on its context.
+// Repositories scope their queries to that organization.
+package tenant
+
+import "context"
+
+type contextKey int
+
+const (
+	tenantKey contextKey = iota
+	unscopedKey
+)
+
+// WithTenant returns a context that acts in the given organization.
+func WithTenant(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, tenantKey, id)
+}
+
+// FromContext returns the organization of ctx. It reports false when ctx has
+// none or was marked with Unscoped.
+func FromContext(ctx context.Context) (string, bool) {
+	if unscoped, _ := ctx.Value(unscopedKey).(bool); unscoped {
+		return "", false
+	}
+	id, ok := ctx.Value(tenantKey).(string)
+	return id, ok && id != ""
+}
+
+// Unscoped returns a context whose queries ignore the organization, for checks
+// that must see every tenant such as the global uniqueness of emails.
+func Unscoped(ctx context.Context) context.Context {
+	return context.WithValue(ctx, unscopedKey, true)
+}
@@ -0,0 +1,128 @@
+package totp
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+// rfcSecret is the SHA1 key of the RFC 6238 test vectors, base32 encoded.
+var rfcSecret = encoding.EncodeToString([]byte("12345678901234567890"))
+
+func TestCode(t *testing.T) {
+	// The RFC lists 8 digit codes; ours are their last 6 digits.
+	tests := []struct {
+		unix int64
+		want string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1111111111, "050471"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+		{20000000000, "353130"},
+	}
+	for _, tt := range tests {
+		got, err := Code(rfcSecret, Counter(time.Unix(tt.unix, 0)))
+		if err != nil {
+			t.Fatalf("Code(%d): %v", tt.unix, err)
+		}
+		if got != tt.want {
+			t.Errorf("Code(%d) = %q, want %q", tt.unix, got, tt.want)
+		}
+	}
+}
+
+func TestCodeAcceptsLowercaseSecret(t *testing.T) {
+	want, _ := Code(rfcSecret, 1)
+	got, err := Code(" "+strings.ToLower(rfcSecret)+" ", 1)
+	if err != nil || got != want {
+		t.Errorf("Code(lowercase) = %q, %v, want %q", got, err, want)
+	}
+}
+
+func TestCodeRejectsInvalidSecret(t *testing.T) {
+	if _, err := Code("not base32!", 1); err == nil {
+		t.Error("Code accepted an invalid secret")
+	}
+}
+
+func TestValidate(t *testing.T) {
+	now := time.Unix(1111111111, 0)
+	current := Counter(now)
+	previous, _ := Code(rfcSecret, current-1)
+	next, _ := Code(rfcSecret, current+1)
+	stale, _ := Code(rfcSecret, current-2)
+
+	tests := []struct {
+		name        string
+		code        string
+		skew        int64
+		wantCounter int64
+		wantOK      bool
+	}{
+		{"current step", "050471", 0, current, true},
+		{"surrounding spaces", " 050471 ", 0, current, true},
+		{"previous step within skew", previous, 1, current - 1, true},
+		{"next step within skew", next, 1, current + 1, true},
+		{"previous step without skew", previous, 0, 0, false},
+		{"step beyond skew", stale, 1, 0, false},
+		{"wrong code", "123456", 1, 0, false},
+		{"too short", "05047", 1, 0, false},
+		{"too long", "0504710", 1, 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			counter, ok := Validate(rfcSecret, tt.code, now, tt.skew)
+			if ok != tt.wantOK || counter != tt.wantCounter {
+				t.Errorf("Validate(%q) = %d, %v, want %d, %v", tt.code, counter, ok, tt.wantCounter, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestGenerateSecret(t *testing.T) {
+	secret, err := GenerateSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, err := encoding.DecodeString(secret)
+	if err != nil {
+		t.Fatalf("secret %q is not base32: %v", secret, err)
+	}
+	if len(key) != 20 {
+		t.Errorf("secret has %d bytes, want 20", len(key))
+	}
+
+	other, _ := GenerateSecret()
+	if other == secret {
+		t.Error("GenerateSecret returned the same secret twice")
+	}
+}
+
+func TestURI(t *testing.T) {
+	uri, err := url.Parse(URI("Gin Clean", "jane@example.com", "SECRET"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if uri.Scheme != "otpauth" || uri.Host != "totp" {
+		t.Errorf("URI = %s, want otpauth://totp/...", uri)
+	}
+	if uri.Path != "/Gin Clean:jane@example.com" {
+		t.Errorf("label = %q", uri.Path)
+	}
+
+	query := uri.Query()
+	for key, want := range map[string]string{
+		"secret":    "SECRET",
+		"issuer":    "Gin Clean",
+		"algorithm": "SHA1",
+		"digits":    "6",
+		"period":    "30",
+	} {
+		if got := query.Get(key); got != want {
+			t.Errorf("%s = %q, want %q", key, got, want)
+		}
+	}
+}
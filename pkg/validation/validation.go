@@ -1,7 +1,13 @@
 package validation
 
 import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
 	"net/http"
+	"reflect"
+	"strings"
 
 	"github.com/HasanNugroho/gin-clean/pkg/logger"
 	"github.com/HasanNugroho/gin-clean/pkg/response"
@@ -25,3 +31,103 @@ func ValidateBody[T any](c *gin.Context, v *validator.Validate, log *logger.Logg
 
 	return &body, true
 }
+
+// ValidateQuery binds and validates the query string of the request, like
+// ValidateBody does for its body.
+func ValidateQuery[T any](c *gin.Context, v *validator.Validate, log *logger.Logger) (*T, bool) {
+	var query T
+
+	if err := c.ShouldBindQuery(&query); err != nil {
+		log.Warn("Invalid query parameters", "error", err)
+		response.SendError(c, http.StatusBadRequest, "Invalid query parameters", err.Error())
+		return nil, false
+	}
+
+	if err := v.Struct(query); err != nil {
+		response.SendError(c, http.StatusBadRequest, "Validation error", err.Error())
+		return nil, false
+	}
+
+	return &query, true
+}
+
+// patchField is implemented by patch.Field.
+type patchField interface {
+	State() (set, null bool, value interface{})
+}
+
+// ValidatePatch binds a JSON merge patch body into T, whose patch.Field
+// members are validated by their validate tag when present. There "required"
+// forbids null while the other rules apply to the value. Unknown members are
+// rejected.
+func ValidatePatch[T any](c *gin.Context, v *validator.Validate, log *logger.Logger) (*T, bool) {
+	var body T
+
+	raw, err := io.ReadAll(c.Request.Body)
+	if err == nil && !bytes.HasPrefix(bytes.TrimSpace(raw), []byte("{")) {
+		err = fmt.Errorf("merge patch must be a JSON object")
+	}
+	if err == nil {
+		decoder := json.NewDecoder(bytes.NewReader(raw))
+		decoder.DisallowUnknownFields()
+		err = decoder.Decode(&body)
+	}
+	if err != nil {
+		log.Warn("Invalid request payload", "error", err)
+		response.SendError(c, http.StatusBadRequest, "Invalid request payload", err.Error())
+		return nil, false
+	}
+
+	if err := validatePatchFields(v, &body); err != nil {
+		response.SendError(c, http.StatusBadRequest, "Validation error", err.Error())
+		return nil, false
+	}
+
+	return &body, true
+}
+
+func validatePatchFields(v *validator.Validate, body interface{}) error {
+	value := reflect.Indirect(reflect.ValueOf(body))
+	for i := 0; i < value.NumField(); i++ {
+		field, ok := value.Field(i).Interface().(patchField)
+		if !ok {
+			continue
+		}
+		set, null, fieldValue := field.State()
+		if !set {
+			continue
+		}
+
+		structField := value.Type().Field(i)
+		name, _, _ := strings.Cut(structField.Tag.Get("json"), ",")
+		if name == "" {
+			name = structField.Name
+		}
+
+		var rules []string
+		required := false
+		for _, rule := range strings.Split(structField.Tag.Get("validate"), ",") {
+			switch rule {
+			case "":
+			case "required":
+				required = true
+			default:
+				rules = append(rules, rule)
+			}
+		}
+
+		if null {
+			if required {
+				return fmt.Errorf("%s cannot be null", name)
+			}
+			continue
+		}
+		if len(rules) == 0 {
+			continue
+		}
+		if err := v.Var(fieldValue, strings.Join(rules, ",")); err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,86 @@
+package validation
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/HasanNugroho/gin-clean/pkg/logger"
+	"github.com/HasanNugroho/gin-clean/pkg/patch"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+type userPatch struct {
+	Name  patch.Field[string] `json:"name" validate:"required,max=5"`
+	Email patch.Field[string] `json:"email" validate:"omitempty,email"`
+	Bio   patch.Field[string] `json:"bio"`
+}
+
+func validatePatch(t *testing.T, body string) (*userPatch, *httptest.ResponseRecorder) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	recorder := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(recorder)
+	c.Request = httptest.NewRequest(http.MethodPatch, "/", strings.NewReader(body))
+
+	result, ok := ValidatePatch[userPatch](c, validator.New(), logger.NewLogger(5))
+	if ok != (result != nil) {
+		t.Fatalf("ValidatePatch returned %v with ok %v", result, ok)
+	}
+	return result, recorder
+}
+
+func TestValidatePatch(t *testing.T) {
+	result, recorder := validatePatch(t, `{"name":"Jane","bio":null}`)
+	if result == nil {
+		t.Fatalf("ValidatePatch rejected a valid patch: %s", recorder.Body)
+	}
+	if !result.Name.Present() || result.Name.Value != "Jane" {
+		t.Errorf("name = %+v", result.Name)
+	}
+	if !result.Bio.Null {
+		t.Errorf("bio = %+v, want null", result.Bio)
+	}
+	if result.Email.Set {
+		t.Errorf("email = %+v, want omitted", result.Email)
+	}
+}
+
+func TestValidatePatchEmptyObject(t *testing.T) {
+	if result, recorder := validatePatch(t, `{}`); result == nil {
+		t.Errorf("ValidatePatch rejected an empty patch: %s", recorder.Body)
+	}
+}
+
+func TestValidatePatchRejects(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want string
+	}{
+		{"not an object", `["name"]`, "merge patch must be a JSON object"},
+		{"malformed", `{"name":`, "Invalid request payload"},
+		{"unknown member", `{"role":"admin"}`, "unknown field"},
+		{"null for required", `{"name":null}`, "name cannot be null"},
+		{"rule of present value", `{"name":"Johnny"}`, "name:"},
+		{"rule of optional value", `{"email":"nope"}`, "email:"},
+		{"wrong type", `{"name":1}`, "Invalid request payload"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, recorder := validatePatch(t, tt.body)
+			if result != nil {
+				t.Fatalf("ValidatePatch accepted %s", tt.body)
+			}
+			if recorder.Code != http.StatusBadRequest {
+				t.Errorf("status = %d, want 400", recorder.Code)
+			}
+			if !strings.Contains(recorder.Body.String(), tt.want) {
+				t.Errorf("body = %s, want it to mention %q", recorder.Body, tt.want)
+			}
+		})
+	}
+}